@@ -2,9 +2,12 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"strings"
 	"time"
 
 	"go.uber.org/zap"
@@ -12,12 +15,34 @@ import (
 	pdmanager "github.com/corsc/pagerduty-manager"
 )
 
+// commands; sync is used when no command is supplied
 const (
-	maxExecutionTime = 60 * time.Second
+	commandSync      = "sync"
+	commandOverrides = "overrides"
+	commandValidate  = "validate"
+	commandSchema    = "schema"
+	commandExport    = "export"
+	commandDrift     = "drift"
+)
+
+// exit codes
+const (
+	exitError = 1
+	// exitDrift is the exit code of the drift command when PagerDuty differs from the config
+	exitDrift = 2
+	// exitTimeout is the exit code when the run did not finish within the timeout (see -timeout)
+	exitTimeout = 3
 )
 
 func main() {
-	cfg := buildConfig()
+	command, args := parseCommand(os.Args[1:])
+
+	if command == commandSchema {
+		_, _ = os.Stdout.Write(pdmanager.Schema)
+		return
+	}
+
+	cfg := buildConfig(command, args)
 
 	logger, err := zap.NewProduction()
 	if err != nil {
@@ -25,52 +50,324 @@ func main() {
 		os.Exit(-1)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), maxExecutionTime)
+	os.Exit(run(command, cfg, logger))
+}
+
+// run runs the command and returns the exit code
+func run(command string, cfg *config, logger *zap.Logger) int {
+	ctx, cancel := context.Background(), func() {}
+
+	// without -timeout there is no deadline, so a large account is never stopped part-way through a sync
+	if cfg.timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.timeout)
+	}
+
 	defer cancel()
 
+	// fail logs the error and returns the exit code; a timeout has its own exit code so it is not mistaken for drift
+	fail := func(msg string, err error) int {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			logger.Error(msg+"; timed out (see -timeout)", zap.Error(err))
+			return exitTimeout
+		}
+
+		logger.Error(msg, zap.Error(err))
+		return exitError
+	}
+
 	manager := pdmanager.New(cfg, logger)
 
-	err = manager.Parse(ctx)
+	if command == commandExport {
+		if cfg.namingFilename != "" {
+			err := manager.ParseNaming(ctx, cfg.namingFilename)
+			if err != nil {
+				return fail("failed to parse naming", err)
+			}
+		}
+
+		err := export(ctx, manager, cfg.filename)
+		if err != nil {
+			return fail("failed to export", err)
+		}
+
+		return 0
+	}
+
+	err := manager.Parse(ctx)
+
+	if command == commandValidate {
+		return printProblems(manager, err)
+	}
+
 	if err != nil {
-		logger.Fatal("failed to parse", zap.Error(err))
-		return
+		return fail("failed to parse", err)
+	}
+
+	switch command {
+	case commandOverrides:
+		err = manager.ParseOverrides(ctx, cfg.overridesFilename)
+		if err != nil {
+			return fail("failed to parse overrides", err)
+		}
+
+		err = manager.SyncOverrides(ctx)
+
+	default:
+		err = manager.Sync(ctx)
 	}
 
-	err = manager.Sync(ctx)
 	if err != nil {
-		logger.Fatal("failed to sync", zap.Error(err))
-		return
+		return fail("failed to sync", err)
+	}
+
+	if cfg.secretsFilename != "" {
+		err = writeSecrets(manager.Secrets(), cfg.secretsFilename)
+		if err != nil {
+			return fail("failed to write secrets", err)
+		}
+	}
+
+	if cfg.DryRun() && !cfg.reportsToStdout() {
+		err = manager.Plan().Print(os.Stdout)
+		if err != nil {
+			return fail("failed to print plan", err)
+		}
+	}
+
+	if command == commandDrift {
+		err = writeReports(manager.Plan(), cfg.jsonReportFilename, cfg.junitReportFilename)
+		if err != nil {
+			return fail("failed to write drift report", err)
+		}
+
+		if !manager.Plan().IsEmpty() {
+			return exitDrift
+		}
+	}
+
+	return 0
+}
+
+// writeReports writes the plan as JSON and JUnit XML (when the filenames are set; use - for stdout)
+func writeReports(plan *pdmanager.Plan, jsonFilename, junitFilename string) error {
+	err := writeReport(jsonFilename, plan.WriteJSON)
+	if err != nil {
+		return err
+	}
+
+	return writeReport(junitFilename, plan.WriteJUnit)
+}
+
+func writeReport(filename string, write func(io.Writer) error) error {
+	switch filename {
+	case "":
+		return nil
+
+	case "-":
+		return write(os.Stdout)
 	}
+
+	file, err := os.Create(filename)
+	if err != nil {
+		return err
+	}
+
+	err = write(file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// writeSecrets writes the secrets as JSON to the file (readable only by the current user) or to stdout when the filename is "-"
+func writeSecrets(secrets *pdmanager.Secrets, filename string) error {
+	if filename == "-" {
+		return secrets.Write(os.Stdout)
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+
+	err = secrets.Write(file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
 }
 
-func buildConfig() *config {
+// export writes the config exported from PagerDuty to the file or to stdout when the filename is "-"
+func export(ctx context.Context, manager *pdmanager.Manager, filename string) error {
+	if filename == "-" {
+		return manager.Export(ctx, os.Stdout)
+	}
+
+	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+
+	err = manager.Export(ctx, file)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	return file.Close()
+}
+
+// printProblems prints every problem with the config and returns the exit code (non-zero when the config is invalid)
+func printProblems(manager *pdmanager.Manager, parseErr error) int {
+	problems := manager.Problems()
+
+	var validationErr *pdmanager.ValidationError
+	if parseErr != nil && !errors.As(parseErr, &validationErr) {
+		// the config could not be loaded so it has not been validated
+		_, _ = fmt.Fprintf(os.Stdout, "error: %s\n", parseErr)
+		return exitError
+	}
+
+	for _, problem := range problems {
+		_, _ = fmt.Fprintln(os.Stdout, problem.String())
+	}
+
+	if parseErr != nil {
+		return exitError
+	}
+
+	_, _ = fmt.Fprintf(os.Stdout, "config is valid (%d warnings)\n", len(problems))
+
+	return 0
+}
+
+// parseCommand splits the command (if any) from the remaining arguments
+func parseCommand(args []string) (string, []string) {
+	if len(args) > 0 {
+		switch args[0] {
+		case commandSync, commandOverrides, commandValidate, commandSchema, commandExport, commandDrift:
+			return args[0], args[1:]
+		}
+	}
+
+	return commandSync, args
+}
+
+func buildConfig(command string, args []string) *config {
 	cfg := &config{
 		accessToken: os.Getenv("PD_TOKEN"),
+		baseURL:     "https://api.pagerduty.com",
 	}
 
-	flag.BoolVar(&cfg.debug, "debug", false, "enable debug mode")
+	flags := flag.NewFlagSet(command, flag.ExitOnError)
 
-	flag.Parse()
+	flags.BoolVar(&cfg.debug, "debug", false, "enable debug mode")
+	flags.BoolVar(&cfg.dryRun, "d", false, "dry-run; print the changes that would be made without making them")
+	flags.BoolVar(&cfg.prune, "prune", false, "remove team members, services, schedules, escalation policies and (for overrides) upcoming overrides that are no longer in the config")
+	flags.BoolVar(&cfg.pruneUsers, "prune-users", false, "with -prune; also delete users that are no longer in any team (cannot be undone)")
+	adopt := flags.String("adopt", "", "comma separated resource types ("+strings.Join(pdmanager.AdoptableResources, ", ")+
+		") where existing objects that were not created by this tool should be managed anyway")
+	flags.BoolVar(&cfg.caseInsensitiveNames, "ignore-case", false, "ignore case when matching existing PagerDuty objects by name")
+	flags.IntVar(&cfg.maxRetries, "max-retries", 5, "number of times a rate limited or failed PagerDuty request is retried")
+	flags.DurationVar(&cfg.maxBackoff, "max-backoff", 30*time.Second, "max wait between retries (unless PagerDuty asks for longer)")
+	flags.DurationVar(&cfg.timeout, "timeout", 0, "max time for the whole run, including retries (default: no limit)")
+	flags.StringVar(&cfg.secretsFilename, "secrets", "", "file to write the integration keys of the services to as JSON (use - for stdout)")
+	flags.IntVar(&cfg.pageSize, "page-size", 100, "number of results to request per page when listing from PagerDuty (max 100)")
+	flags.StringVar(&cfg.jsonReportFilename, "report-json", "", "drift only; file to write the differences to as JSON (use - for stdout)")
+	flags.StringVar(&cfg.junitReportFilename, "report-junit", "", "drift only; file to write the differences to as JUnit XML (use - for stdout)")
+	flags.StringVar(&cfg.namingFilename, "naming", "", "export only; config file or directory whose naming templates are used to find the objects created for each team")
+
+	_ = flags.Parse(args)
+
+	// drift detection is a dry-run that reports the differences
+	if command == commandDrift {
+		cfg.dryRun = true
+	}
+
+	args = flags.Args()
+	if len(args) == 0 && command == commandExport {
+		args = []string{"-"}
+	}
 
-	args := flag.Args()
 	if len(args) == 0 {
-		_, _ = fmt.Fprintf(os.Stderr, "Please supply a JSON file")
+		_, _ = fmt.Fprintf(os.Stderr, "Please supply a config file or directory")
 		os.Exit(-1)
 	}
 
 	cfg.filename = args[0]
 
+	if command == commandOverrides {
+		if len(args) < 2 {
+			_, _ = fmt.Fprintf(os.Stderr, "Please supply a config file and an overrides file")
+			os.Exit(-1)
+		}
+
+		cfg.overridesFilename = args[1]
+	}
+
+	cfg.adopt = map[string]bool{}
+
+	for _, resource := range strings.Split(*adopt, ",") {
+		resource = strings.TrimSpace(resource)
+		if resource == "" {
+			continue
+		}
+
+		if !isAdoptable(resource) {
+			_, _ = fmt.Fprintf(os.Stderr, "Unknown resource type to adopt: %s", resource)
+			os.Exit(-1)
+		}
+
+		cfg.adopt[resource] = true
+	}
+
 	return cfg
 }
 
+func isAdoptable(resource string) bool {
+	for _, adoptable := range pdmanager.AdoptableResources {
+		if resource == adoptable {
+			return true
+		}
+	}
+
+	return false
+}
+
 type config struct {
 	accessToken string
+	baseURL     string
 	filename    string
 	debug       bool
+	dryRun      bool
+	prune       bool
+	pruneUsers  bool
+	pageSize    int
+	maxRetries  int
+	maxBackoff  time.Duration
+	timeout     time.Duration
+	adopt       map[string]bool
+
+	overridesFilename   string
+	secretsFilename     string
+	jsonReportFilename  string
+	junitReportFilename string
+	namingFilename      string
+
+	caseInsensitiveNames bool
+}
+
+// reportsToStdout returns true when a drift report is written to stdout (instead of the plan)
+func (c *config) reportsToStdout() bool {
+	return c.jsonReportFilename == "-" || c.junitReportFilename == "-"
 }
 
 func (c *config) BaseURL() string {
-	return "https://api.pagerduty.com"
+	return c.baseURL
 }
 
 func (c *config) AuthToken() string {
@@ -81,6 +378,38 @@ func (c *config) Debug() bool {
 	return c.debug
 }
 
+func (c *config) DryRun() bool {
+	return c.dryRun
+}
+
+func (c *config) PageSize() int {
+	return c.pageSize
+}
+
+func (c *config) CaseInsensitiveNames() bool {
+	return c.caseInsensitiveNames
+}
+
+func (c *config) MaxRetries() int {
+	return c.maxRetries
+}
+
+func (c *config) MaxBackoff() time.Duration {
+	return c.maxBackoff
+}
+
+func (c *config) Prune() bool {
+	return c.prune
+}
+
+func (c *config) PruneUsers() bool {
+	return c.pruneUsers
+}
+
+func (c *config) Adopt(resource string) bool {
+	return c.adopt[resource]
+}
+
 func (c *config) Filename() string {
 	return c.filename
 }
@@ -0,0 +1,66 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRun_drift(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		timeout               time.Duration
+		expected              int
+	}{
+		{
+			desc: "sad path - timeout",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				select {
+				case <-req.Context().Done():
+				case <-time.After(1 * time.Second):
+				}
+			}),
+			timeout:  50 * time.Millisecond,
+			expected: exitTimeout,
+		},
+		{
+			desc: "sad path - PagerDuty error without a timeout",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusForbidden)
+			}),
+			expected: exitError,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &config{
+				baseURL:  testServer.URL,
+				filename: "../test_data/simple.json",
+				dryRun:   true,
+				timeout:  scenario.timeout,
+				adopt:    map[string]bool{},
+			}
+
+			// call object under test
+			result := run(commandDrift, cfg, logger)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+			assert.NotEqual(t, exitDrift, result)
+		})
+	}
+}
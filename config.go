@@ -0,0 +1,508 @@
+package pdmanager
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// extensions of the config files that are loaded from a directory
+var configExtensions = map[string]bool{
+	".json": true,
+	".yaml": true,
+	".yml":  true,
+}
+
+// configFile is a single file of the config; files can include other files (see loadConfig)
+type configFile struct {
+	companyConfig
+
+	// Include are the files merged into this config; glob patterns relative to the directory of the file
+	Include []string `json:"include"`
+}
+
+// loadConfig loads the config from a JSON or YAML file, or from every JSON and YAML file in a directory.
+// Files can include other files; the teams of all of the files are merged into one config.
+// Every file is loaded once, so a file that is included more than once (e.g. by a glob that matches the including file,
+// or by a file in the same config directory) is skipped after the first time.
+func loadConfig(filename string) (*companyConfig, error) {
+	loader := &configLoader{
+		out:     &companyConfig{},
+		visited: map[string]bool{},
+	}
+
+	info, err := os.Stat(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input file with err: %w", err)
+	}
+
+	if !info.IsDir() {
+		err = loader.load(filename)
+		if err != nil {
+			return nil, err
+		}
+
+		return loader.out, nil
+	}
+
+	entries, err := ioutil.ReadDir(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read input directory with err: %w", err)
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || !configExtensions[strings.ToLower(filepath.Ext(entry.Name()))] {
+			continue
+		}
+
+		err = loader.load(filepath.Join(filename, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return loader.out, nil
+}
+
+type configLoader struct {
+	out *companyConfig
+	// absolute paths of the files loaded so far
+	visited map[string]bool
+}
+
+// load loads the file and the files it includes, unless the file has already been loaded
+func (l *configLoader) load(filename string) error {
+	absolute, err := filepath.Abs(filename)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	if l.visited[absolute] {
+		return nil
+	}
+
+	l.visited[absolute] = true
+
+	contents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read input file with err: %w", err)
+	}
+
+	file := &configFile{}
+
+	root, problems, err := decodeConfig(filename, contents, file, len(l.out.Teams))
+	if err != nil {
+		return err
+	}
+
+	// unknown fields are reported with the other problems when the config is validated
+	l.out.problems = append(l.out.problems, problems...)
+
+	for index, line := range itemLines(root, "teams") {
+		if index < len(file.Teams) {
+			file.Teams[index].source = fmt.Sprintf("%s:%d", filename, line)
+		}
+	}
+
+	err = l.out.merge(&file.companyConfig)
+	if err != nil {
+		return fmt.Errorf("%s: %w", filename, err)
+	}
+
+	for _, pattern := range file.Include {
+		matches, err := filepath.Glob(filepath.Join(filepath.Dir(filename), pattern))
+		if err != nil {
+			return fmt.Errorf("%s: invalid include '%s' with err: %w", filename, pattern, err)
+		}
+
+		if len(matches) == 0 {
+			return fmt.Errorf("%s: no files match include '%s'", filename, pattern)
+		}
+
+		for _, match := range matches {
+			err = l.load(match)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// merge adds the teams and notification profiles of the other config.
+// Other settings can be in more than one file as long as they are the same.
+func (c *companyConfig) merge(other *companyConfig) error {
+	c.Teams = append(c.Teams, other.Teams...)
+
+	if other.DefaultTimezone != "" {
+		if c.DefaultTimezone != "" && c.DefaultTimezone != other.DefaultTimezone {
+			return fmt.Errorf("default_timezone '%s' conflicts with '%s'", other.DefaultTimezone, c.DefaultTimezone)
+		}
+
+		c.DefaultTimezone = other.DefaultTimezone
+	}
+
+	if other.Slack != nil {
+		if c.Slack != nil && *c.Slack != *other.Slack {
+			return errors.New("slack conflicts with the slack of another file")
+		}
+
+		c.Slack = other.Slack
+	}
+
+	err := c.Naming.merge(&other.Naming)
+	if err != nil {
+		return err
+	}
+
+	for name, rules := range other.NotificationProfiles {
+		if _, ok := c.NotificationProfiles[name]; ok {
+			return fmt.Errorf("duplicate notification profile '%s'", name)
+		}
+
+		if c.NotificationProfiles == nil {
+			c.NotificationProfiles = map[string][]*NotificationRule{}
+		}
+
+		c.NotificationProfiles[name] = rules
+	}
+
+	return nil
+}
+
+func (n *Naming) merge(other *Naming) error {
+	for resource, template := range other.Templates {
+		existing, ok := n.Templates[resource]
+		if ok && existing != template {
+			return fmt.Errorf("naming template '%s' conflicts with '%s'", template, existing)
+		}
+
+		if n.Templates == nil {
+			n.Templates = map[string]string{}
+		}
+
+		n.Templates[resource] = template
+	}
+
+	for resource, templates := range other.Legacy {
+		if n.Legacy == nil {
+			n.Legacy = map[string][]string{}
+		}
+
+		for _, template := range templates {
+			if !containsString(n.Legacy[resource], template) {
+				n.Legacy[resource] = append(n.Legacy[resource], template)
+			}
+		}
+	}
+
+	return nil
+}
+
+// decodeConfig decodes the JSON or YAML contents into out using the json tags of out.
+// YAML is a superset of JSON so both are parsed as YAML and then converted to JSON; errors include the file and line.
+// Keys that are not fields of out (e.g. misspelled keys) are returned as problems (with the path and line of each key) and
+// are otherwise ignored, so that they can be reported with the other problems of the config.
+// firstTeam is the index of the first team of the file in the merged config, so the paths match those of the other problems.
+func decodeConfig(filename string, contents []byte, out interface{}, firstTeam int) (*yaml.Node, []*Problem, error) {
+	root := &yaml.Node{}
+
+	err := yaml.Unmarshal(contents, root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: failed to parse config with err: %s", filename, strings.TrimPrefix(err.Error(), "yaml: "))
+	}
+
+	converter := &jsonConverter{}
+
+	err = converter.convert(root)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", filename, err)
+	}
+
+	finder := &unknownFieldFinder{filename: filename, firstTeam: firstTeam}
+	finder.find(root, reflect.TypeOf(out), "")
+
+	decoder := json.NewDecoder(bytes.NewReader(converter.buf.Bytes()))
+	if len(finder.problems) == 0 {
+		decoder.DisallowUnknownFields()
+	}
+
+	err = decoder.Decode(out)
+	if err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return nil, nil, fmt.Errorf("%s:%d: invalid value of '%s' (must be %s)",
+				filename, converter.line(typeErr.Offset), typeErr.Field, typeErr.Type)
+		}
+
+		if strings.HasPrefix(err.Error(), "json: unknown field ") {
+			// only when a key is missed by the unknownFieldFinder
+			return nil, nil, fmt.Errorf("%s:%d: %s", filename, converter.line(decoder.InputOffset()),
+				strings.TrimPrefix(err.Error(), "json: "))
+		}
+
+		return nil, nil, fmt.Errorf("%s: failed to parse config with err: %w", filename, err)
+	}
+
+	return root, finder.problems, nil
+}
+
+// unknownFieldFinder records the keys of the YAML that are not fields of the type they are decoded into
+type unknownFieldFinder struct {
+	filename  string
+	firstTeam int
+	problems  []*Problem
+}
+
+func (f *unknownFieldFinder) find(node *yaml.Node, typ reflect.Type, path string) {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) > 0 {
+			f.find(node.Content[0], typ, path)
+		}
+
+		return
+
+	case yaml.AliasNode:
+		f.find(node.Alias, typ, path)
+		return
+	}
+
+	switch typ.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			key := node.Content[index]
+			fieldPath := joinPath(path, key.Value)
+
+			field, ok := jsonField(typ, key.Value)
+			if !ok {
+				f.problems = append(f.problems, &Problem{
+					Severity: SeverityError,
+					Source:   fmt.Sprintf("%s:%d", f.filename, key.Line),
+					Path:     fieldPath,
+					Message:  fmt.Sprintf("unknown field '%s'", key.Value),
+				})
+
+				continue
+			}
+
+			f.find(node.Content[index+1], field.Type, fieldPath)
+		}
+
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			f.find(node.Content[index+1], typ.Elem(), joinPath(path, node.Content[index].Value))
+		}
+
+	case reflect.Slice:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+
+		offset := 0
+		if path == "teams" {
+			offset = f.firstTeam
+		}
+
+		for index, item := range node.Content {
+			f.find(item, typ.Elem(), fmt.Sprintf("%s[%d]", path, offset+index))
+		}
+	}
+}
+
+// jsonField returns the field of the struct that the key is decoded into (including the fields of embedded structs);
+// like encoding/json, keys match the json names of the fields ignoring case
+func jsonField(typ reflect.Type, key string) (reflect.StructField, bool) {
+	for index := 0; index < typ.NumField(); index++ {
+		field := typ.Field(index)
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "-" {
+			continue
+		}
+
+		if field.Anonymous && name == "" {
+			embedded, ok := jsonField(field.Type, key)
+			if ok {
+				return embedded, true
+			}
+
+			continue
+		}
+
+		if field.PkgPath != "" {
+			// unexported
+			continue
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+
+		if strings.EqualFold(name, key) {
+			return field, true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// itemLines returns the line numbers of the items of the top level list with the key (e.g. the teams) in the file
+func itemLines(root *yaml.Node, key string) []int {
+	if root.Kind != yaml.DocumentNode || len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return nil
+	}
+
+	mapping := root.Content[0]
+
+	for index := 0; index+1 < len(mapping.Content); index += 2 {
+		if mapping.Content[index].Value != key {
+			continue
+		}
+
+		var out []int
+		for _, item := range mapping.Content[index+1].Content {
+			out = append(out, item.Line)
+		}
+
+		return out
+	}
+
+	return nil
+}
+
+// jsonConverter converts YAML to JSON and records where the YAML nodes are in the JSON (to report the lines of errors)
+type jsonConverter struct {
+	buf bytes.Buffer
+	// offsets in the JSON of the nodes (ascending) and their lines in the YAML
+	offsets []int64
+	lines   []int
+}
+
+func (c *jsonConverter) convert(node *yaml.Node) error {
+	c.offsets = append(c.offsets, int64(c.buf.Len()))
+	c.lines = append(c.lines, node.Line)
+
+	switch node.Kind {
+	case yaml.DocumentNode:
+		if len(node.Content) == 0 {
+			c.buf.WriteString("null")
+			return nil
+		}
+
+		return c.convert(node.Content[0])
+
+	case yaml.AliasNode:
+		return c.convert(node.Alias)
+
+	case yaml.MappingNode:
+		c.buf.WriteByte('{')
+
+		for index := 0; index+1 < len(node.Content); index += 2 {
+			if index > 0 {
+				c.buf.WriteByte(',')
+			}
+
+			key, _ := json.Marshal(node.Content[index].Value)
+			c.buf.Write(key)
+			c.buf.WriteByte(':')
+
+			err := c.convert(node.Content[index+1])
+			if err != nil {
+				return err
+			}
+		}
+
+		c.buf.WriteByte('}')
+
+	case yaml.SequenceNode:
+		c.buf.WriteByte('[')
+
+		for index, item := range node.Content {
+			if index > 0 {
+				c.buf.WriteByte(',')
+			}
+
+			err := c.convert(item)
+			if err != nil {
+				return err
+			}
+		}
+
+		c.buf.WriteByte(']')
+
+	default:
+		var value interface{} = node.Value
+
+		// strings (including dates and times) are kept as written
+		if node.Tag != "!!str" && node.Tag != "!!timestamp" {
+			err := node.Decode(&value)
+			if err != nil {
+				return fmt.Errorf("line %d: %s", node.Line, strings.TrimPrefix(err.Error(), "yaml: "))
+			}
+		}
+
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("line %d: invalid value '%s'", node.Line, node.Value)
+		}
+
+		c.buf.Write(encoded)
+	}
+
+	return nil
+}
+
+// line returns the line of the node at the offset in the JSON
+func (c *jsonConverter) line(offset int64) int {
+	// the offset of json errors is the end of the value, so use the last node that starts before it
+	index := sort.Search(len(c.offsets), func(i int) bool {
+		return c.offsets[i] >= offset
+	})
+
+	if index == 0 {
+		return 1
+	}
+
+	return c.lines[index-1]
+}
+
+func containsString(values []string, value string) bool {
+	for _, thisValue := range values {
+		if thisValue == value {
+			return true
+		}
+	}
+
+	return false
+}
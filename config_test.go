@@ -0,0 +1,146 @@
+package pdmanager
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadConfig(t *testing.T) {
+	scenarios := []struct {
+		desc            string
+		in              string
+		expectedTeams   []string
+		expectedSources []string
+		// expectedErr is part of the expected error
+		expectedErr string
+	}{
+		{
+			desc:            "yaml with includes",
+			in:              "./test_data/yaml/company.yaml",
+			expectedTeams:   []string{"Test Team A", "Test Team B"},
+			expectedSources: []string{"test_data/yaml/teams/team_a.yaml:2", "test_data/yaml/teams/team_b.yaml:2"},
+		},
+		{
+			desc:            "directory",
+			in:              "./test_data/yaml/teams",
+			expectedTeams:   []string{"Test Team A", "Test Team B"},
+			expectedSources: []string{"test_data/yaml/teams/team_a.yaml:2", "test_data/yaml/teams/team_b.yaml:2"},
+		},
+		{
+			desc:            "json",
+			in:              "./test_data/simple.json",
+			expectedTeams:   []string{"Test Team A"},
+			expectedSources: []string{"./test_data/simple.json:3"},
+		},
+		{
+			desc:        "sad path - invalid value",
+			in:          "./test_data/invalid_type.yaml",
+			expectedErr: "./test_data/invalid_type.yaml:9: invalid value of 'teams.",
+		},
+		{
+			desc:            "include cycle",
+			in:              "./test_data/include_cycle.yaml",
+			expectedTeams:   []string{"Test Team C"},
+			expectedSources: []string{"./test_data/include_cycle.yaml:5"},
+		},
+		{
+			desc:            "include glob matches the including file",
+			in:              "./test_data/yaml_flat/company.yaml",
+			expectedTeams:   []string{"Test Team A"},
+			expectedSources: []string{"test_data/yaml_flat/team_a.yaml:2"},
+		},
+		{
+			desc:            "directory with includes",
+			in:              "./test_data/yaml_flat",
+			expectedTeams:   []string{"Test Team A"},
+			expectedSources: []string{"test_data/yaml_flat/team_a.yaml:2"},
+		},
+		{
+			desc:        "sad path - missing file",
+			in:          "./test_data/missing.yaml",
+			expectedErr: "failed to read input file",
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result, resultErr := loadConfig(scenario.in)
+
+			// validation
+			if scenario.expectedErr != "" {
+				require.Error(t, resultErr)
+				assert.Contains(t, resultErr.Error(), scenario.expectedErr)
+				return
+			}
+
+			require.NoError(t, resultErr)
+
+			var teams, sources []string
+			for _, team := range result.Teams {
+				teams = append(teams, team.Name)
+				sources = append(sources, team.source)
+			}
+
+			assert.Equal(t, scenario.expectedTeams, teams)
+			assert.Equal(t, scenario.expectedSources, sources)
+		})
+	}
+}
+
+func TestCompanyConfig_merge(t *testing.T) {
+	scenarios := []struct {
+		desc      string
+		in        *companyConfig
+		expectErr bool
+	}{
+		{
+			desc: "same settings",
+			in: &companyConfig{
+				Teams:           []*Team{{Name: "B"}},
+				DefaultTimezone: "Asia/Jakarta",
+				Naming:          Naming{Templates: map[string]string{"schedule": "{team} On-call"}},
+			},
+			expectErr: false,
+		},
+		{
+			desc:      "sad path - different time zone",
+			in:        &companyConfig{DefaultTimezone: "Europe/London"},
+			expectErr: true,
+		},
+		{
+			desc:      "sad path - different naming template",
+			in:        &companyConfig{Naming: Naming{Templates: map[string]string{"schedule": "{team}"}}},
+			expectErr: true,
+		},
+		{
+			desc: "sad path - duplicate notification profile",
+			in: &companyConfig{
+				NotificationProfiles: map[string][]*NotificationRule{"on-call": nil},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			config := &companyConfig{
+				Teams:                []*Team{{Name: "A"}},
+				DefaultTimezone:      "Asia/Jakarta",
+				Naming:               Naming{Templates: map[string]string{"schedule": "{team} On-call"}},
+				NotificationProfiles: map[string][]*NotificationRule{"on-call": nil},
+			}
+
+			// call object under test
+			resultErr := config.merge(scenario.in)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package pdmanager
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// name of the JUnit test suite of drift reports
+const driftSuite = "pagerduty-drift"
+
+// WriteJSON writes a machine readable version of the plan (e.g. for drift detection in CI)
+func (p *Plan) WriteJSON(writer io.Writer) error {
+	report := &driftReport{
+		Drift: !p.IsEmpty(),
+		Summary: &driftSummary{
+			Create: p.Count(ActionCreate),
+			Update: p.Count(ActionUpdate),
+			Delete: p.Count(ActionDelete),
+		},
+		Changes: []*driftChange{},
+	}
+
+	for _, change := range p.Changes {
+		thisChange := &driftChange{
+			Action:   change.Action,
+			Resource: change.Resource,
+			Name:     change.Name,
+			Details:  change.Details,
+		}
+
+		for _, field := range change.Fields {
+			thisChange.Fields = append(thisChange.Fields, &driftField{
+				Path:    field.Path,
+				Live:    field.Live,
+				Desired: field.Desired,
+			})
+		}
+
+		report.Changes = append(report.Changes, thisChange)
+	}
+
+	encoder := json.NewEncoder(writer)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(report)
+}
+
+// WriteJUnit writes the plan as a JUnit XML report; each change is a failed test case.
+// When there are no changes a single passing test case is written.
+func (p *Plan) WriteJUnit(writer io.Writer) error {
+	suite := &junitSuite{
+		Name:     driftSuite,
+		Tests:    len(p.Changes),
+		Failures: len(p.Changes),
+	}
+
+	for _, change := range p.Changes {
+		var lines []string
+		lines = append(lines, change.Details...)
+
+		for _, field := range change.Fields {
+			lines = append(lines, field.String())
+		}
+
+		suite.Cases = append(suite.Cases, &junitCase{
+			ClassName: driftSuite + "." + strings.ReplaceAll(change.Resource, " ", "_"),
+			Name:      fmt.Sprintf("%s %s %q", change.Action, change.Resource, change.Name),
+			Failure: &junitFailure{
+				Message: fmt.Sprintf("%s %q differs from the config", change.Resource, change.Name),
+				Type:    string(change.Action),
+				Text:    strings.Join(lines, "\n"),
+			},
+		})
+	}
+
+	if p.IsEmpty() {
+		suite.Tests = 1
+		suite.Cases = append(suite.Cases, &junitCase{
+			ClassName: driftSuite,
+			Name:      "PagerDuty matches the config",
+		})
+	}
+
+	_, err := io.WriteString(writer, xml.Header)
+	if err != nil {
+		return err
+	}
+
+	encoder := xml.NewEncoder(writer)
+	encoder.Indent("", "  ")
+
+	err = encoder.Encode(suite)
+	if err != nil {
+		return err
+	}
+
+	_, err = io.WriteString(writer, "\n")
+
+	return err
+}
+
+type driftReport struct {
+	Drift   bool           `json:"drift"`
+	Summary *driftSummary  `json:"summary"`
+	Changes []*driftChange `json:"changes"`
+}
+
+type driftSummary struct {
+	Create int `json:"create"`
+	Update int `json:"update"`
+	Delete int `json:"delete"`
+}
+
+type driftChange struct {
+	Action   Action        `json:"action"`
+	Resource string        `json:"resource"`
+	Name     string        `json:"name"`
+	Details  []string      `json:"details,omitempty"`
+	Fields   []*driftField `json:"fields,omitempty"`
+}
+
+type driftField struct {
+	Path    string `json:"path"`
+	Live    string `json:"live"`
+	Desired string `json:"desired"`
+}
+
+type junitSuite struct {
+	XMLName  xml.Name     `xml:"testsuite"`
+	Name     string       `xml:"name,attr"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Cases    []*junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
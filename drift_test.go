@@ -0,0 +1,110 @@
+package pdmanager
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan_WriteJSON(t *testing.T) {
+	scenarios := []struct {
+		desc     string
+		in       *Plan
+		expected string
+	}{
+		{
+			desc:     "no drift",
+			in:       &Plan{},
+			expected: `{"drift": false, "summary": {"create": 0, "update": 0, "delete": 0}, "changes": []}`,
+		},
+		{
+			desc: "drift",
+			in: &Plan{
+				Changes: []*Change{
+					{Action: ActionCreate, Resource: resourceUser, Name: "ringo@beatles.com", Details: []string{"name: Ringo"}},
+					{
+						Action:   ActionUpdate,
+						Resource: resourceSchedule,
+						Name:     "Test Team A",
+						Fields:   []*FieldDiff{{Path: "time_zone", Live: "UTC", Desired: "Asia/Jakarta"}},
+					},
+				},
+			},
+			expected: `{"drift": true, "summary": {"create": 1, "update": 1, "delete": 0}, "changes": [
+				{"action": "create", "resource": "user", "name": "ringo@beatles.com", "details": ["name: Ringo"]},
+				{"action": "update", "resource": "schedule", "name": "Test Team A",
+					"fields": [{"path": "time_zone", "live": "UTC", "desired": "Asia/Jakarta"}]}
+			]}`,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			buffer := &bytes.Buffer{}
+
+			// call object under test
+			resultErr := scenario.in.WriteJSON(buffer)
+
+			// validation
+			require.NoError(t, resultErr)
+			assert.JSONEq(t, scenario.expected, buffer.String())
+		})
+	}
+}
+
+func TestPlan_WriteJUnit(t *testing.T) {
+	scenarios := []struct {
+		desc     string
+		in       *Plan
+		expected string
+	}{
+		{
+			desc: "no drift",
+			in:   &Plan{},
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="pagerduty-drift" tests="1" failures="0">
+  <testcase classname="pagerduty-drift" name="PagerDuty matches the config"></testcase>
+</testsuite>
+`,
+		},
+		{
+			desc: "drift",
+			in: &Plan{
+				Changes: []*Change{
+					{
+						Action:   ActionUpdate,
+						Resource: resourceEscalation,
+						Name:     "Test Team A",
+						Fields:   []*FieldDiff{{Path: "num_loops", Live: "1", Desired: "9"}},
+					},
+				},
+			},
+			expected: `<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="pagerduty-drift" tests="1" failures="1">
+  <testcase classname="pagerduty-drift.escalation_policy" name="update escalation policy &#34;Test Team A&#34;">
+    <failure message="escalation policy &#34;Test Team A&#34; differs from the config" type="update">num_loops: &#34;1&#34; =&gt; &#34;9&#34;</failure>
+  </testcase>
+</testsuite>
+`,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			buffer := &bytes.Buffer{}
+
+			// call object under test
+			resultErr := scenario.in.WriteJUnit(buffer)
+
+			// validation
+			require.NoError(t, resultErr)
+			assert.Equal(t, scenario.expected, buffer.String())
+		})
+	}
+}
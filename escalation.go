@@ -0,0 +1,401 @@
+package pdmanager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/corsc/pagerduty-manager/internal/escalations"
+	"github.com/corsc/pagerduty-manager/internal/schedules"
+	"go.uber.org/zap"
+)
+
+const (
+	// targetOnCall is the escalation target for the team's own schedule
+	targetOnCall = "on-call"
+
+	defaultEscalationDelay = 5
+	maxNumLoops            = 9
+)
+
+// map of escalation target roles to the member roles they include
+var escalationRoles = map[string]string{
+	roleMember:   roleMember,
+	roleLead:     roleLead,
+	roleDeptHead: roleDeptHead,
+}
+
+// TeamEscalation is the (optional) layout of the team's escalation policy.
+// When missing, the policy escalates to the team's schedule, then the leads and then the dept heads.
+type TeamEscalation struct {
+	Tiers []*EscalationTier `json:"tiers"`
+	// NumLoops is the number of times the policy repeats (default: 9)
+	NumLoops *int `json:"num_loops"`
+	// HandoffNotifications is "always" (default) or "has_services"
+	HandoffNotifications string `json:"on_call_handoff_notifications"`
+}
+
+// EscalationTier is a level of the escalation policy
+type EscalationTier struct {
+	// DelayMinutes before escalating to the next tier (default: 5)
+	DelayMinutes int `json:"delay_minutes"`
+	// Roles are "on-call" (the team's schedule) or the roles of the team members to notify (member, lead or dept-head)
+	Roles []string `json:"roles"`
+	// Schedules are the names of other PagerDuty schedules (e.g. a shared SRE schedule)
+	Schedules []string `json:"schedules"`
+	// Users are the emails of users to notify
+	Users []string `json:"users"`
+	// Teams are other teams in the config to notify (e.g. a platform team as the final escalation)
+	Teams []*TeamTarget `json:"teams"`
+}
+
+// TeamTarget refers to the schedule and/or members of another team in the config
+type TeamTarget struct {
+	// Name of the team
+	Name string `json:"name"`
+	// Roles are "on-call" (the team's schedule) or the roles of the team members to notify (member, lead or dept-head)
+	Roles []string `json:"roles"`
+
+	team *Team
+}
+
+// validate records the problems with the escalation; path is the path of the escalation
+func (e *TeamEscalation) validate(v *validator, path string) {
+	if e == nil {
+		return
+	}
+
+	if len(e.Tiers) == 0 {
+		v.errorf(path+".tiers", "escalation must have at least one tier")
+	}
+
+	if e.NumLoops != nil && (*e.NumLoops < 0 || *e.NumLoops > maxNumLoops) {
+		v.errorf(path+".num_loops", "invalid num_loops %d (must be 0 to %d)", *e.NumLoops, maxNumLoops)
+	}
+
+	switch e.HandoffNotifications {
+	case "", escalations.HandoffNotificationsAlways, escalations.HandoffNotificationsHasServices:
+		// valid
+
+	default:
+		v.errorf(path+".on_call_handoff_notifications", "invalid on_call_handoff_notifications '%s' (must be always or has_services)",
+			e.HandoffNotifications)
+	}
+
+	for tierIndex, tier := range e.Tiers {
+		tierPath := fmt.Sprintf("%s.tiers[%d]", path, tierIndex)
+
+		if tier.DelayMinutes < 0 {
+			v.errorf(tierPath+".delay_minutes", "invalid delay %d", tier.DelayMinutes)
+		}
+
+		if len(tier.Roles)+len(tier.Schedules)+len(tier.Users)+len(tier.Teams) == 0 {
+			v.errorf(tierPath, "escalation tier has no targets")
+		}
+
+		validateEscalationRoles(v, tierPath+".roles", tier.Roles)
+
+		for targetIndex, target := range tier.Teams {
+			targetPath := fmt.Sprintf("%s.teams[%d]", tierPath, targetIndex)
+
+			if len(target.Roles) == 0 {
+				v.errorf(targetPath+".roles", "reference to team '%s' has no roles", target.Name)
+			}
+
+			validateEscalationRoles(v, targetPath+".roles", target.Roles)
+		}
+	}
+}
+
+func validateEscalationRoles(v *validator, path string, roles []string) {
+	for index, role := range roles {
+		_, ok := escalationRoles[role]
+		if role != targetOnCall && !ok {
+			v.errorf(fmt.Sprintf("%s[%d]", path, index), "invalid role '%s' (must be on-call, member, lead or dept-head)", role)
+		}
+	}
+}
+
+// resolveTeamReferences links the references to other teams in escalation policies with those teams
+func (m *Manager) resolveTeamReferences(v *validator) {
+	for teamIndex, team := range m.companyConfig.Teams {
+		if team.Escalation == nil {
+			continue
+		}
+
+		v.source = team.source
+
+		for tierIndex, tier := range team.Escalation.Tiers {
+			for targetIndex, target := range tier.Teams {
+				target.team = m.findTeam(target.Name)
+				if target.team == nil {
+					v.errorf(fmt.Sprintf("teams[%d].escalation.tiers[%d].teams[%d].name", teamIndex, tierIndex, targetIndex),
+						"unknown team '%s'", target.Name)
+				}
+			}
+		}
+	}
+
+	v.source = ""
+}
+
+// escalationCycleError is returned when the references to other teams in escalation policies form a cycle
+type escalationCycleError struct {
+	// path of the reference that closes the cycle
+	path  string
+	teams []string
+}
+
+func (e *escalationCycleError) Error() string {
+	return "escalation references form a cycle: " + strings.Join(e.teams, " -> ")
+}
+
+// escalationOrder returns the teams ordered so that teams are after the teams their escalation policy refers to.
+// Returns an escalationCycleError when the references form a cycle.
+// The references must have been resolved (see resolveTeamReferences).
+func (m *Manager) escalationOrder() ([]*Team, error) {
+	var out []*Team
+
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	state := map[*Team]int{}
+
+	indexes := map[*Team]int{}
+	for index, team := range m.companyConfig.Teams {
+		indexes[team] = index
+	}
+
+	var visit func(team *Team, names []string) error
+	visit = func(team *Team, names []string) error {
+		state[team] = visiting
+		names = append(names, team.Name)
+
+		for tierIndex, tier := range team.tiers() {
+			for targetIndex, target := range tier.Teams {
+				switch state[target.team] {
+				case visited:
+					continue
+
+				case visiting:
+					return &escalationCycleError{
+						path:  fmt.Sprintf("teams[%d].escalation.tiers[%d].teams[%d].name", indexes[team], tierIndex, targetIndex),
+						teams: append(names, target.team.Name),
+					}
+				}
+
+				err := visit(target.team, names)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		state[team] = visited
+
+		out = append(out, team)
+
+		return nil
+	}
+
+	for _, team := range m.companyConfig.Teams {
+		if state[team] == visited {
+			continue
+		}
+
+		err := visit(team, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return out, nil
+}
+
+// tiers returns the tiers of the team's escalation policy (if any)
+func (t *Team) tiers() []*EscalationTier {
+	if t.Escalation == nil {
+		return nil
+	}
+
+	return t.Escalation.Tiers
+}
+
+// resolveEscalationTargets finds the IDs of the schedules and users the team's escalation policy refers to by name or email.
+// Schedules and users in the config are used first as they may not exist yet during a dry-run.
+func (m *Manager) resolveEscalationTargets(ctx context.Context, team *Team) error {
+	team.escalationIDs = map[string]string{}
+
+	if team.Escalation == nil {
+		return nil
+	}
+
+	for _, tier := range team.Escalation.Tiers {
+		for _, name := range tier.Schedules {
+			scheduleID, err := m.findScheduleID(ctx, name)
+			if err != nil {
+				m.logger.Error("failed to sync escalation - fetch schedule failed", zap.String("schedule", name), zap.Error(err))
+				return err
+			}
+
+			team.escalationIDs[name] = scheduleID
+		}
+
+		for _, email := range tier.Users {
+			userID, err := m.findUserID(ctx, email)
+			if err != nil {
+				m.logger.Error("failed to sync escalation - fetch user failed", zap.String("email", email), zap.Error(err))
+				return err
+			}
+
+			team.escalationIDs[email] = userID
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) findScheduleID(ctx context.Context, name string) (string, error) {
+	for _, team := range m.companyConfig.Teams {
+		if team.ScheduleName == name {
+			return team.ScheduleID, nil
+		}
+	}
+
+	fetchedSchedule, err := m.scheduleManager.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, schedules.ErrNoSuchSchedule) {
+			return "", fmt.Errorf("unknown schedule '%s'", name)
+		}
+
+		return "", err
+	}
+
+	return fetchedSchedule.ID, nil
+}
+
+func (m *Manager) findUserID(ctx context.Context, email string) (string, error) {
+	for _, team := range m.companyConfig.Teams {
+		member := team.findMember(email)
+		if member != nil {
+			return member.ID, nil
+		}
+	}
+
+	fetchedUser, err := m.userManager.GetByEmail(ctx, email)
+	if err != nil {
+		return "", err
+	}
+
+	return fetchedUser.ID, nil
+}
+
+// GetStructure returns the layout of the escalation policy or nil for the default layout
+func (t *Team) GetStructure() *escalations.Structure {
+	if t.Escalation == nil {
+		return nil
+	}
+
+	out := &escalations.Structure{
+		NumLoops:             maxNumLoops,
+		HandoffNotifications: escalations.HandoffNotificationsAlways,
+	}
+
+	if t.Escalation.NumLoops != nil {
+		out.NumLoops = *t.Escalation.NumLoops
+	}
+
+	if t.Escalation.HandoffNotifications != "" {
+		out.HandoffNotifications = t.Escalation.HandoffNotifications
+	}
+
+	for _, tier := range t.Escalation.Tiers {
+		newTier := &escalations.Tier{
+			DelayMinutes: tier.DelayMinutes,
+		}
+
+		if newTier.DelayMinutes == 0 {
+			newTier.DelayMinutes = defaultEscalationDelay
+		}
+
+		t.addEscalationTargets(newTier, tier.Roles)
+
+		for _, name := range tier.Schedules {
+			newTier.ScheduleIDs = append(newTier.ScheduleIDs, t.escalationIDs[name])
+		}
+
+		for _, email := range tier.Users {
+			newTier.UserIDs = append(newTier.UserIDs, t.escalationIDs[email])
+		}
+
+		for _, target := range tier.Teams {
+			target.team.addEscalationTargets(newTier, target.Roles)
+		}
+
+		out.Tiers = append(out.Tiers, newTier)
+	}
+
+	return out
+}
+
+// addEscalationTargets adds the team's schedule and/or the members with the roles to the tier
+func (t *Team) addEscalationTargets(tier *escalations.Tier, roles []string) {
+	for _, role := range roles {
+		if role == targetOnCall {
+			tier.ScheduleIDs = append(tier.ScheduleIDs, t.ScheduleID)
+			continue
+		}
+
+		for _, member := range t.Members {
+			if member.Role == escalationRoles[role] {
+				tier.UserIDs = append(tier.UserIDs, member.ID)
+			}
+		}
+	}
+}
+
+// escalationTargetNames returns the name of the team's schedule and/or the emails of the members with the roles
+func (t *Team) escalationTargetNames(roles []string) []string {
+	var out []string
+
+	for _, role := range roles {
+		if role == targetOnCall {
+			out = append(out, t.ScheduleName)
+			continue
+		}
+
+		out = append(out, t.getEmails(escalationRoles[role])...)
+	}
+
+	return out
+}
+
+func escalationDetails(team *Team) []string {
+	if team.Escalation == nil {
+		return []string{
+			"level 1: " + team.ScheduleName,
+			"level 2: " + strings.Join(team.getEmails(roleLead), ", "),
+			"level 3: " + strings.Join(team.getEmails(roleDeptHead), ", "),
+		}
+	}
+
+	var out []string
+
+	for index, tier := range team.Escalation.Tiers {
+		targets := team.escalationTargetNames(tier.Roles)
+		targets = append(targets, tier.Schedules...)
+		targets = append(targets, tier.Users...)
+
+		for _, target := range tier.Teams {
+			targets = append(targets, target.team.escalationTargetNames(target.Roles)...)
+		}
+
+		out = append(out, "level "+strconv.Itoa(index+1)+": "+strings.Join(targets, ", "))
+	}
+
+	return out
+}
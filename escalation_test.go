@@ -0,0 +1,284 @@
+package pdmanager
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/corsc/pagerduty-manager/internal/escalations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestTeam_GetStructure(t *testing.T) {
+	twoLoops := 2
+	tooManyLoops := 10
+
+	platform := &Team{
+		Name:       "Platform",
+		ScheduleID: "PLATFORM",
+		Members:    []*Member{{ID: "YOKO", Email: "yoko@beatles.com", Role: roleDeptHead}},
+	}
+
+	scenarios := []struct {
+		desc           string
+		in             *TeamEscalation
+		expected       *escalations.Structure
+		expectedErrors []string
+	}{
+		{
+			desc:     "no escalation uses the default",
+			in:       nil,
+			expected: nil,
+		},
+		{
+			desc: "on-call, then the leads and the SRE schedule, then the head of engineering, then the platform team",
+			in: &TeamEscalation{
+				NumLoops:             &twoLoops,
+				HandoffNotifications: "has_services",
+				Tiers: []*EscalationTier{
+					{DelayMinutes: 10, Roles: []string{"on-call"}},
+					{Roles: []string{"lead"}, Schedules: []string{"SRE Schedule"}},
+					{Users: []string{"brian@beatles.com"}},
+					{Teams: []*TeamTarget{{Name: "Platform", Roles: []string{"on-call", "dept-head"}, team: platform}}},
+				},
+			},
+			expected: &escalations.Structure{
+				Tiers: []*escalations.Tier{
+					{DelayMinutes: 10, ScheduleIDs: []string{"SCHEDULE"}},
+					{DelayMinutes: 5, ScheduleIDs: []string{"SRE"}, UserIDs: []string{"PAUL"}},
+					{DelayMinutes: 5, UserIDs: []string{"BRIAN"}},
+					{DelayMinutes: 5, ScheduleIDs: []string{"PLATFORM"}, UserIDs: []string{"YOKO"}},
+				},
+				NumLoops:             2,
+				HandoffNotifications: "has_services",
+			},
+		},
+		{
+			desc:           "sad path - no tiers",
+			in:             &TeamEscalation{},
+			expectedErrors: []string{"escalation.tiers"},
+		},
+		{
+			desc: "sad path - too many loops",
+			in: &TeamEscalation{
+				NumLoops: &tooManyLoops,
+				Tiers:    []*EscalationTier{{Roles: []string{"on-call"}}},
+			},
+			expectedErrors: []string{"escalation.num_loops"},
+		},
+		{
+			desc: "sad path - invalid role",
+			in: &TeamEscalation{
+				Tiers: []*EscalationTier{{Roles: []string{"observer"}}},
+			},
+			expectedErrors: []string{"escalation.tiers[0].roles[0]"},
+		},
+		{
+			desc: "sad path - team reference without roles",
+			in: &TeamEscalation{
+				Tiers: []*EscalationTier{{Teams: []*TeamTarget{{Name: "Platform"}}}},
+			},
+			expectedErrors: []string{"escalation.tiers[0].teams[0].roles"},
+		},
+		{
+			desc: "sad path - every problem is reported",
+			in: &TeamEscalation{
+				NumLoops:             &tooManyLoops,
+				HandoffNotifications: "never",
+				Tiers: []*EscalationTier{
+					{Roles: []string{"on-call"}},
+					{DelayMinutes: -1, Roles: []string{"lead", "observer"}},
+					{Teams: []*TeamTarget{{Name: "Platform", Roles: []string{"drummer"}}}},
+				},
+			},
+			expectedErrors: []string{
+				"escalation.num_loops",
+				"escalation.on_call_handoff_notifications",
+				"escalation.tiers[1].delay_minutes",
+				"escalation.tiers[1].roles[1]",
+				"escalation.tiers[2].teams[0].roles[0]",
+			},
+		},
+		{
+			desc: "sad path - tier without targets",
+			in: &TeamEscalation{
+				Tiers: []*EscalationTier{{DelayMinutes: 5}},
+			},
+			expectedErrors: []string{"escalation.tiers[0]"},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			team := &Team{
+				ScheduleID: "SCHEDULE",
+				Escalation: scenario.in,
+				Members: []*Member{
+					{ID: "PAUL", Email: "paul@beatles.com", Role: roleLead},
+					{ID: "GEORGE", Email: "george@beatles.com", Role: roleMember},
+				},
+				escalationIDs: map[string]string{
+					"SRE Schedule":      "SRE",
+					"brian@beatles.com": "BRIAN",
+				},
+			}
+
+			v := &validator{}
+
+			// call object under test
+			scenario.in.validate(v, "escalation")
+
+			// validation
+			assert.Equal(t, scenario.expectedErrors, problemPaths(v.problems))
+			if len(scenario.expectedErrors) == 0 {
+				assert.Equal(t, scenario.expected, team.GetStructure())
+			}
+		})
+	}
+}
+
+func TestManager_resolveTeamReferences(t *testing.T) {
+	platformReference := func(roles ...string) *TeamEscalation {
+		return &TeamEscalation{
+			Tiers: []*EscalationTier{
+				{Roles: []string{"on-call"}},
+				{Teams: []*TeamTarget{{Name: "Platform", Roles: roles}}},
+			},
+		}
+	}
+
+	scenarios := []struct {
+		desc           string
+		in             []*Team
+		expectedErrors []string
+	}{
+		{
+			desc: "referenced team defined later in the file",
+			in: []*Team{
+				{Name: "Checkout", Escalation: platformReference("lead")},
+				{Name: "Search", Escalation: platformReference("on-call")},
+				{Name: "Platform"},
+			},
+		},
+		{
+			desc: "sad path - unknown teams",
+			in: []*Team{
+				{Name: "Checkout", Escalation: platformReference("on-call")},
+				{Name: "Search", Escalation: platformReference("lead")},
+			},
+			expectedErrors: []string{
+				"teams[0].escalation.tiers[1].teams[0].name",
+				"teams[1].escalation.tiers[1].teams[0].name",
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			manager := New(&testConfig{}, zap.NewNop())
+			manager.companyConfig.Teams = scenario.in
+
+			v := &validator{}
+
+			// call object under test
+			manager.resolveTeamReferences(v)
+
+			// validation
+			assert.Equal(t, scenario.expectedErrors, problemPaths(v.problems))
+
+			if len(scenario.expectedErrors) == 0 {
+				for _, team := range scenario.in {
+					if team.Escalation == nil {
+						continue
+					}
+
+					for _, tier := range team.Escalation.Tiers {
+						for _, target := range tier.Teams {
+							assert.Equal(t, target.Name, target.team.Name)
+						}
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestManager_escalationOrder(t *testing.T) {
+	platformReference := func(roles ...string) *TeamEscalation {
+		return &TeamEscalation{
+			Tiers: []*EscalationTier{
+				{Roles: []string{"on-call"}},
+				{Teams: []*TeamTarget{{Name: "Platform", Roles: roles}}},
+			},
+		}
+	}
+
+	scenarios := []struct {
+		desc         string
+		in           []*Team
+		expected     []string
+		expectedPath string
+	}{
+		{
+			desc: "referenced team defined later in the file is synced first",
+			in: []*Team{
+				{Name: "Checkout", Escalation: platformReference("lead")},
+				{Name: "Search", Escalation: platformReference("on-call")},
+				{Name: "Platform"},
+			},
+			expected: []string{"Platform", "Checkout", "Search"},
+		},
+		{
+			desc: "sad path - cycle",
+			in: []*Team{
+				{Name: "Checkout", Escalation: platformReference("on-call")},
+				{
+					Name: "Platform",
+					Escalation: &TeamEscalation{
+						Tiers: []*EscalationTier{{Teams: []*TeamTarget{{Name: "Checkout", Roles: []string{"lead"}}}}},
+					},
+				},
+			},
+			expectedPath: "teams[1].escalation.tiers[0].teams[0].name",
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			manager := New(&testConfig{}, zap.NewNop())
+			manager.companyConfig.Teams = scenario.in
+
+			v := &validator{}
+			manager.resolveTeamReferences(v)
+			require.Empty(t, v.problems)
+
+			// call object under test
+			result, resultErr := manager.escalationOrder()
+
+			// validation
+			var cycleErr *escalationCycleError
+			if scenario.expectedPath != "" {
+				require.True(t, errors.As(resultErr, &cycleErr), "expected cycle error. err: %s", resultErr)
+				assert.Equal(t, scenario.expectedPath, cycleErr.path)
+				assert.Equal(t, "escalation references form a cycle: Checkout -> Platform -> Checkout", cycleErr.Error())
+				return
+			}
+
+			require.NoError(t, resultErr)
+
+			var names []string
+			for _, team := range result {
+				names = append(names, team.Name)
+			}
+
+			assert.Equal(t, scenario.expected, names)
+		})
+	}
+}
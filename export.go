@@ -0,0 +1,665 @@
+package pdmanager
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/escalations"
+	"github.com/corsc/pagerduty-manager/internal/naming"
+	"github.com/corsc/pagerduty-manager/internal/ownership"
+	"github.com/corsc/pagerduty-manager/internal/schedules"
+	"github.com/corsc/pagerduty-manager/internal/services"
+	"github.com/corsc/pagerduty-manager/internal/teams"
+	"github.com/corsc/pagerduty-manager/internal/users"
+	"go.uber.org/zap"
+)
+
+// PD team role of the managers of a team
+const pdTeamRoleManager = "manager"
+
+// ParseNaming loads the naming templates of the config file, which Export uses to find the schedule, escalation policy
+// and service created for each team
+func (m *Manager) ParseNaming(_ context.Context, filename string) error {
+	m.logger.Debug("loading naming from file", zap.String("file", filename))
+
+	config, err := loadConfig(filename)
+	if err != nil {
+		return err
+	}
+
+	if len(config.problems) > 0 {
+		return &ValidationError{Problems: config.problems}
+	}
+
+	m.companyConfig.Naming = config.Naming
+
+	return nil
+}
+
+// Export reads every team in PagerDuty (with their members, schedule, escalation policy and services) and writes a config for them.
+// The roles of the members are inferred: team managers are leads, members of the team's schedules are members,
+// members that are only in the team's escalation policies are dept heads and everyone else is an observer.
+// The team's schedule and escalation policy are found by the names of the naming templates (see ParseNaming) and are
+// exported as they are, so that a sync of the exported config does not change them. Teams with a schedule or escalation
+// policy that cannot be written as config (e.g. a schedule layer with a user that is not a member of the team) are skipped
+// with a warning.
+func (m *Manager) Export(ctx context.Context, w io.Writer) error {
+	m.userManager = users.New(m.cfg, m.logger)
+	m.teamManager = teams.New(m.cfg, m.logger)
+	m.scheduleManager = schedules.New(m.cfg, m.logger)
+	m.escalationManager = escalations.New(m.cfg, m.logger)
+	m.serviceManager = services.New(m.cfg, m.logger)
+
+	exporter, err := m.newExporter(ctx)
+	if err != nil {
+		return err
+	}
+
+	out := &exportConfig{}
+
+	if len(m.companyConfig.Naming.Templates) > 0 || len(m.companyConfig.Naming.Legacy) > 0 {
+		out.Naming = &m.companyConfig.Naming
+	}
+
+	for _, thisTeam := range exporter.teams {
+		exported, err := exporter.exportTeam(ctx, thisTeam)
+		if err != nil {
+			return err
+		}
+
+		if exported != nil {
+			out.Teams = append(out.Teams, exported)
+		}
+	}
+
+	out.DefaultTimezone = defaultTimeZone(out.Teams)
+
+	for _, thisTeam := range out.Teams {
+		for _, member := range thisTeam.Members {
+			if member.Timezone == out.DefaultTimezone {
+				member.Timezone = ""
+			}
+		}
+	}
+
+	contents, err := json.MarshalIndent(out, "", "\t")
+	if err != nil {
+		return fmt.Errorf("failed to encode config with err: %w", err)
+	}
+
+	// check that the exported config is valid before writing it
+	exported := &configFile{}
+
+	_, problems, err := decodeConfig("export", contents, exported, 0)
+	if err != nil {
+		return err
+	}
+
+	exported.problems = problems
+
+	m.companyConfig = &exported.companyConfig
+
+	err = m.validate()
+	if err != nil {
+		return fmt.Errorf("exported config is invalid with err: %w", err)
+	}
+
+	_, err = w.Write(append(contents, '\n'))
+
+	return err
+}
+
+// exporter is the PagerDuty objects used to build the config
+type exporter struct {
+	m *Manager
+
+	teams []*teams.Team
+	// users keyed by ID
+	users map[string]*users.User
+	// escalation policies, schedules and services keyed by team ID
+	policies  map[string][]*escalations.EscalationPolicy
+	schedules map[string][]*schedules.Schedule
+	services  map[string][]*services.Service
+	// names of the schedules keyed by ID
+	scheduleNames map[string]string
+
+	// naming is used to find the schedule, escalation policy and service created for each team
+	naming *naming.Strategy
+}
+
+func (m *Manager) newExporter(ctx context.Context) (*exporter, error) {
+	strategy, err := naming.New(m.companyConfig.Naming.Templates, m.companyConfig.Naming.Legacy)
+	if err != nil {
+		return nil, fmt.Errorf("invalid naming config with err: %w", err)
+	}
+
+	out := &exporter{
+		m:             m,
+		users:         map[string]*users.User{},
+		policies:      map[string][]*escalations.EscalationPolicy{},
+		schedules:     map[string][]*schedules.Schedule{},
+		services:      map[string][]*services.Service{},
+		scheduleNames: map[string]string{},
+		naming:        strategy,
+	}
+
+	out.teams, err = m.teamManager.List(ctx)
+	if err != nil {
+		m.logger.Error("failed to export - list teams failed", zap.Error(err))
+		return nil, err
+	}
+
+	liveUsers, err := m.userManager.List(ctx)
+	if err != nil {
+		m.logger.Error("failed to export - list users failed", zap.Error(err))
+		return nil, err
+	}
+
+	for _, liveUser := range liveUsers {
+		out.users[liveUser.ID] = liveUser
+	}
+
+	policies, err := m.escalationManager.List(ctx)
+	if err != nil {
+		m.logger.Error("failed to export - list escalation policies failed", zap.Error(err))
+		return nil, err
+	}
+
+	for _, policy := range policies {
+		for _, teamID := range policy.GetTeamIDs() {
+			out.policies[teamID] = append(out.policies[teamID], policy)
+		}
+	}
+
+	liveSchedules, err := m.scheduleManager.List(ctx)
+	if err != nil {
+		m.logger.Error("failed to export - list schedules failed", zap.Error(err))
+		return nil, err
+	}
+
+	for _, schedule := range liveSchedules {
+		out.scheduleNames[schedule.ID] = schedule.Name
+
+		for _, teamID := range schedule.GetTeamIDs() {
+			out.schedules[teamID] = append(out.schedules[teamID], schedule)
+		}
+	}
+
+	liveServices, err := m.serviceManager.List(ctx)
+	if err != nil {
+		m.logger.Error("failed to export - list services failed", zap.Error(err))
+		return nil, err
+	}
+
+	for _, service := range liveServices {
+		// services can belong to more than one team but service names must be unique in the config
+		if len(service.Teams) > 0 {
+			out.services[service.Teams[0].ID] = append(out.services[service.Teams[0].ID], service)
+		}
+	}
+
+	return out, nil
+}
+
+// exportTeam returns the team with the inferred roles of its members, its schedule and escalation policy.
+// Returns nil when the team has no members or when its schedule or escalation policy cannot be exported.
+func (e *exporter) exportTeam(ctx context.Context, liveTeam *teams.Team) (*exportTeam, error) {
+	members, err := e.m.teamManager.GetMembers(ctx, liveTeam.ID)
+	if errors.Is(err, teams.ErrNoMembers) {
+		e.m.logger.Warn("skipping team without members", zap.String("team", liveTeam.Name))
+		return nil, nil
+	}
+
+	if err != nil {
+		e.m.logger.Error("failed to export - fetch team members failed", zap.Error(err))
+		return nil, err
+	}
+
+	teamSchedules, err := e.fetchSchedules(ctx, liveTeam.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	onCall := map[string]bool{}
+	for _, schedule := range teamSchedules {
+		for _, userID := range schedule.GetUserIDs() {
+			onCall[userID] = true
+		}
+	}
+
+	escalationOnly := map[string]bool{}
+	for _, policy := range e.policies[liveTeam.ID] {
+		for _, userID := range policy.GetUserIDs() {
+			escalationOnly[userID] = !onCall[userID]
+		}
+	}
+
+	out := &exportTeam{
+		Name:        liveTeam.Name,
+		Description: unmark(liveTeam.Description),
+	}
+
+	for _, member := range members {
+		liveUser, ok := e.users[member.ID]
+		if !ok {
+			e.m.logger.Warn("skipping unknown team member", zap.String("team", liveTeam.Name), zap.String("id", member.ID))
+			continue
+		}
+
+		role := roleObserver
+
+		switch {
+		case member.Role == pdTeamRoleManager:
+			role = roleLead
+
+		case onCall[member.ID]:
+			role = roleMember
+
+		case escalationOnly[member.ID]:
+			role = roleDeptHead
+		}
+
+		out.Members = append(out.Members, &exportMember{
+			Name:        liveUser.Name,
+			Email:       liveUser.Email,
+			Timezone:    liveUser.TimeZone,
+			Role:        role,
+			JobTitle:    liveUser.JobTitle,
+			Description: unmark(liveUser.Description),
+		})
+	}
+
+	if len(out.Members) == 0 {
+		e.m.logger.Warn("skipping team without members", zap.String("team", liveTeam.Name))
+		return nil, nil
+	}
+
+	promoteObservers(out)
+
+	err = e.exportScheduleAndEscalation(out, liveTeam, teamSchedules, members)
+	if err != nil {
+		e.m.logger.Warn("skipping team as it cannot be exported", zap.String("team", liveTeam.Name), zap.Error(err))
+		return nil, nil
+	}
+
+	teamServiceNames := e.teamNames(naming.ResourceTeamService, liveTeam.Name)
+
+	for _, service := range e.services[liveTeam.ID] {
+		if teamServiceNames[service.Name] || service.Status == "disabled" {
+			continue
+		}
+
+		out.Services = append(out.Services, &exportService{
+			Name:        service.Name,
+			Description: unmark(service.Description),
+		})
+	}
+
+	return out, nil
+}
+
+// fetchSchedules returns the team's schedules with their layers
+func (e *exporter) fetchSchedules(ctx context.Context, teamID string) ([]*schedules.Schedule, error) {
+	var out []*schedules.Schedule
+
+	for _, listed := range e.schedules[teamID] {
+		// the layers are only included when the schedule is fetched on its own
+		schedule, err := e.m.scheduleManager.Get(ctx, listed.ID)
+		if err != nil {
+			e.m.logger.Error("failed to export - fetch schedule failed", zap.Error(err))
+			return nil, err
+		}
+
+		out = append(out, schedule)
+	}
+
+	return out, nil
+}
+
+// exportScheduleAndEscalation adds the team's schedule and escalation policy (found by name) to the exported team.
+// Returns an error when either cannot be written as config.
+func (e *exporter) exportScheduleAndEscalation(out *exportTeam, liveTeam *teams.Team, teamSchedules []*schedules.Schedule,
+	members []*teams.Member) error {
+	var teamScheduleID string
+
+	memberEmails := map[string]string{}
+	for _, member := range members {
+		if liveUser, ok := e.users[member.ID]; ok {
+			memberEmails[member.ID] = liveUser.Email
+		}
+	}
+
+	scheduleNames := e.teamNames(naming.ResourceSchedule, liveTeam.Name)
+
+	for _, schedule := range teamSchedules {
+		if !scheduleNames[schedule.Name] {
+			continue
+		}
+
+		var err error
+
+		out.Schedule, err = exportTeamSchedule(schedule, memberEmails)
+		if err != nil {
+			return err
+		}
+
+		teamScheduleID = schedule.ID
+		break
+	}
+
+	if out.Schedule == nil {
+		e.m.logger.Warn("team has no schedule with the name of the naming template; a new schedule will be created",
+			zap.String("team", liveTeam.Name))
+	}
+
+	policyNames := e.teamNames(naming.ResourceEscalation, liveTeam.Name)
+
+	for _, policy := range e.policies[liveTeam.ID] {
+		if !policyNames[policy.Name] {
+			continue
+		}
+
+		var err error
+
+		out.Escalation, err = e.exportEscalation(policy, teamScheduleID)
+
+		return err
+	}
+
+	e.m.logger.Warn("team has no escalation policy with the name of the naming template; a new policy will be created",
+		zap.String("team", liveTeam.Name))
+
+	return nil
+}
+
+// exportTeamSchedule returns the layers of the schedule; the users of the layers must be members of the team
+func exportTeamSchedule(schedule *schedules.Schedule, memberEmails map[string]string) (*exportSchedule, error) {
+	layers, err := schedule.ActiveLayers()
+	if err != nil {
+		return nil, fmt.Errorf("failed to export schedule '%s' with err: %w", schedule.Name, err)
+	}
+
+	if len(layers) == 0 {
+		return nil, fmt.Errorf("schedule '%s' has no layers", schedule.Name)
+	}
+
+	out := &exportSchedule{
+		TimeZone: schedule.TimeZone,
+	}
+
+	names := map[string]bool{}
+
+	for _, layer := range layers {
+		if names[layer.Name] {
+			return nil, fmt.Errorf("schedule '%s' has more than one layer named '%s'", schedule.Name, layer.Name)
+		}
+
+		names[layer.Name] = true
+
+		if layer.Rotation.Length < time.Hour {
+			return nil, fmt.Errorf("rotation of schedule layer '%s' is shorter than 1h", layer.Name)
+		}
+
+		exported := &exportLayer{
+			Name:           layer.Name,
+			RotationLength: formatRotationLength(layer.Rotation.Length),
+			HandoffTime:    formatTimeOfDay(layer.Rotation.HandoffTime),
+			StartDate:      layer.Rotation.StartDate.Format("2006-01-02"),
+		}
+
+		if layer.Rotation.Length%(7*24*time.Hour) == 0 {
+			exported.HandoffDay = strings.ToLower(layer.Rotation.HandoffDay.String())
+		}
+
+		for _, userID := range layer.UserIDs {
+			email, ok := memberEmails[userID]
+			if !ok {
+				return nil, fmt.Errorf("user '%s' of schedule layer '%s' is not a member of the team", userID, layer.Name)
+			}
+
+			exported.Members = append(exported.Members, email)
+		}
+
+		for _, restriction := range layer.Restrictions {
+			if restriction.StartTime%time.Minute != 0 {
+				return nil, fmt.Errorf("restriction of schedule layer '%s' does not start on a whole minute", layer.Name)
+			}
+
+			exportedRestriction := &exportRestriction{
+				Type:      restrictionDaily,
+				StartTime: formatTimeOfDay(restriction.StartTime),
+				Duration:  formatDuration(restriction.Duration),
+			}
+
+			if restriction.Weekly {
+				exportedRestriction.Type = restrictionWeekly
+				exportedRestriction.StartDay = strings.ToLower(restriction.StartDay.String())
+			}
+
+			exported.Restrictions = append(exported.Restrictions, exportedRestriction)
+		}
+
+		out.Layers = append(out.Layers, exported)
+	}
+
+	return out, nil
+}
+
+// exportEscalation returns the tiers of the escalation policy; the team's own schedule is the on-call role
+func (e *exporter) exportEscalation(policy *escalations.EscalationPolicy, teamScheduleID string) (*exportEscalation, error) {
+	structure := policy.GetStructure()
+
+	if len(structure.Tiers) == 0 {
+		return nil, fmt.Errorf("escalation policy '%s' has no rules", policy.Name)
+	}
+
+	out := &exportEscalation{
+		NumLoops:             &structure.NumLoops,
+		HandoffNotifications: structure.HandoffNotifications,
+	}
+
+	for index, tier := range structure.Tiers {
+		// a delay of 0 is the default delay in the config
+		if tier.DelayMinutes <= 0 {
+			return nil, fmt.Errorf("rule %d of escalation policy '%s' has no delay", index+1, policy.Name)
+		}
+
+		exported := &exportTier{
+			DelayMinutes: tier.DelayMinutes,
+		}
+
+		for _, scheduleID := range tier.ScheduleIDs {
+			if teamScheduleID != "" && scheduleID == teamScheduleID {
+				exported.Roles = append(exported.Roles, targetOnCall)
+				continue
+			}
+
+			name, ok := e.scheduleNames[scheduleID]
+			if !ok {
+				return nil, fmt.Errorf("unknown schedule '%s' in escalation policy '%s'", scheduleID, policy.Name)
+			}
+
+			exported.Schedules = append(exported.Schedules, name)
+		}
+
+		for _, userID := range tier.UserIDs {
+			liveUser, ok := e.users[userID]
+			if !ok {
+				return nil, fmt.Errorf("unknown user '%s' in escalation policy '%s'", userID, policy.Name)
+			}
+
+			exported.Users = append(exported.Users, liveUser.Email)
+		}
+
+		if len(exported.Roles)+len(exported.Schedules)+len(exported.Users) == 0 {
+			return nil, fmt.Errorf("rule %d of escalation policy '%s' has no targets", index+1, policy.Name)
+		}
+
+		out.Tiers = append(out.Tiers, exported)
+	}
+
+	return out, nil
+}
+
+// teamNames returns the name and legacy names of the object created for the team
+func (e *exporter) teamNames(resource, teamName string) map[string]bool {
+	out := map[string]bool{
+		e.naming.Name(resource, teamName): true,
+	}
+
+	for _, legacyName := range e.naming.LegacyNames(resource, teamName) {
+		out[legacyName] = true
+	}
+
+	return out
+}
+
+// formatRotationLength returns the length as it is written in the config (e.g. weekly or 12h)
+func formatRotationLength(length time.Duration) string {
+	switch length {
+	case 24 * time.Hour:
+		return "daily"
+
+	case 7 * 24 * time.Hour:
+		return "weekly"
+	}
+
+	return formatDuration(length)
+}
+
+// formatDuration returns the duration in hours when it is a whole number of hours (e.g. 336h instead of 336h0m0s)
+func formatDuration(duration time.Duration) string {
+	if duration%time.Hour == 0 {
+		return fmt.Sprintf("%dh", duration/time.Hour)
+	}
+
+	return duration.String()
+}
+
+// formatTimeOfDay returns the time of day (since midnight) as HH:MM
+func formatTimeOfDay(timeOfDay time.Duration) string {
+	return fmt.Sprintf("%02d:%02d", timeOfDay/time.Hour, timeOfDay%time.Hour/time.Minute)
+}
+
+// promoteObservers makes the observers members when there is no one else to be on-call
+func promoteObservers(team *exportTeam) {
+	for _, member := range team.Members {
+		if member.Role == roleMember || member.Role == roleLead {
+			return
+		}
+	}
+
+	for _, member := range team.Members {
+		if member.Role == roleObserver {
+			member.Role = roleMember
+		}
+	}
+}
+
+// defaultTimeZone returns the most common time zone of the members
+func defaultTimeZone(exportedTeams []*exportTeam) string {
+	counts := map[string]int{}
+
+	for _, thisTeam := range exportedTeams {
+		for _, member := range thisTeam.Members {
+			if member.Timezone != "" {
+				counts[member.Timezone]++
+			}
+		}
+	}
+
+	var timeZones []string
+	for timeZone := range counts {
+		timeZones = append(timeZones, timeZone)
+	}
+
+	if len(timeZones) == 0 {
+		return "UTC"
+	}
+
+	sort.Slice(timeZones, func(i, j int) bool {
+		if counts[timeZones[i]] != counts[timeZones[j]] {
+			return counts[timeZones[i]] > counts[timeZones[j]]
+		}
+
+		return timeZones[i] < timeZones[j]
+	})
+
+	return timeZones[0]
+}
+
+// unmark removes the ownership markers from the description
+func unmark(description string) string {
+	return strings.TrimSpace(strings.ReplaceAll(ownership.UnmarkAdmin(description), ownership.Marker, ""))
+}
+
+// exportConfig is the exported config; only the fields that are exported are included
+type exportConfig struct {
+	Teams           []*exportTeam `json:"teams"`
+	DefaultTimezone string        `json:"default_timezone"`
+	Naming          *Naming       `json:"naming,omitempty"`
+}
+
+type exportTeam struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description,omitempty"`
+	Members     []*exportMember   `json:"members"`
+	Schedule    *exportSchedule   `json:"schedule,omitempty"`
+	Escalation  *exportEscalation `json:"escalation,omitempty"`
+	Services    []*exportService  `json:"services,omitempty"`
+}
+
+type exportMember struct {
+	Name        string `json:"name"`
+	Email       string `json:"email"`
+	Timezone    string `json:"timezone,omitempty"`
+	Role        string `json:"role"`
+	JobTitle    string `json:"job_title,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+type exportService struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+type exportSchedule struct {
+	TimeZone string         `json:"time_zone,omitempty"`
+	Layers   []*exportLayer `json:"layers"`
+}
+
+type exportLayer struct {
+	Name           string               `json:"name"`
+	Members        []string             `json:"members"`
+	RotationLength string               `json:"rotation_length"`
+	HandoffDay     string               `json:"handoff_day,omitempty"`
+	HandoffTime    string               `json:"handoff_time"`
+	StartDate      string               `json:"start_date"`
+	Restrictions   []*exportRestriction `json:"restrictions,omitempty"`
+}
+
+type exportRestriction struct {
+	Type      string `json:"type"`
+	StartDay  string `json:"start_day,omitempty"`
+	StartTime string `json:"start_time"`
+	Duration  string `json:"duration"`
+}
+
+type exportEscalation struct {
+	Tiers                []*exportTier `json:"tiers"`
+	NumLoops             *int          `json:"num_loops"`
+	HandoffNotifications string        `json:"on_call_handoff_notifications,omitempty"`
+}
+
+type exportTier struct {
+	DelayMinutes int      `json:"delay_minutes"`
+	Roles        []string `json:"roles,omitempty"`
+	Schedules    []string `json:"schedules,omitempty"`
+	Users        []string `json:"users,omitempty"`
+}
@@ -0,0 +1,193 @@
+package pdmanager
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_Export(t *testing.T) {
+	scenarios := []struct {
+		desc     string
+		naming   Naming
+		names    map[string]string
+		expected string
+	}{
+		{
+			desc: "default naming",
+			names: map[string]string{
+				"schedule":        "Booking Schedule",
+				"escalation":      "Booking Escalation",
+				"service":         "Booking",
+				"legacy_schedule": "Legacy Schedule",
+			},
+			expected: exportedBooking,
+		},
+		{
+			desc: "custom naming",
+			naming: Naming{
+				Templates: map[string]string{
+					"schedule":          "{team} On-call",
+					"escalation_policy": "{team} Policy",
+				},
+				Legacy: map[string][]string{
+					"team_service": {"{team} Alerts"},
+				},
+			},
+			names: map[string]string{
+				"schedule":        "Booking On-call",
+				"escalation":      "Booking Policy",
+				"service":         "Booking Alerts",
+				"legacy_schedule": "Legacy On-call",
+			},
+			expected: strings.Replace(exportedBooking, `"default_timezone": "Europe/London"`, `"default_timezone": "Europe/London",
+				"naming": {
+					"templates": {"escalation_policy": "{team} Policy", "schedule": "{team} On-call"},
+					"legacy": {"team_service": ["{team} Alerts"]}
+				}`, 1),
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			responses := map[string]string{
+				"/teams": `{"teams": [
+					{"id": "T1", "name": "Booking", "description": "Books things [managed by pagerduty-manager]"},
+					{"id": "T2", "name": "Empty"},
+					{"id": "T3", "name": "Legacy"}
+				]}`,
+				"/teams/T1/members": `{"members": [
+					{"user": {"id": "PAUL"}, "role": "manager"},
+					{"user": {"id": "GEORGE"}, "role": "responder"},
+					{"user": {"id": "JOHN"}, "role": "responder"},
+					{"user": {"id": "PETE"}, "role": "responder"}
+				]}`,
+				"/teams/T2/members": `{"members": []}`,
+				"/teams/T3/members": `{"members": [{"user": {"id": "PETE"}, "role": "manager"}]}`,
+				"/users": `{"users": [
+					{"id": "PAUL", "name": "Paul", "email": "paul@beatles.com", "time_zone": "Europe/London"},
+					{"id": "GEORGE", "name": "George", "email": "george@beatles.com", "time_zone": "Europe/London"},
+					{"id": "JOHN", "name": "John", "email": "john@beatles.com", "time_zone": "America/New_York", "job_title": "Boss"},
+					{"id": "PETE", "name": "Pete", "email": "pete@beatles.com", "time_zone": "Europe/London"},
+					{"id": "RINGO", "name": "Ringo", "email": "ringo@beatles.com", "time_zone": "Europe/London"}
+				]}`,
+				"/escalation_policies": `{"escalation_policies": [
+					{"id": "E1", "name": "` + scenario.names["escalation"] + `", "teams": [{"id": "T1"}], "num_loops": 2,
+						"on_call_handoff_notifications": "has_services", "escalation_rules": [
+						{"escalation_delay_in_minutes": 15, "targets": [{"id": "S1", "type": "schedule_reference"}]},
+						{"escalation_delay_in_minutes": 10, "targets": [
+							{"id": "PAUL", "type": "user_reference"},
+							{"id": "JOHN", "type": "user_reference"},
+							{"id": "SRE", "type": "schedule_reference"}
+						]}
+					]}
+				]}`,
+				"/schedules": `{"schedules": [
+					{"id": "S1", "name": "` + scenario.names["schedule"] + `", "teams": [{"id": "T1"}]},
+					{"id": "S3", "name": "` + scenario.names["legacy_schedule"] + `", "teams": [{"id": "T3"}]},
+					{"id": "SRE", "name": "SRE Schedule"}
+				]}`,
+				"/schedules/S1": `{"schedule": {"id": "S1", "name": "` + scenario.names["schedule"] + `", "time_zone": "Europe/London",
+					"schedule_layers": [
+						{"name": "Old", "end": "2021-07-05T11:00:00Z", "rotation_virtual_start": "2021-06-07T11:00:00Z",
+							"rotation_turn_length_seconds": 604800, "users": [{"id": "PETE"}]},
+						{"name": "Business hours", "rotation_virtual_start": "2021-07-05T11:00:00Z", "rotation_turn_length_seconds": 604800,
+							"users": [{"id": "GEORGE"}, {"id": "PAUL"}],
+							"restrictions": [{"type": "weekly_restriction", "start_time_of_day": "09:00:00", "start_day_of_week": 1, "duration_seconds": 28800}]}
+					]}}`,
+				"/schedules/S3": `{"schedule": {"id": "S3", "name": "` + scenario.names["legacy_schedule"] + `", "time_zone": "Europe/London", "schedule_layers": [
+					{"name": "Layer 1", "rotation_virtual_start": "2021-07-05T11:00:00Z", "rotation_turn_length_seconds": 86400,
+						"users": [{"id": "RINGO"}]}
+				]}}`,
+				"/services": `{"services": [
+					{"id": "B1", "name": "Booking API", "status": "active", "description": "API [managed by pagerduty-manager]", "teams": [{"id": "T1"}]},
+					{"id": "B2", "name": "` + scenario.names["service"] + `", "status": "active", "teams": [{"id": "T1"}]},
+					{"id": "B3", "name": "Old Booking API", "status": "disabled", "teams": [{"id": "T1"}]}
+				]}`,
+			}
+
+			testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, http.MethodGet, req.Method, "export must not make changes")
+
+				response, ok := responses[req.URL.Path]
+				require.True(t, ok, "unexpected request %s", req.URL.Path)
+
+				_, _ = resp.Write([]byte(response))
+			}))
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			buffer := &bytes.Buffer{}
+
+			// call object under test
+			manager := New(cfg, logger)
+			manager.companyConfig.Naming = scenario.naming
+
+			resultErr := manager.Export(ctx, buffer)
+
+			// validation
+			require.NoError(t, resultErr)
+			assert.JSONEq(t, scenario.expected, buffer.String())
+		})
+	}
+}
+
+// exportedBooking is the config exported for the Booking team; the Legacy team is skipped as a user of its schedule is not
+// a member of the team
+const exportedBooking = `{
+	"teams": [
+		{
+			"name": "Booking",
+			"description": "Books things",
+			"members": [
+				{"name": "Paul", "email": "paul@beatles.com", "role": "lead"},
+				{"name": "George", "email": "george@beatles.com", "role": "member"},
+				{"name": "John", "email": "john@beatles.com", "timezone": "America/New_York", "role": "dept-head", "job_title": "Boss"},
+				{"name": "Pete", "email": "pete@beatles.com", "role": "observer"}
+			],
+			"schedule": {
+				"time_zone": "Europe/London",
+				"layers": [
+					{
+						"name": "Business hours",
+						"members": ["george@beatles.com", "paul@beatles.com"],
+						"rotation_length": "weekly",
+						"handoff_day": "monday",
+						"handoff_time": "12:00",
+						"start_date": "2021-07-05",
+						"restrictions": [{"type": "weekly", "start_day": "monday", "start_time": "09:00", "duration": "8h"}]
+					}
+				]
+			},
+			"escalation": {
+				"tiers": [
+					{"delay_minutes": 15, "roles": ["on-call"]},
+					{"delay_minutes": 10, "schedules": ["SRE Schedule"], "users": ["paul@beatles.com", "john@beatles.com"]}
+				],
+				"num_loops": 2,
+				"on_call_handoff_notifications": "has_services"
+			},
+			"services": [{"name": "Booking API", "description": "API"}]
+		}
+	],
+	"default_timezone": "Europe/London"
+}`
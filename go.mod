@@ -6,4 +6,5 @@ require (
 	github.com/corsc/go-commons v1.1.1
 	github.com/stretchr/testify v1.7.0
 	go.uber.org/zap v1.18.1
+	gopkg.in/yaml.v3 v3.0.1
 )
@@ -0,0 +1,284 @@
+package pdmanager
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/corsc/pagerduty-manager/internal/diff"
+	"github.com/corsc/pagerduty-manager/internal/services"
+	"go.uber.org/zap"
+)
+
+const resourceIntegration = "integration"
+
+// replacedSuffix is appended to the name of integrations that have been replaced, as they cannot be deleted with the API
+const replacedSuffix = " (replaced)"
+
+// integration types
+const (
+	integrationEventsAPIV2 = "events_api_v2"
+	integrationEmail       = "email"
+	integrationPrometheus  = "prometheus"
+	integrationDatadog     = "datadog"
+)
+
+type integrationType struct {
+	pdType string
+	// vendor is the name of the PagerDuty vendor (empty for generic integrations)
+	vendor string
+}
+
+// map of our integration types to PD integration types and vendors
+var integrationTypes = map[string]*integrationType{
+	integrationEventsAPIV2: {pdType: services.IntegrationEventsAPIV2},
+	integrationEmail:       {pdType: services.IntegrationEmail},
+	integrationPrometheus:  {pdType: services.IntegrationEventsAPIV2, vendor: "Prometheus"},
+	integrationDatadog:     {pdType: services.IntegrationEventsAPIV2, vendor: "Datadog"},
+}
+
+// validateIntegrations records the problems with the integrations of the service; path is the path of the service
+func validateIntegrations(v *validator, path string, service *Service) {
+	names := map[string]bool{}
+
+	for index, integration := range service.Integrations {
+		integrationPath := fmt.Sprintf("%s.integrations[%d]", path, index)
+
+		if integration.Name == "" {
+			v.errorf(integrationPath+".name", "missing name")
+		} else if names[integration.Name] {
+			v.errorf(integrationPath+".name", "duplicate integration '%s'", integration.Name)
+		}
+
+		names[integration.Name] = true
+
+		if integrationTypes[integration.Type] == nil {
+			v.errorf(integrationPath+".type", "invalid type '%s' (must be events_api_v2, email, prometheus or datadog)", integration.Type)
+		}
+
+		if integration.Type == integrationEmail && integration.Email == "" {
+			v.errorf(integrationPath+".email", "missing email")
+		}
+	}
+}
+
+// syncIntegrations creates the integrations of the service that do not exist (matched by name) and records their secrets.
+// Integrations with a different type or vendor to the config are replaced. Integrations that are not in the config are left alone.
+func (m *Manager) syncIntegrations(ctx context.Context, service *Service, serviceID string) error {
+	if len(service.Integrations) == 0 {
+		return nil
+	}
+
+	var liveIntegrations []*services.Integration
+
+	if serviceID != "" {
+		var err error
+
+		liveIntegrations, err = m.serviceManager.GetIntegrations(ctx, serviceID)
+		if err != nil {
+			m.logger.Error("failed to sync service - fetch integrations failed", zap.Error(err))
+			return err
+		}
+	}
+
+	for _, integration := range service.Integrations {
+		err := m.resolveVendor(ctx, integration)
+		if err != nil {
+			return err
+		}
+
+		liveIntegration := services.FindIntegration(liveIntegrations, integration.Name)
+		if liveIntegration != nil {
+			fields := integrationDiff(liveIntegration, integration)
+			if len(fields) == 0 {
+				m.secrets.add(service, integration, liveIntegration)
+				continue
+			}
+
+			err = m.replaceIntegration(ctx, service, serviceID, integration, liveIntegration, fields)
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceIntegration,
+			Name:     service.Name + "/" + integration.Name,
+			Details: []string{
+				"type: " + integration.Type,
+			},
+		}
+
+		err = m.apply(change, func() error {
+			return m.addIntegration(ctx, service, serviceID, integration)
+		})
+		if err != nil {
+			m.logger.Error("failed to sync service - add integration failed", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// replaceIntegration renames the live integration (PagerDuty does not allow it to be deleted or its type to be changed)
+// and creates the integration of the config in its place. The renamed integration keeps working until it is removed by hand.
+func (m *Manager) replaceIntegration(ctx context.Context, service *Service, serviceID string, integration *ServiceIntegration,
+	liveIntegration *services.Integration, fields []*FieldDiff) error {
+	change := &Change{
+		Action:   ActionUpdate,
+		Resource: resourceIntegration,
+		Name:     service.Name + "/" + integration.Name,
+		Details: []string{
+			"replaced by a new integration; the old integration is renamed to '" + integration.Name + replacedSuffix + "'",
+		},
+		Fields: fields,
+	}
+
+	err := m.apply(change, func() error {
+		renameErr := m.serviceManager.RenameIntegration(ctx, serviceID, liveIntegration, integration.Name+replacedSuffix)
+		if renameErr != nil {
+			return renameErr
+		}
+
+		return m.addIntegration(ctx, service, serviceID, integration)
+	})
+	if err != nil {
+		m.logger.Error("failed to sync service - replace integration failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+func (m *Manager) addIntegration(ctx context.Context, service *Service, serviceID string, integration *ServiceIntegration) error {
+	newIntegration, err := m.serviceManager.AddIntegration(ctx, serviceID, integration)
+	if err != nil {
+		return err
+	}
+
+	m.secrets.add(service, integration, newIntegration)
+
+	return nil
+}
+
+// integrationDiff returns the differences in type and vendor between the live integration and the integration of the config
+func integrationDiff(liveIntegration *services.Integration, integration *ServiceIntegration) []*FieldDiff {
+	builder := &diff.Builder{}
+	builder.String("type", liveIntegration.Type, integration.GetType())
+
+	if liveIntegration.GetVendorID() != integration.GetVendorID() {
+		builder.String("vendor", vendorName(liveIntegration.GetVendorName(), liveIntegration.GetVendorID()),
+			vendorName(integrationTypes[integration.Type].vendor, integration.GetVendorID()))
+	}
+
+	return builder.Fields()
+}
+
+// vendorName returns the name of the vendor or the ID when the name is unknown
+func vendorName(name, id string) string {
+	if name != "" {
+		return name
+	}
+
+	return id
+}
+
+// resolveVendor sets the ID of the vendor of the integration (if any)
+func (m *Manager) resolveVendor(ctx context.Context, integration *ServiceIntegration) error {
+	vendor := integrationTypes[integration.Type].vendor
+	if vendor == "" {
+		return nil
+	}
+
+	vendorID, ok := m.vendorIDs[vendor]
+	if !ok {
+		var err error
+
+		vendorID, err = m.serviceManager.GetVendorID(ctx, vendor)
+		if err != nil {
+			m.logger.Error("failed to sync service - fetch vendor failed", zap.Error(err))
+			return err
+		}
+
+		m.vendorIDs[vendor] = vendorID
+	}
+
+	integration.vendorID = vendorID
+
+	return nil
+}
+
+// Secrets returns the integration keys (and email addresses) of the integrations in the config.
+// During a dry-run the secrets of integrations that do not exist yet are not included.
+func (m *Manager) Secrets() *Secrets {
+	return m.secrets
+}
+
+// ServiceIntegration sends alerts to the service; type is events_api_v2, email, prometheus or datadog
+type ServiceIntegration struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// Email is the address of email integrations (e.g. alerts@example.pagerduty.com)
+	Email string `json:"email"`
+
+	vendorID string
+}
+
+func (s *ServiceIntegration) GetName() string {
+	return s.Name
+}
+
+func (s *ServiceIntegration) GetType() string {
+	return integrationTypes[s.Type].pdType
+}
+
+func (s *ServiceIntegration) GetVendorID() string {
+	return s.vendorID
+}
+
+func (s *ServiceIntegration) GetEmail() string {
+	return s.Email
+}
+
+// Secrets are the integration keys of the integrations in the config
+type Secrets struct {
+	Integrations []*IntegrationSecret `json:"integrations"`
+}
+
+// IntegrationSecret is the integration key (or email address) alerts are sent to
+type IntegrationSecret struct {
+	Service          string `json:"service"`
+	Integration      string `json:"integration"`
+	Type             string `json:"type"`
+	IntegrationKey   string `json:"integration_key,omitempty"`
+	IntegrationEmail string `json:"integration_email,omitempty"`
+}
+
+func (s *Secrets) add(service *Service, integration *ServiceIntegration, liveIntegration *services.Integration) {
+	s.Integrations = append(s.Integrations, &IntegrationSecret{
+		Service:          service.Name,
+		Integration:      integration.Name,
+		Type:             integration.Type,
+		IntegrationKey:   liveIntegration.IntegrationKey,
+		IntegrationEmail: liveIntegration.IntegrationEmail,
+	})
+}
+
+// Write writes the secrets as JSON
+func (s *Secrets) Write(w io.Writer) error {
+	out := &Secrets{
+		Integrations: []*IntegrationSecret{},
+	}
+
+	out.Integrations = append(out.Integrations, s.Integrations...)
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(out)
+}
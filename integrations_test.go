@@ -0,0 +1,205 @@
+package pdmanager
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/services"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_syncIntegrations(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	// mocks
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method, "dry-run must not make changes")
+
+		if req.URL.Path == "/vendors" {
+			_, _ = resp.Write([]byte(`{"vendors": [{"id": "PROM", "name": "Prometheus"}]}`))
+			return
+		}
+
+		_, _ = resp.Write([]byte(`{"service": {"id": "BOOK", "integrations": [
+			{"id": "INT1", "type": "events_api_v2_inbound_integration", "name": "Events", "integration_key": "KEY1"},
+			{"id": "INT2", "type": "generic_email_inbound_integration", "name": "Hand made"}
+		]}}`))
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		baseURL: testServer.URL,
+		dryRun:  true,
+	}
+
+	service := &Service{
+		Name: "Booking",
+		Integrations: []*ServiceIntegration{
+			{Name: "Events", Type: integrationEventsAPIV2},
+			{Name: "Alertmanager", Type: integrationPrometheus},
+		},
+	}
+
+	manager := New(cfg, logger)
+	manager.serviceManager = services.New(cfg, logger)
+
+	// call object under test
+	resultErr := manager.syncIntegrations(ctx, service, "BOOK")
+	require.NoError(t, resultErr)
+
+	// validation
+	require.Len(t, manager.Plan().Changes, 1)
+	assert.Equal(t, `create integration "Booking/Alertmanager"`, manager.Plan().Changes[0].String()[2:])
+	assert.Equal(t, "PROM", service.Integrations[1].GetVendorID())
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, manager.Secrets().Write(buffer))
+	assert.JSONEq(t, `{"integrations": [
+		{"service": "Booking", "integration": "Events", "type": "events_api_v2", "integration_key": "KEY1"}
+	]}`, buffer.String())
+}
+
+func TestManager_syncIntegrations_replace(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	// mocks
+	var mutations []string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		switch {
+		case req.Method == http.MethodPost:
+			mutations = append(mutations, req.Method+" "+req.URL.Path)
+
+			resp.WriteHeader(http.StatusCreated)
+			_, _ = resp.Write([]byte(`{"integration": {"id": "INT4", "type": "events_api_v2_inbound_integration", "name": "Alertmanager", "integration_key": "KEY4"}}`))
+
+		case req.Method == http.MethodPut:
+			mutations = append(mutations, req.Method+" "+req.URL.Path)
+
+			payload, _ := ioutil.ReadAll(req.Body)
+			assert.Contains(t, string(payload), `"name":"Alertmanager (replaced)"`)
+
+		case req.URL.Path == "/vendors":
+			_, _ = resp.Write([]byte(`{"vendors": [{"id": "PROM", "name": "Prometheus"}]}`))
+
+		default:
+			_, _ = resp.Write([]byte(`{"service": {"id": "BOOK", "integrations": [
+				{"id": "INT3", "type": "events_api_v2_inbound_integration", "name": "Alertmanager", "integration_key": "KEY3",
+					"vendor": {"id": "DD", "type": "vendor_reference", "summary": "Datadog"}}
+			]}}`))
+		}
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		baseURL: testServer.URL,
+	}
+
+	service := &Service{
+		Name: "Booking",
+		Integrations: []*ServiceIntegration{
+			{Name: "Alertmanager", Type: integrationPrometheus},
+		},
+	}
+
+	manager := New(cfg, logger)
+	manager.serviceManager = services.New(cfg, logger)
+
+	// call object under test
+	resultErr := manager.syncIntegrations(ctx, service, "BOOK")
+	require.NoError(t, resultErr)
+
+	// validation
+	require.Len(t, manager.Plan().Changes, 1)
+
+	change := manager.Plan().Changes[0]
+	assert.Equal(t, `update integration "Booking/Alertmanager"`, change.String()[2:])
+	assert.Equal(t, []*FieldDiff{{Path: "vendor", Live: "Datadog", Desired: "Prometheus"}}, change.Fields)
+
+	assert.Equal(t, []string{
+		"PUT /services/BOOK/integrations/INT3",
+		"POST /services/BOOK/integrations",
+	}, mutations, "the old integration is renamed before the new one is created")
+
+	buffer := &bytes.Buffer{}
+	require.NoError(t, manager.Secrets().Write(buffer))
+	assert.JSONEq(t, `{"integrations": [
+		{"service": "Booking", "integration": "Alertmanager", "type": "prometheus", "integration_key": "KEY4"}
+	]}`, buffer.String())
+}
+
+func TestValidateIntegrations(t *testing.T) {
+	scenarios := []struct {
+		desc           string
+		in             []*ServiceIntegration
+		expectedErrors []string
+	}{
+		{
+			desc: "happy path",
+			in: []*ServiceIntegration{
+				{Name: "Events", Type: integrationEventsAPIV2},
+				{Name: "Email", Type: integrationEmail, Email: "booking@example.pagerduty.com"},
+				{Name: "Datadog", Type: integrationDatadog},
+			},
+		},
+		{
+			desc:           "sad path - unknown type",
+			in:             []*ServiceIntegration{{Name: "Nagios", Type: "nagios"}},
+			expectedErrors: []string{"services[0].integrations[0].type"},
+		},
+		{
+			desc:           "sad path - email without address",
+			in:             []*ServiceIntegration{{Name: "Email", Type: integrationEmail}},
+			expectedErrors: []string{"services[0].integrations[0].email"},
+		},
+		{
+			desc: "sad path - duplicate name",
+			in: []*ServiceIntegration{
+				{Name: "Events", Type: integrationEventsAPIV2},
+				{Name: "Events", Type: integrationPrometheus},
+			},
+			expectedErrors: []string{"services[0].integrations[1].name"},
+		},
+		{
+			desc: "sad path - every problem is reported",
+			in: []*ServiceIntegration{
+				{Type: "nagios"},
+				{Name: "Email", Type: integrationEmail},
+			},
+			expectedErrors: []string{
+				"services[0].integrations[0].name",
+				"services[0].integrations[0].type",
+				"services[0].integrations[1].email",
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			v := &validator{}
+
+			// call object under test
+			validateIntegrations(v, "services[0]", &Service{Name: "Booking", Integrations: scenario.in})
+
+			// validation
+			assert.Equal(t, scenario.expectedErrors, problemPaths(v.problems))
+		})
+	}
+}
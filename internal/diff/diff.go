@@ -0,0 +1,83 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Unknown is used in place of IDs that will not be known until the resource has been created
+const Unknown = "(known after apply)"
+
+// Field is a single difference between the live and desired value of a field
+type Field struct {
+	Path    string
+	Live    string
+	Desired string
+}
+
+func (f *Field) String() string {
+	return fmt.Sprintf("%s: %q => %q", f.Path, f.Live, f.Desired)
+}
+
+// Builder compares fields of the live and desired objects and accumulates any differences
+type Builder struct {
+	fields []*Field
+}
+
+// String compares a single string field
+func (b *Builder) String(path, live, desired string) {
+	if live == desired {
+		return
+	}
+
+	b.fields = append(b.fields, &Field{
+		Path:    path,
+		Live:    live,
+		Desired: desired,
+	})
+}
+
+// Int compares a single numeric field
+func (b *Builder) Int(path string, live, desired int) {
+	b.String(path, strconv.Itoa(live), strconv.Itoa(desired))
+}
+
+// List compares a list where the order is significant (e.g. the members of a rotation)
+func (b *Builder) List(path string, live, desired []string) {
+	b.String(path, strings.Join(live, ", "), strings.Join(desired, ", "))
+}
+
+// Set compares a list where the order is not significant (e.g. the teams of a schedule)
+func (b *Builder) Set(path string, live, desired []string) {
+	b.List(path, sorted(live), sorted(desired))
+}
+
+// Fields returns the differences found so far
+func (b *Builder) Fields() []*Field {
+	return b.fields
+}
+
+// IDs returns the supplied IDs, replacing any that are not yet known with Unknown
+func IDs(ids ...string) []string {
+	out := make([]string, len(ids))
+
+	for index, id := range ids {
+		out[index] = id
+		if id == "" {
+			out[index] = Unknown
+		}
+	}
+
+	return out
+}
+
+func sorted(values []string) []string {
+	out := make([]string, len(values))
+	copy(out, values)
+
+	sort.Strings(out)
+
+	return out
+}
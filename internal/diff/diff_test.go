@@ -0,0 +1,74 @@
+package diff
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuilder(t *testing.T) {
+	scenarios := []struct {
+		desc     string
+		in       func(builder *Builder)
+		expected []*Field
+	}{
+		{
+			desc: "no differences",
+			in: func(builder *Builder) {
+				builder.String("name", "A", "A")
+				builder.Int("num_loops", 9, 9)
+				builder.List("users", []string{"A", "B"}, []string{"A", "B"})
+				builder.Set("teams", []string{"B", "A"}, []string{"A", "B"})
+			},
+			expected: nil,
+		},
+		{
+			desc: "string and int differences",
+			in: func(builder *Builder) {
+				builder.String("name", "A", "B")
+				builder.Int("num_loops", 1, 9)
+			},
+			expected: []*Field{
+				{Path: "name", Live: "A", Desired: "B"},
+				{Path: "num_loops", Live: "1", Desired: "9"},
+			},
+		},
+		{
+			desc: "list order is significant",
+			in: func(builder *Builder) {
+				builder.List("users", []string{"B", "A"}, []string{"A", "B"})
+			},
+			expected: []*Field{
+				{Path: "users", Live: "B, A", Desired: "A, B"},
+			},
+		},
+		{
+			desc: "set membership is significant",
+			in: func(builder *Builder) {
+				builder.Set("teams", []string{"C", "A"}, []string{"A", "B"})
+			},
+			expected: []*Field{
+				{Path: "teams", Live: "A, C", Desired: "A, B"},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			builder := &Builder{}
+
+			// call object under test
+			scenario.in(builder)
+
+			// validation
+			assert.Equal(t, scenario.expected, builder.Fields())
+		})
+	}
+}
+
+func TestIDs(t *testing.T) {
+	result := IDs("A", "", "B")
+
+	assert.Equal(t, []string{"A", Unknown, "B"}, result)
+}
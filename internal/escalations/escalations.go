@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
 	"github.com/corsc/pagerduty-manager/internal/pd"
 
 	"go.uber.org/zap"
@@ -16,9 +18,15 @@ const (
 	listURI   = "/escalation_policies"
 	addURI    = "/escalation_policies"
 	updateURI = "/escalation_policies/%s"
+
+	targetSchedule = "schedule_reference"
+	targetUser     = "user_reference"
 )
 
-var ErrNoSuchPolicy = errors.New("no such escalation policy")
+var (
+	ErrNoSuchPolicy    = errors.New("no such escalation policy")
+	ErrAmbiguousPolicy = errors.New("more than one escalation policy matches")
+)
 
 func New(cfg Config, logger *zap.Logger) *Manager {
 	return &Manager{
@@ -52,30 +60,60 @@ func (u *Manager) Get(ctx context.Context, policyID string) (*EscalationPolicy,
 	return escalations.Policy, nil
 }
 
+// GetByName returns the escalation policy with exactly the supplied name
 func (u *Manager) GetByName(ctx context.Context, name string) (*EscalationPolicy, error) {
 	params := url.Values{}
 	params.Set("query", name)
-	params.Set("total", "false")
-	params.Set("limit", "1")
 
-	escalations := &getEscalationPolicyResponse{}
+	var matches []*EscalationPolicy
 
-	err := u.api.Get(ctx, listURI, params, escalations)
+	err := u.api.List(ctx, listURI, params,
+		func() pd.Page {
+			return &getEscalationPolicyResponse{}
+		},
+		func(page pd.Page) {
+			for _, policy := range page.(*getEscalationPolicyResponse).Policies {
+				if pd.NamesMatch(name, policy.Name, u.cfg.CaseInsensitiveNames()) {
+					matches = append(matches, policy)
+				}
+			}
+		})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get escalations '%s' with err: %s", name, err)
 	}
 
-	if len(escalations.Policies) == 0 {
+	switch len(matches) {
+	case 0:
 		return nil, ErrNoSuchPolicy
+
+	case 1:
+		return matches[0], nil
+
+	default:
+		return nil, fmt.Errorf("%w: %d escalations named '%s'", ErrAmbiguousPolicy, len(matches), name)
+	}
+}
+
+// List returns every escalation policy in the account
+func (u *Manager) List(ctx context.Context) ([]*EscalationPolicy, error) {
+	var out []*EscalationPolicy
+
+	err := u.api.List(ctx, listURI, nil,
+		func() pd.Page {
+			return &getEscalationPolicyResponse{}
+		},
+		func(page pd.Page) {
+			out = append(out, page.(*getEscalationPolicyResponse).Policies...)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list escalation policies with err: %s", err)
 	}
 
-	return escalations.Policies[0], nil
+	return out, nil
 }
 
 func (u *Manager) Add(ctx context.Context, policy NewPolicy) (string, error) {
-	reqDTO := buildAddRequest(policy)
-	addLeads(policy, reqDTO)
-	addDeptHeads(policy, reqDTO)
+	reqDTO := buildRequest(policy)
 
 	respDTO := &addResponse{}
 
@@ -93,9 +131,7 @@ func (u *Manager) Update(ctx context.Context, escalationID string, policy NewPol
 		return fmt.Errorf("failed to update escalation with err: %w", err)
 	}
 
-	reqDTO := buildAddRequest(policy)
-	addLeads(policy, reqDTO)
-	addDeptHeads(policy, reqDTO)
+	reqDTO := buildRequest(policy)
 
 	updateIDs(reqDTO, prevPolicy)
 
@@ -109,83 +145,98 @@ func (u *Manager) Update(ctx context.Context, escalationID string, policy NewPol
 	return nil
 }
 
-func updateIDs(reqDTO *addRequest, prevPolicy *EscalationPolicy) {
-	reqDTO.Policy.ID = prevPolicy.ID
+// Diff returns the differences between the live escalation policy and the requested policy
+func (u *Manager) Diff(live *EscalationPolicy, policy NewPolicy) []*diff.Field {
+	desired := buildRequest(policy).Policy
+
+	builder := &diff.Builder{}
+	builder.String("name", live.Name, desired.Name)
+	builder.String("description", live.Description, desired.Description)
+	builder.Int("num_loops", live.NumLoops, desired.NumLoops)
+	builder.String("on_call_handoff_notifications", live.OnCallHandoffNotifications, desired.OnCallHandoffNotifications)
+	builder.Set("teams", teamIDs(live.Teams), teamIDs(desired.Teams))
+	builder.Int("escalation_rules", len(live.EscalationRules), len(desired.EscalationRules))
+
+	for index, desiredRule := range desired.EscalationRules {
+		liveRule := &escalationRule{}
+		if index < len(live.EscalationRules) {
+			liveRule = live.EscalationRules[index]
+		}
+
+		path := fmt.Sprintf("escalation_rules[%d]", index)
+
+		builder.Int(path+".escalation_delay_in_minutes", liveRule.EscalationDelayInMinutes, desiredRule.EscalationDelayInMinutes)
+		// the targets of a rule are notified at the same time so their order does not matter
+		builder.Set(path+".targets", targetIDs(liveRule.Targets), targetIDs(desiredRule.Targets))
+	}
+
+	return builder.Fields()
+}
+
+func teamIDs(teams []*team) []string {
+	var out []string
+
+	for _, thisTeam := range teams {
+		out = append(out, diff.IDs(thisTeam.ID)...)
+	}
+
+	return out
+}
+
+func targetIDs(targets []*escalationTarget) []string {
+	var out []string
+
+	for _, target := range targets {
+		out = append(out, target.Type+":"+diff.IDs(target.ID)[0])
+	}
+
+	return out
 }
 
-func buildAddRequest(policy NewPolicy) *addRequest {
+func buildRequest(policy NewPolicy) *addRequest {
+	structure := getStructure(policy)
+
 	return &addRequest{
 		Policy: &EscalationPolicy{
-			Name: policy.GetTeamName() + " Escalation",
-			EscalationRules: []*escalationRule{
-				{
-					EscalationDelayInMinutes: 5,
-					Targets: []*escalationTarget{
-						{
-							ID:   policy.GetScheduleID(),
-							Type: "schedule_reference",
-						},
-					},
-				},
-			},
-			NumLoops: 9,
+			Name:            policy.GetPolicyName(),
+			EscalationRules: buildRules(structure.Tiers),
+			NumLoops:        structure.NumLoops,
 			Teams: []*team{
 				{
 					ID:   policy.GetTeamID(),
 					Type: "team_reference",
 				},
 			},
-			OnCallHandoffNotifications: "always",
+			OnCallHandoffNotifications: structure.HandoffNotifications,
 			Description:                policy.GetDescription(),
 		},
 	}
 }
 
-func addLeads(policy NewPolicy, reqDTO *addRequest) {
-	if len(policy.GetLeadIDs()) == 0 {
-		return
-	}
-
-	rule := &escalationRule{
-		EscalationDelayInMinutes: 5,
-	}
+func (u *Manager) Delete(ctx context.Context, policyID string) error {
+	uri := fmt.Sprintf(getURI, policyID)
 
-	for _, userID := range policy.GetLeadIDs() {
-		rule.Targets = append(rule.Targets, &escalationTarget{
-			ID:   userID,
-			Type: "user_reference",
-		})
+	err := u.api.Delete(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to delete policy '%s' with err: %s", policyID, err)
 	}
 
-	reqDTO.Policy.EscalationRules = append(reqDTO.Policy.EscalationRules, rule)
+	return nil
 }
 
-func addDeptHeads(policy NewPolicy, reqDTO *addRequest) {
-	if len(policy.GetDeptHeadsIDs()) == 0 {
-		return
-	}
-
-	rule := &escalationRule{
-		EscalationDelayInMinutes: 5,
-	}
-
-	for _, userID := range policy.GetDeptHeadsIDs() {
-		rule.Targets = append(rule.Targets, &escalationTarget{
-			ID:   userID,
-			Type: "user_reference",
-		})
-	}
-
-	reqDTO.Policy.EscalationRules = append(reqDTO.Policy.EscalationRules, rule)
+func updateIDs(reqDTO *addRequest, prevPolicy *EscalationPolicy) {
+	reqDTO.Policy.ID = prevPolicy.ID
 }
 
 type NewPolicy interface {
-	GetTeamName() string
+	GetPolicyName() string
 	GetDescription() string
 	GetScheduleID() string
 	GetTeamID() string
 	GetLeadIDs() []string
 	GetDeptHeadsIDs() []string
+	// GetStructure returns the layout of the policy or nil for the default layout (see DefaultStructure)
+	GetStructure() *Structure
 }
 
 type getEscalationsResponse struct {
@@ -193,6 +244,7 @@ type getEscalationsResponse struct {
 }
 
 type getEscalationPolicyResponse struct {
+	pd.Pagination
 	Policies []*EscalationPolicy `json:"escalation_policies"`
 }
 
@@ -206,6 +258,75 @@ type EscalationPolicy struct {
 	Description                string            `json:"description"`
 }
 
+// GetScheduleIDs returns the IDs of all of the schedules the policy escalates to
+func (e *EscalationPolicy) GetScheduleIDs() []string {
+	var out []string
+
+	for _, rule := range e.EscalationRules {
+		for _, target := range rule.Targets {
+			if target.Type == targetSchedule {
+				out = append(out, target.ID)
+			}
+		}
+	}
+
+	return out
+}
+
+// GetUserIDs returns the IDs of all of the users the policy escalates to (directly)
+func (e *EscalationPolicy) GetUserIDs() []string {
+	var out []string
+
+	for _, rule := range e.EscalationRules {
+		for _, target := range rule.Targets {
+			if target.Type == targetUser {
+				out = append(out, target.ID)
+			}
+		}
+	}
+
+	return out
+}
+
+// GetStructure returns the layout of the live policy (e.g. to export it)
+func (e *EscalationPolicy) GetStructure() *Structure {
+	out := &Structure{
+		NumLoops:             e.NumLoops,
+		HandoffNotifications: e.OnCallHandoffNotifications,
+	}
+
+	for _, rule := range e.EscalationRules {
+		tier := &Tier{
+			DelayMinutes: rule.EscalationDelayInMinutes,
+		}
+
+		for _, target := range rule.Targets {
+			switch target.Type {
+			case targetSchedule:
+				tier.ScheduleIDs = append(tier.ScheduleIDs, target.ID)
+
+			case targetUser:
+				tier.UserIDs = append(tier.UserIDs, target.ID)
+			}
+		}
+
+		out.Tiers = append(out.Tiers, tier)
+	}
+
+	return out
+}
+
+// GetTeamIDs returns the IDs of the teams the policy belongs to
+func (e *EscalationPolicy) GetTeamIDs() []string {
+	var out []string
+
+	for _, thisTeam := range e.Teams {
+		out = append(out, thisTeam.ID)
+	}
+
+	return out
+}
+
 type escalationRule struct {
 	EscalationDelayInMinutes int                 `json:"escalation_delay_in_minutes"`
 	Targets                  []*escalationTarget `json:"targets"`
@@ -233,4 +354,8 @@ type Config interface {
 	Debug() bool
 	BaseURL() string
 	AuthToken() string
+	PageSize() int
+	MaxRetries() int
+	MaxBackoff() time.Duration
+	CaseInsensitiveNames() bool
 }
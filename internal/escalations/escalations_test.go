@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -97,6 +98,22 @@ func TestManager_GetByName(t *testing.T) {
 			expected:  nil,
 			expectErr: true,
 		},
+		{
+			desc: "sad path - partial name match only",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"escalation_policies": [{"id": "A", "name": "B Platform"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			desc: "sad path - ambiguous name",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"escalation_policies": [{"id": "A", "name": "B"}, {"id": "B", "name": "B"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
 	}
 
 	for _, s := range scenarios {
@@ -118,7 +135,69 @@ func TestManager_GetByName(t *testing.T) {
 
 			// call object under test
 			manager := New(cfg, logger)
-			result, resultErr := manager.GetByName(ctx, "A")
+			result, resultErr := manager.GetByName(ctx, "B")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result, "expected result")
+		})
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*EscalationPolicy
+		expectErr             bool
+	}{
+		{
+			desc: "happy path - multiple pages",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("offset") == "" {
+					_, _ = resp.Write([]byte(`{"escalation_policies": [{"id": "A"}], "more": true}`))
+					return
+				}
+
+				_, _ = resp.Write([]byte(`{"escalation_policies": [{"id": "B"}], "more": false}`))
+			}),
+			expected: []*EscalationPolicy{
+				{ID: "A"},
+				{ID: "B"},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL:  testServer.URL,
+				pageSize: 1,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.List(ctx)
 
 			// validation
 			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
@@ -261,6 +340,169 @@ func TestManager_Update(t *testing.T) {
 	}
 }
 
+func TestManager_Diff(t *testing.T) {
+	scenarios := []struct {
+		desc      string
+		live      *EscalationPolicy
+		structure *Structure
+		expected  []*diff.Field
+	}{
+		{
+			desc: "no differences",
+			live: &EscalationPolicy{
+				Name:        "B Escalation",
+				Description: "C",
+				EscalationRules: []*escalationRule{
+					{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "F", Type: "schedule_reference"}}},
+					{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "G", Type: "user_reference"}}},
+					{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "H", Type: "user_reference"}}},
+				},
+				NumLoops:                   9,
+				Teams:                      []*team{{ID: "E", Type: "team_reference"}},
+				OnCallHandoffNotifications: "always",
+			},
+			expected: nil,
+		},
+		{
+			desc: "missing dept head rule",
+			live: &EscalationPolicy{
+				Name:        "B Escalation",
+				Description: "C",
+				EscalationRules: []*escalationRule{
+					{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "F", Type: "schedule_reference"}}},
+					{EscalationDelayInMinutes: 10, Targets: []*escalationTarget{{ID: "G", Type: "user_reference"}}},
+				},
+				NumLoops:                   9,
+				Teams:                      []*team{{ID: "E", Type: "team_reference"}},
+				OnCallHandoffNotifications: "always",
+			},
+			expected: []*diff.Field{
+				{Path: "escalation_rules", Live: "2", Desired: "3"},
+				{Path: "escalation_rules[1].escalation_delay_in_minutes", Live: "10", Desired: "5"},
+				{Path: "escalation_rules[2].escalation_delay_in_minutes", Live: "0", Desired: "5"},
+				{Path: "escalation_rules[2].targets", Live: "", Desired: "user_reference:H"},
+			},
+		},
+		{
+			desc: "custom structure with a shared schedule",
+			live: &EscalationPolicy{
+				Name:        "B Escalation",
+				Description: "C",
+				EscalationRules: []*escalationRule{
+					{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "F", Type: "schedule_reference"}}},
+					{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "G", Type: "user_reference"}}},
+					{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "H", Type: "user_reference"}}},
+				},
+				NumLoops:                   9,
+				Teams:                      []*team{{ID: "E", Type: "team_reference"}},
+				OnCallHandoffNotifications: "always",
+			},
+			structure: &Structure{
+				Tiers: []*Tier{
+					{DelayMinutes: 15, ScheduleIDs: []string{"F"}},
+					{DelayMinutes: 10, ScheduleIDs: []string{"SRE"}, UserIDs: []string{"G"}},
+					{DelayMinutes: 10},
+				},
+				NumLoops:             2,
+				HandoffNotifications: "has_services",
+			},
+			expected: []*diff.Field{
+				{Path: "num_loops", Live: "9", Desired: "2"},
+				{Path: "on_call_handoff_notifications", Live: "always", Desired: "has_services"},
+				{Path: "escalation_rules", Live: "3", Desired: "2"},
+				{Path: "escalation_rules[0].escalation_delay_in_minutes", Live: "5", Desired: "15"},
+				{Path: "escalation_rules[1].escalation_delay_in_minutes", Live: "5", Desired: "10"},
+				{Path: "escalation_rules[1].targets", Live: "user_reference:G", Desired: "schedule_reference:SRE, user_reference:G"},
+			},
+		},
+		{
+			desc: "targets of a rule in another order",
+			live: &EscalationPolicy{
+				Name:        "B Escalation",
+				Description: "C",
+				EscalationRules: []*escalationRule{
+					{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "G", Type: "user_reference"}, {ID: "F", Type: "schedule_reference"}}},
+				},
+				NumLoops:                   9,
+				Teams:                      []*team{{ID: "E", Type: "team_reference"}},
+				OnCallHandoffNotifications: "always",
+			},
+			structure: &Structure{
+				Tiers: []*Tier{
+					{DelayMinutes: 5, ScheduleIDs: []string{"F"}, UserIDs: []string{"G"}},
+				},
+				NumLoops:             9,
+				HandoffNotifications: "always",
+			},
+			expected: nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			newEscalation := &testEscalation{
+				name:        "B",
+				description: "C",
+				teamID:      "E",
+				scheduleID:  "F",
+				leadIDs:     []string{"G"},
+				deptHeadIDs: []string{"H"},
+				structure:   scenario.structure,
+			}
+
+			// call object under test
+			manager := New(&testConfig{}, logger)
+			result := manager.Diff(scenario.live, newEscalation)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestEscalationPolicy_GetScheduleIDs(t *testing.T) {
+	policy := &EscalationPolicy{
+		EscalationRules: []*escalationRule{
+			{Targets: []*escalationTarget{{ID: "F", Type: "schedule_reference"}}},
+			{Targets: []*escalationTarget{{ID: "G", Type: "user_reference"}, {ID: "H", Type: "schedule_reference"}}},
+		},
+	}
+
+	// call object under test
+	result := policy.GetScheduleIDs()
+
+	// validation
+	assert.Equal(t, []string{"F", "H"}, result)
+}
+
+func TestEscalationPolicy_GetStructure(t *testing.T) {
+	policy := &EscalationPolicy{
+		NumLoops:                   2,
+		OnCallHandoffNotifications: "always",
+		EscalationRules: []*escalationRule{
+			{EscalationDelayInMinutes: 5, Targets: []*escalationTarget{{ID: "F", Type: "schedule_reference"}}},
+			{EscalationDelayInMinutes: 10, Targets: []*escalationTarget{{ID: "G", Type: "user_reference"}, {ID: "H", Type: "schedule_reference"}}},
+		},
+	}
+
+	// call object under test
+	result := policy.GetStructure()
+
+	// validation
+	assert.Equal(t, &Structure{
+		Tiers: []*Tier{
+			{DelayMinutes: 5, ScheduleIDs: []string{"F"}},
+			{DelayMinutes: 10, ScheduleIDs: []string{"H"}, UserIDs: []string{"G"}},
+		},
+		NumLoops:             2,
+		HandoffNotifications: "always",
+	}, result)
+}
+
 type testEscalation struct {
 	name        string
 	description string
@@ -268,6 +510,7 @@ type testEscalation struct {
 	scheduleID  string
 	leadIDs     []string
 	deptHeadIDs []string
+	structure   *Structure
 }
 
 func (t *testEscalation) GetScheduleID() string {
@@ -282,8 +525,12 @@ func (t *testEscalation) GetDeptHeadsIDs() []string {
 	return t.deptHeadIDs
 }
 
-func (t *testEscalation) GetTeamName() string {
-	return t.name
+func (t *testEscalation) GetStructure() *Structure {
+	return t.structure
+}
+
+func (t *testEscalation) GetPolicyName() string {
+	return t.name + " Escalation"
 }
 
 func (t *testEscalation) GetDescription() string {
@@ -295,7 +542,11 @@ func (t *testEscalation) GetTeamID() string {
 }
 
 type testConfig struct {
-	baseURL string
+	baseURL         string
+	pageSize        int
+	caseInsensitive bool
+	maxRetries      int
+	maxBackoff      time.Duration
 }
 
 func (t *testConfig) AuthToken() string {
@@ -310,6 +561,22 @@ func (t *testConfig) BaseURL() string {
 	return t.baseURL
 }
 
+func (t *testConfig) PageSize() int {
+	return t.pageSize
+}
+
+func (t *testConfig) MaxRetries() int {
+	return t.maxRetries
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return t.maxBackoff
+}
+
+func (t *testConfig) CaseInsensitiveNames() bool {
+	return t.caseInsensitive
+}
+
 var getHappyPathResponse = `
 {
  "escalation_policy": {
@@ -0,0 +1,80 @@
+package escalations
+
+// Handoff notification settings
+const (
+	HandoffNotificationsAlways      = "always"
+	HandoffNotificationsHasServices = "has_services"
+)
+
+// Structure is the layout of an escalation policy
+type Structure struct {
+	// Tiers are the levels of the policy in order; tiers without targets are skipped
+	Tiers []*Tier
+	// NumLoops is the number of times the policy repeats after the last tier
+	NumLoops int
+	// HandoffNotifications is when on-call handoff notifications are sent (always or has_services)
+	HandoffNotifications string
+}
+
+// Tier is a level of an escalation policy
+type Tier struct {
+	// DelayMinutes before escalating to the next tier
+	DelayMinutes int
+	ScheduleIDs  []string
+	UserIDs      []string
+}
+
+// DefaultStructure returns the layout used when a policy does not have one;
+// the team's schedule, then the leads and then the dept heads, 5 minutes apart and repeated 9 times
+func DefaultStructure(policy NewPolicy) *Structure {
+	return &Structure{
+		Tiers: []*Tier{
+			{DelayMinutes: 5, ScheduleIDs: []string{policy.GetScheduleID()}},
+			{DelayMinutes: 5, UserIDs: policy.GetLeadIDs()},
+			{DelayMinutes: 5, UserIDs: policy.GetDeptHeadsIDs()},
+		},
+		NumLoops:             9,
+		HandoffNotifications: HandoffNotificationsAlways,
+	}
+}
+
+func getStructure(policy NewPolicy) *Structure {
+	structure := policy.GetStructure()
+	if structure == nil {
+		return DefaultStructure(policy)
+	}
+
+	return structure
+}
+
+func buildRules(tiers []*Tier) []*escalationRule {
+	var out []*escalationRule
+
+	for _, tier := range tiers {
+		rule := &escalationRule{
+			EscalationDelayInMinutes: tier.DelayMinutes,
+		}
+
+		for _, scheduleID := range tier.ScheduleIDs {
+			rule.Targets = append(rule.Targets, &escalationTarget{
+				ID:   scheduleID,
+				Type: targetSchedule,
+			})
+		}
+
+		for _, userID := range tier.UserIDs {
+			rule.Targets = append(rule.Targets, &escalationTarget{
+				ID:   userID,
+				Type: targetUser,
+			})
+		}
+
+		if len(rule.Targets) == 0 {
+			continue
+		}
+
+		out = append(out, rule)
+	}
+
+	return out
+}
@@ -0,0 +1,109 @@
+package naming
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Resource types that are named after the team
+const (
+	ResourceSchedule    = "schedule"
+	ResourceEscalation  = "escalation_policy"
+	ResourceTeamService = "team_service"
+)
+
+// TeamPlaceholder is replaced with the name of the team
+const TeamPlaceholder = "{team}"
+
+var (
+	ErrUnknownResource = errors.New("unknown resource type")
+	ErrNoPlaceholder   = errors.New("template does not contain " + TeamPlaceholder)
+)
+
+var defaultTemplates = map[string]string{
+	ResourceSchedule:    "{team} Schedule",
+	ResourceEscalation:  "{team} Escalation",
+	ResourceTeamService: "{team}",
+}
+
+// New builds a naming strategy from the supplied templates (keyed by resource type).
+// Resource types without a template use the defaults.
+// Legacy templates are the names that objects may have been created with previously; they are used for lookups only.
+func New(templates map[string]string, legacy map[string][]string) (*Strategy, error) {
+	out := &Strategy{
+		templates: map[string]string{},
+		legacy:    map[string][]string{},
+	}
+
+	for resource, template := range defaultTemplates {
+		out.templates[resource] = template
+	}
+
+	for resource, template := range templates {
+		err := validate(resource, template)
+		if err != nil {
+			return nil, err
+		}
+
+		out.templates[resource] = template
+	}
+
+	for resource, templates := range legacy {
+		for _, template := range templates {
+			err := validate(resource, template)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		out.legacy[resource] = templates
+	}
+
+	return out, nil
+}
+
+// Strategy builds the names of the PagerDuty objects that are created for each team
+type Strategy struct {
+	templates map[string]string
+	legacy    map[string][]string
+}
+
+// Name returns the name used to create and look up the resource for the team
+func (s *Strategy) Name(resource, teamName string) string {
+	return render(s.templates[resource], teamName)
+}
+
+// LegacyNames returns the names the resource for the team may have previously been created with
+func (s *Strategy) LegacyNames(resource, teamName string) []string {
+	var out []string
+
+	for _, template := range s.legacy[resource] {
+		name := render(template, teamName)
+		if name == s.Name(resource, teamName) {
+			continue
+		}
+
+		out = append(out, name)
+	}
+
+	return out
+}
+
+func validate(resource, template string) error {
+	_, ok := defaultTemplates[resource]
+	if !ok {
+		return fmt.Errorf("%w '%s'", ErrUnknownResource, resource)
+	}
+
+	// without the team name every team would share the same object
+	if !strings.Contains(template, TeamPlaceholder) {
+		return fmt.Errorf("%w for %s: '%s'", ErrNoPlaceholder, resource, template)
+	}
+
+	return nil
+}
+
+func render(template, teamName string) string {
+	return strings.ReplaceAll(template, TeamPlaceholder, teamName)
+}
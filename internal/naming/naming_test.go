@@ -0,0 +1,129 @@
+package naming
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStrategy_Name(t *testing.T) {
+	scenarios := []struct {
+		desc      string
+		templates map[string]string
+		resource  string
+		expected  string
+	}{
+		{
+			desc:     "default schedule name",
+			resource: ResourceSchedule,
+			expected: "Payments Schedule",
+		},
+		{
+			desc:     "default escalation policy name",
+			resource: ResourceEscalation,
+			expected: "Payments Escalation",
+		},
+		{
+			desc:     "default team service name",
+			resource: ResourceTeamService,
+			expected: "Payments",
+		},
+		{
+			desc: "custom template",
+			templates: map[string]string{
+				ResourceSchedule: "On-call: {team}",
+			},
+			resource: ResourceSchedule,
+			expected: "On-call: Payments",
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			strategy, err := New(scenario.templates, nil)
+			require.NoError(t, err)
+
+			// call object under test
+			result := strategy.Name(scenario.resource, "Payments")
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestStrategy_LegacyNames(t *testing.T) {
+	legacy := map[string][]string{
+		ResourceSchedule: {"{team}", "{team} Schedule", "{team} Rotation"},
+	}
+
+	strategy, err := New(nil, legacy)
+	require.NoError(t, err)
+
+	// call object under test
+	result := strategy.LegacyNames(ResourceSchedule, "Payments")
+
+	// validation
+	assert.Equal(t, []string{"Payments", "Payments Rotation"}, result, "current name should not be repeated")
+	assert.Empty(t, strategy.LegacyNames(ResourceEscalation, "Payments"))
+}
+
+func TestNew(t *testing.T) {
+	scenarios := []struct {
+		desc      string
+		templates map[string]string
+		legacy    map[string][]string
+		expectErr error
+	}{
+		{
+			desc: "happy path",
+			templates: map[string]string{
+				ResourceEscalation: "{team} EP",
+			},
+			legacy: map[string][]string{
+				ResourceEscalation: {"{team} Escalation"},
+			},
+			expectErr: nil,
+		},
+		{
+			desc: "sad path - unknown resource",
+			templates: map[string]string{
+				"user": "{team}",
+			},
+			expectErr: ErrUnknownResource,
+		},
+		{
+			desc: "sad path - template without team name",
+			templates: map[string]string{
+				ResourceSchedule: "Schedule",
+			},
+			expectErr: ErrNoPlaceholder,
+		},
+		{
+			desc: "sad path - legacy template without team name",
+			legacy: map[string][]string{
+				ResourceSchedule: {"Schedule"},
+			},
+			expectErr: ErrNoPlaceholder,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			_, resultErr := New(scenario.templates, scenario.legacy)
+
+			// validation
+			if scenario.expectErr == nil {
+				require.NoError(t, resultErr)
+				return
+			}
+
+			require.True(t, errors.Is(resultErr, scenario.expectErr), "unexpected err: %s", resultErr)
+		})
+	}
+}
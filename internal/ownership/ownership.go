@@ -0,0 +1,50 @@
+package ownership
+
+import (
+	"strings"
+)
+
+// Marker is added to the description of every PagerDuty object created by this tool.
+// Objects without it were created by hand (or by another tool) and are not modified unless they have been adopted.
+const Marker = "[managed by pagerduty-manager]"
+
+// AdminMarker is added to the description of users that were made admins by this tool.
+// Admins without it were made admins by hand (even when the user has been adopted) and are never demoted.
+const AdminMarker = "[admin role managed by pagerduty-manager]"
+
+// Mark returns the description with the ownership marker appended
+func Mark(description string) string {
+	return appendMarker(description, Marker)
+}
+
+// IsOwned returns true when the description contains the ownership marker
+func IsOwned(description string) bool {
+	return strings.Contains(description, Marker)
+}
+
+// MarkAdmin returns the description with the admin marker appended
+func MarkAdmin(description string) string {
+	return appendMarker(description, AdminMarker)
+}
+
+// UnmarkAdmin returns the description without the admin marker
+func UnmarkAdmin(description string) string {
+	return strings.TrimSpace(strings.ReplaceAll(description, AdminMarker, ""))
+}
+
+// IsAdminOwned returns true when the description contains the admin marker
+func IsAdminOwned(description string) bool {
+	return strings.Contains(description, AdminMarker)
+}
+
+func appendMarker(description, marker string) string {
+	if strings.Contains(description, marker) {
+		return description
+	}
+
+	if description == "" {
+		return marker
+	}
+
+	return description + " " + marker
+}
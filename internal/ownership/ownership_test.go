@@ -0,0 +1,59 @@
+package ownership
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMark(t *testing.T) {
+	scenarios := []struct {
+		desc        string
+		description string
+		expected    string
+	}{
+		{
+			desc:        "empty description",
+			description: "",
+			expected:    Marker,
+		},
+		{
+			desc:        "unmarked description",
+			description: "The Fab Four",
+			expected:    "The Fab Four " + Marker,
+		},
+		{
+			desc:        "already marked",
+			description: "The Fab Four " + Marker,
+			expected:    "The Fab Four " + Marker,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result := Mark(scenario.description)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+			assert.True(t, IsOwned(result))
+		})
+	}
+}
+
+func TestIsOwned(t *testing.T) {
+	assert.False(t, IsOwned(""))
+	assert.False(t, IsOwned("The Fab Four"))
+	assert.True(t, IsOwned("The Fab Four "+Marker))
+}
+
+func TestMarkAdmin(t *testing.T) {
+	marked := MarkAdmin("Bass " + Marker)
+
+	assert.Equal(t, "Bass "+Marker+" "+AdminMarker, marked)
+	assert.True(t, IsAdminOwned(marked))
+	assert.Equal(t, marked, MarkAdmin(marked), "already marked")
+	assert.Equal(t, "Bass "+Marker, UnmarkAdmin(marked))
+	assert.False(t, IsAdminOwned(UnmarkAdmin(marked)))
+}
@@ -1,19 +1,27 @@
 package pd
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 
 	"github.com/corsc/go-commons/iocloser"
 )
 
+// default and max page size for classic (offset based) pagination; PagerDuty caps the limit at 100
+const (
+	defaultPageSize = 100
+	maxPageSize     = 100
+)
+
 func New(cfg Config, logger *zap.Logger) *API {
 	return &API{
 		cfg:    cfg,
@@ -34,18 +42,9 @@ func (u *API) Get(ctx context.Context, uri string, params url.Values, respDTO in
 
 	u.logger.Debug("making HTTP GET request", zap.String("uri", fullURI))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fullURI, nil)
+	resp, err := u.do(ctx, http.MethodGet, fullURI, nil)
 	if err != nil {
-		return fmt.Errorf("failed to build GET request with err: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Token token="+u.cfg.AuthToken())
-	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := u.client.Do(req)
-	if err != nil {
-		return fmt.Errorf("failed to do GET request with err: %w", err)
+		return err
 	}
 
 	defer iocloser.Close(resp.Body)
@@ -60,6 +59,60 @@ func (u *API) Get(ctx context.Context, uri string, params url.Values, respDTO in
 	return u.parseResponse(resp, respDTO)
 }
 
+// List requests every page of a list endpoint.
+// newPage must return an empty response DTO for each page; onPage is called with each page once it has been parsed.
+// Both offset and cursor based pagination are supported.
+func (u *API) List(ctx context.Context, uri string, params url.Values, newPage func() Page, onPage func(page Page)) error {
+	pageSize := u.cfg.PageSize()
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	if pageSize > maxPageSize {
+		pageSize = maxPageSize
+	}
+
+	pageParams := url.Values{}
+	for key, values := range params {
+		pageParams[key] = values
+	}
+
+	pageParams.Set("limit", strconv.Itoa(pageSize))
+
+	offset := 0
+
+	for {
+		page := newPage()
+
+		err := u.Get(ctx, uri, pageParams, page)
+		if err != nil {
+			return err
+		}
+
+		onPage(page)
+
+		pagination := page.GetPagination()
+
+		switch {
+		case pagination.NextCursor != "":
+			pageParams.Set("cursor", pagination.NextCursor)
+
+		case pagination.More:
+			// advance by the limit PagerDuty applied, which can be lower than the requested one
+			limit := pagination.Limit
+			if limit <= 0 {
+				limit = pageSize
+			}
+
+			offset += limit
+			pageParams.Set("offset", strconv.Itoa(offset))
+
+		default:
+			return nil
+		}
+	}
+}
+
 func (u *API) Put(ctx context.Context, uri string, reqDTO interface{}) error {
 	fullURI := u.buildURI(uri, nil)
 
@@ -70,18 +123,9 @@ func (u *API) Put(ctx context.Context, uri string, reqDTO interface{}) error {
 
 	u.logger.Debug("making HTTP PUT request", zap.String("uri", fullURI))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPut, fullURI, bytes.NewBuffer(payload))
-	if err != nil {
-		return fmt.Errorf("failed to build PUT request with err: %w", err)
-	}
-
-	req.Header.Set("Authorization", "Token token="+u.cfg.AuthToken())
-	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := u.client.Do(req) //nolint:bodyclose
+	resp, err := u.do(ctx, http.MethodPut, fullURI, payload)
 	if err != nil {
-		return fmt.Errorf("failed to do PUT request with err: %w", err)
+		return err
 	}
 
 	defer iocloser.Close(resp.Body)
@@ -106,30 +150,43 @@ func (u *API) Post(ctx context.Context, uri string, reqDTO, respDTO interface{})
 
 	u.logger.Debug("making HTTP POST request", zap.String("uri", fullURI))
 
-	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fullURI, bytes.NewBuffer(payload))
+	resp, err := u.do(ctx, http.MethodPost, fullURI, payload)
 	if err != nil {
-		return fmt.Errorf("failed to build PUT request with err: %w", err)
+		return err
 	}
 
-	req.Header.Set("Authorization", "Token token="+u.cfg.AuthToken())
-	req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
-	req.Header.Set("Content-Type", "application/json")
+	defer iocloser.Close(resp.Body)
+
+	if resp.StatusCode != http.StatusCreated {
+		payload, _ := ioutil.ReadAll(resp.Body)
+		u.logger.Debug("response", zap.ByteString("payload", payload))
+
+		return fmt.Errorf("unexpected HTTP POST response code: %d", resp.StatusCode)
+	}
 
-	resp, err := u.client.Do(req)
+	return u.parseResponse(resp, respDTO)
+}
+
+func (u *API) Delete(ctx context.Context, uri string) error {
+	fullURI := u.buildURI(uri, nil)
+
+	u.logger.Debug("making HTTP DELETE request", zap.String("uri", fullURI))
+
+	resp, err := u.do(ctx, http.MethodDelete, fullURI, nil)
 	if err != nil {
-		return fmt.Errorf("failed to do PUT request with err: %w", err)
+		return err
 	}
 
 	defer iocloser.Close(resp.Body)
 
-	if resp.StatusCode != http.StatusCreated {
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
 		payload, _ := ioutil.ReadAll(resp.Body)
 		u.logger.Debug("response", zap.ByteString("payload", payload))
 
-		return fmt.Errorf("unexpected HTTP PUT response code: %d", resp.StatusCode)
+		return fmt.Errorf("unexpected HTTP DELETE response code: %d", resp.StatusCode)
 	}
 
-	return u.parseResponse(resp, respDTO)
+	return nil
 }
 
 func (u *API) buildURI(uri string, params url.Values) string {
@@ -158,8 +215,39 @@ func (u *API) parseResponse(resp *http.Response, respDTO interface{}) error {
 	return nil
 }
 
+// NamesMatch returns true when the name of a PagerDuty object exactly matches the requested name.
+// PagerDuty's query parameter is a substring search, so this should be applied to all query results.
+func NamesMatch(requested, actual string, caseInsensitive bool) bool {
+	if caseInsensitive {
+		return strings.EqualFold(requested, actual)
+	}
+
+	return requested == actual
+}
+
+// Pagination contains the pagination fields of PagerDuty list responses.
+// It should be embedded into list response DTOs so they implement Page.
+type Pagination struct {
+	Limit      int    `json:"limit"`
+	Offset     int    `json:"offset"`
+	More       bool   `json:"more"`
+	NextCursor string `json:"next_cursor"`
+}
+
+func (p *Pagination) GetPagination() *Pagination {
+	return p
+}
+
+// Page is a single page of a list response
+type Page interface {
+	GetPagination() *Pagination
+}
+
 type Config interface {
 	Debug() bool
 	BaseURL() string
 	AuthToken() string
+	PageSize() int
+	MaxRetries() int
+	MaxBackoff() time.Duration
 }
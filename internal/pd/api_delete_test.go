@@ -0,0 +1,64 @@
+package pd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAPI_Delete(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusNoContent)
+			}),
+			expectErr: false,
+		},
+		{
+			desc: "sad path - bad response",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+
+			uri := "/teams/fu/users/bar"
+
+			resultErr := manager.Delete(ctx, uri)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+		})
+	}
+}
@@ -106,7 +106,10 @@ type Team struct {
 }
 
 type testConfig struct {
-	baseURL string
+	baseURL    string
+	pageSize   int
+	maxRetries int
+	maxBackoff time.Duration
 }
 
 func (t *testConfig) AuthToken() string {
@@ -121,6 +124,18 @@ func (t *testConfig) BaseURL() string {
 	return t.baseURL
 }
 
+func (t *testConfig) PageSize() int {
+	return t.pageSize
+}
+
+func (t *testConfig) MaxRetries() int {
+	return t.maxRetries
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return t.maxBackoff
+}
+
 var getHappyPathResponse = `
 {
   "users": [
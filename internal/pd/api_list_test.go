@@ -0,0 +1,144 @@
+package pd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAPI_List(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		pageSize              int
+		expected              []string
+		expectErr             bool
+	}{
+		{
+			desc: "happy path - single page",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"users": [{"name": "Fred"}], "more": false}`))
+			}),
+			expected:  []string{"Fred"},
+			expectErr: false,
+		},
+		{
+			desc: "happy path - offset pagination",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "2", req.URL.Query().Get("limit"))
+
+				switch req.URL.Query().Get("offset") {
+				case "":
+					_, _ = resp.Write([]byte(`{"users": [{"name": "Fred"}, {"name": "Wilma"}], "offset": 0, "more": true}`))
+				case "2":
+					_, _ = resp.Write([]byte(`{"users": [{"name": "Barney"}], "offset": 2, "more": false}`))
+				default:
+					resp.WriteHeader(http.StatusBadRequest)
+				}
+			}),
+			expected:  []string{"Fred", "Wilma", "Barney"},
+			expectErr: false,
+		},
+		{
+			desc: "happy path - page size above the PagerDuty max",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "100", req.URL.Query().Get("limit"))
+
+				// PagerDuty returns fewer records when the limit is lowered
+				switch req.URL.Query().Get("offset") {
+				case "":
+					_, _ = resp.Write([]byte(`{"users": [{"name": "Fred"}, {"name": "Wilma"}], "limit": 2, "offset": 0, "more": true}`))
+				case "2":
+					_, _ = resp.Write([]byte(`{"users": [{"name": "Barney"}], "limit": 2, "offset": 2, "more": false}`))
+				default:
+					resp.WriteHeader(http.StatusBadRequest)
+				}
+			}),
+			pageSize:  200,
+			expected:  []string{"Fred", "Wilma", "Barney"},
+			expectErr: false,
+		},
+		{
+			desc: "happy path - cursor pagination",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				switch req.URL.Query().Get("cursor") {
+				case "":
+					_, _ = resp.Write([]byte(`{"users": [{"name": "Fred"}], "next_cursor": "abc"}`))
+				case "abc":
+					_, _ = resp.Write([]byte(`{"users": [{"name": "Wilma"}], "next_cursor": null}`))
+				default:
+					resp.WriteHeader(http.StatusBadRequest)
+				}
+			}),
+			expected:  []string{"Fred", "Wilma"},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - second page fails",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("offset") == "" {
+					_, _ = resp.Write([]byte(`{"users": [{"name": "Fred"}], "more": true}`))
+					return
+				}
+
+				resp.WriteHeader(http.StatusBadRequest)
+			}),
+			expected:  []string{"Fred"},
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL:  testServer.URL,
+				pageSize: 2,
+			}
+
+			if scenario.pageSize != 0 {
+				cfg.pageSize = scenario.pageSize
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+
+			var result []string
+
+			resultErr := manager.List(ctx, "/users", nil,
+				func() Page {
+					return &listResponse{}
+				},
+				func(page Page) {
+					for _, user := range page.(*listResponse).Users {
+						result = append(result, user.Name)
+					}
+				})
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result, "expected result")
+		})
+	}
+}
+
+type listResponse struct {
+	Pagination
+	Users []*User `json:"users"`
+}
@@ -0,0 +1,147 @@
+package pd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/corsc/go-commons/iocloser"
+)
+
+const (
+	baseBackoff       = 500 * time.Millisecond
+	defaultMaxBackoff = 30 * time.Second
+
+	// seconds until the rate limit resets
+	rateLimitResetHeader = "ratelimit-reset"
+)
+
+// do sends the request and returns the response.
+// Requests that are rate limited (429) or fail with a server error (5xx) are retried up to the configured max retries.
+// POST requests are not retried after server errors as they may have created the object.
+// The request fails without waiting when the retry would be after the deadline of the context.
+func (u *API) do(ctx context.Context, method, fullURI string, payload []byte) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		var body io.Reader
+		if payload != nil {
+			body = bytes.NewReader(payload)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, method, fullURI, body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build %s request with err: %w", method, err)
+		}
+
+		req.Header.Set("Authorization", "Token token="+u.cfg.AuthToken())
+		req.Header.Set("Accept", "application/vnd.pagerduty+json;version=2")
+		req.Header.Set("Content-Type", "application/json")
+
+		resp, err := u.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to do %s request with err: %w", method, err)
+		}
+
+		if !shouldRetry(method, resp.StatusCode) || attempt >= u.cfg.MaxRetries() {
+			return resp, nil
+		}
+
+		delay := u.retryDelay(resp.Header, attempt)
+
+		_, _ = io.Copy(ioutil.Discard, resp.Body)
+		iocloser.Close(resp.Body)
+
+		// waiting is pointless when the run would time out first
+		deadline, ok := ctx.Deadline()
+		if ok && time.Until(deadline) < delay {
+			return nil, fmt.Errorf("failed to retry %s request with err: %w (retry in %s)", method, context.DeadlineExceeded, delay)
+		}
+
+		u.logger.Debug("retrying HTTP request",
+			zap.String("method", method),
+			zap.String("uri", fullURI),
+			zap.Int("status", resp.StatusCode),
+			zap.Int("attempt", attempt+1),
+			zap.Duration("delay", delay))
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("failed to retry %s request with err: %w", method, ctx.Err())
+
+		case <-time.After(delay):
+		}
+	}
+}
+
+func shouldRetry(method string, statusCode int) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return statusCode >= http.StatusInternalServerError && method != http.MethodPost
+}
+
+// retryDelay returns how long to wait before the next attempt.
+// The server's Retry-After or rate limit reset headers are respected when present,
+// otherwise a jittered exponential backoff (capped to the configured max backoff) is used.
+func (u *API) retryDelay(header http.Header, attempt int) time.Duration {
+	delay, ok := parseRetryAfter(header.Get("Retry-After"))
+	if ok {
+		return delay
+	}
+
+	seconds, err := strconv.Atoi(header.Get(rateLimitResetHeader))
+	if err == nil && seconds >= 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	maxBackoff := u.cfg.MaxBackoff()
+	if maxBackoff <= 0 {
+		maxBackoff = defaultMaxBackoff
+	}
+
+	backoff := baseBackoff << uint(attempt)
+	if backoff > maxBackoff || backoff <= 0 {
+		backoff = maxBackoff
+	}
+
+	// "equal jitter"; wait at least half the backoff
+	half := backoff / 2
+
+	return half + time.Duration(rand.Int63n(int64(half)+1)) //nolint:gosec
+}
+
+// parseRetryAfter supports both formats of the Retry-After header; delay in seconds and HTTP date
+func parseRetryAfter(value string) (time.Duration, bool) {
+	if value == "" {
+		return 0, false
+	}
+
+	seconds, err := strconv.Atoi(value)
+	if err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+
+		return time.Duration(seconds) * time.Second, true
+	}
+
+	retryAt, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+
+	delay := time.Until(retryAt)
+	if delay < 0 {
+		delay = 0
+	}
+
+	return delay, true
+}
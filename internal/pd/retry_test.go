@@ -0,0 +1,246 @@
+package pd
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestAPI_Retry(t *testing.T) {
+	scenarios := []struct {
+		desc             string
+		method           string
+		failures         int32
+		failureCode      int
+		failureHeaders   map[string]string
+		maxRetries       int
+		expectedAttempts int32
+		expectErr        bool
+	}{
+		{
+			desc:        "happy path - rate limited with retry after",
+			method:      http.MethodGet,
+			failures:    2,
+			failureCode: http.StatusTooManyRequests,
+			failureHeaders: map[string]string{
+				"Retry-After": "0",
+			},
+			maxRetries:       3,
+			expectedAttempts: 3,
+			expectErr:        false,
+		},
+		{
+			desc:        "happy path - rate limited with rate limit reset",
+			method:      http.MethodPut,
+			failures:    1,
+			failureCode: http.StatusTooManyRequests,
+			failureHeaders: map[string]string{
+				"ratelimit-reset": "0",
+			},
+			maxRetries:       3,
+			expectedAttempts: 2,
+			expectErr:        false,
+		},
+		{
+			desc:             "happy path - server error with backoff",
+			method:           http.MethodGet,
+			failures:         2,
+			failureCode:      http.StatusServiceUnavailable,
+			maxRetries:       3,
+			expectedAttempts: 3,
+			expectErr:        false,
+		},
+		{
+			desc:             "happy path - rate limited POST is retried",
+			method:           http.MethodPost,
+			failures:         1,
+			failureCode:      http.StatusTooManyRequests,
+			maxRetries:       3,
+			expectedAttempts: 2,
+			expectErr:        false,
+		},
+		{
+			desc:             "sad path - POST server errors are not retried",
+			method:           http.MethodPost,
+			failures:         1,
+			failureCode:      http.StatusInternalServerError,
+			maxRetries:       3,
+			expectedAttempts: 1,
+			expectErr:        true,
+		},
+		{
+			desc:             "sad path - client errors are not retried",
+			method:           http.MethodGet,
+			failures:         1,
+			failureCode:      http.StatusBadRequest,
+			maxRetries:       3,
+			expectedAttempts: 1,
+			expectErr:        true,
+		},
+		{
+			desc:        "sad path - retry after the deadline",
+			method:      http.MethodGet,
+			failures:    1,
+			failureCode: http.StatusTooManyRequests,
+			failureHeaders: map[string]string{
+				"Retry-After": "60",
+			},
+			maxRetries:       3,
+			expectedAttempts: 1,
+			expectErr:        true,
+		},
+		{
+			desc:             "sad path - retry budget exhausted",
+			method:           http.MethodGet,
+			failures:         5,
+			failureCode:      http.StatusTooManyRequests,
+			maxRetries:       2,
+			expectedAttempts: 3,
+			expectErr:        true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			attempts := int32(0)
+
+			testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				attempt := atomic.AddInt32(&attempts, 1)
+				if attempt <= scenario.failures {
+					for key, value := range scenario.failureHeaders {
+						resp.Header().Set(key, value)
+					}
+
+					resp.WriteHeader(scenario.failureCode)
+					return
+				}
+
+				if req.Method == http.MethodPost {
+					resp.WriteHeader(http.StatusCreated)
+				}
+
+				_, _ = resp.Write([]byte(getHappyPathResponse))
+			}))
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL:    testServer.URL,
+				maxRetries: scenario.maxRetries,
+				maxBackoff: 2 * time.Millisecond,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+
+			var resultErr error
+
+			switch scenario.method {
+			case http.MethodGet:
+				resultErr = manager.Get(ctx, "/users", nil, &getResponse{})
+
+			case http.MethodPut:
+				resultErr = manager.Put(ctx, "/users/A", &User{Name: "Fred"})
+
+			case http.MethodPost:
+				resultErr = manager.Post(ctx, "/users", &User{Name: "Fred"}, &getResponse{})
+			}
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expectedAttempts, atomic.LoadInt32(&attempts))
+		})
+	}
+}
+
+func TestAPI_retryDelay(t *testing.T) {
+	scenarios := []struct {
+		desc        string
+		headers     map[string]string
+		attempt     int
+		expectedMin time.Duration
+		expectedMax time.Duration
+	}{
+		{
+			desc: "retry after seconds",
+			headers: map[string]string{
+				"Retry-After": "7",
+			},
+			expectedMin: 7 * time.Second,
+			expectedMax: 7 * time.Second,
+		},
+		{
+			desc: "retry after date in the past",
+			headers: map[string]string{
+				"Retry-After": "Wed, 21 Oct 2015 07:28:00 GMT",
+			},
+			expectedMin: 0,
+			expectedMax: 0,
+		},
+		{
+			desc: "rate limit reset",
+			headers: map[string]string{
+				"ratelimit-reset": "3",
+			},
+			expectedMin: 3 * time.Second,
+			expectedMax: 3 * time.Second,
+		},
+		{
+			desc:        "first backoff",
+			attempt:     0,
+			expectedMin: 250 * time.Millisecond,
+			expectedMax: 500 * time.Millisecond,
+		},
+		{
+			desc:        "backoff is exponential",
+			attempt:     2,
+			expectedMin: 1 * time.Second,
+			expectedMax: 2 * time.Second,
+		},
+		{
+			desc:        "backoff is capped",
+			attempt:     20,
+			expectedMin: 5 * time.Second,
+			expectedMax: 10 * time.Second,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			cfg := &testConfig{
+				maxBackoff: 10 * time.Second,
+			}
+
+			header := http.Header{}
+			for key, value := range scenario.headers {
+				header.Set(key, value)
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result := manager.retryDelay(header, scenario.attempt)
+
+			// validation
+			assert.GreaterOrEqual(t, int64(result), int64(scenario.expectedMin))
+			assert.LessOrEqual(t, int64(result), int64(scenario.expectedMax))
+		})
+	}
+}
@@ -0,0 +1,390 @@
+package schedules
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	restrictionDaily  = "daily_restriction"
+	restrictionWeekly = "weekly_restriction"
+)
+
+// Layer is a requested layer of a schedule
+type Layer struct {
+	Name    string
+	UserIDs []string
+	// Rotation of the layer; the rotation of the schedule is used when nil
+	Rotation *Rotation
+	// Restrictions limit when the layer is on-call; the layer is always on-call when there are none
+	Restrictions []*Restriction
+}
+
+// Restriction limits when a layer is on-call.
+// Daily restrictions apply every day; weekly restrictions start once a week on the start day.
+type Restriction struct {
+	Weekly   bool
+	StartDay time.Weekday
+	// StartTime is the time of day (since midnight) the restriction starts
+	StartTime time.Duration
+	Duration  time.Duration
+}
+
+// getLayers returns the requested layers.
+// Schedules without layers have a single layer containing the responders and then the leads.
+func getLayers(schedule ReqSchedule, rotation *Rotation) []*Layer {
+	requested := schedule.GetLayers()
+	if len(requested) == 0 {
+		var userIDs []string
+		userIDs = append(userIDs, schedule.GetResponderIDs()...)
+		userIDs = append(userIDs, schedule.GetLeadIDs()...)
+
+		return []*Layer{
+			{
+				Name:     layerName,
+				UserIDs:  userIDs,
+				Rotation: rotation,
+			},
+		}
+	}
+
+	var out []*Layer
+
+	for _, layer := range requested {
+		layerCopy := *layer
+
+		if layerCopy.Rotation == nil {
+			layerCopy.Rotation = rotation
+		}
+
+		out = append(out, &layerCopy)
+	}
+
+	return out
+}
+
+// ActiveLayers returns the layers of the schedule that have not been ended, with their rotations in the time zone of the
+// schedule (e.g. to export them). The schedule must have been fetched with Get to include its layers.
+func (s *Schedule) ActiveLayers() ([]*Layer, error) {
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return nil, fmt.Errorf("invalid time zone '%s' of schedule '%s'", s.TimeZone, s.Name)
+	}
+
+	var out []*Layer
+
+	for _, liveLayer := range activeLayers(s.ScheduleLayers) {
+		if liveLayer.RotationVirtualStart.IsZero() || liveLayer.RotationTurnLengthSeconds <= 0 {
+			return nil, fmt.Errorf("schedule layer '%s' has no rotation", liveLayer.Name)
+		}
+
+		virtualStart := liveLayer.RotationVirtualStart.In(location)
+		if virtualStart.Second() != 0 || virtualStart.Nanosecond() != 0 {
+			return nil, fmt.Errorf("handoff of schedule layer '%s' is not on a whole minute", liveLayer.Name)
+		}
+
+		layer := &Layer{
+			Name: liveLayer.Name,
+			Rotation: &Rotation{
+				Length:      time.Duration(liveLayer.RotationTurnLengthSeconds) * time.Second,
+				HandoffDay:  virtualStart.Weekday(),
+				HandoffTime: time.Duration(virtualStart.Hour())*time.Hour + time.Duration(virtualStart.Minute())*time.Minute,
+				StartDate:   time.Date(virtualStart.Year(), virtualStart.Month(), virtualStart.Day(), 0, 0, 0, 0, time.UTC),
+				TimeZone:    s.TimeZone,
+			},
+		}
+
+		for _, thisUser := range liveLayer.Users {
+			layer.UserIDs = append(layer.UserIDs, thisUser.ID)
+		}
+
+		for _, thisRestriction := range liveLayer.Restrictions {
+			restriction, err := thisRestriction.parse()
+			if err != nil {
+				return nil, fmt.Errorf("invalid restriction of schedule layer '%s' with err: %w", liveLayer.Name, err)
+			}
+
+			layer.Restrictions = append(layer.Restrictions, restriction)
+		}
+
+		out = append(out, layer)
+	}
+
+	return out, nil
+}
+
+// buildLayers builds the layers of the schedule; layers are matched with the live layers by name.
+// To preserve the on-call history and the current shift, live layers are never rewritten:
+// unchanged layers are left alone and changed layers are ended at the end of the current shift and replaced by a new layer
+// starting then.
+// Live layers that are no longer requested are ended (PagerDuty does not allow layers to be deleted).
+func buildLayers(liveLayers []*scheduleLayer, layers []*Layer, location *time.Location, now time.Time) ([]*scheduleLayer, error) {
+	var out []*scheduleLayer
+
+	// ended layers are kept as they are
+	for _, liveLayer := range liveLayers {
+		if liveLayer.End != nil {
+			out = append(out, liveLayer)
+		}
+	}
+
+	requested := map[string]bool{}
+
+	for _, layer := range layers {
+		if len(layer.UserIDs) == 0 {
+			return nil, fmt.Errorf("cannot build schedule layer '%s' with no users", layer.Name)
+		}
+
+		requested[layer.Name] = true
+
+		newLayer := &scheduleLayer{
+			Name:                      layer.Name,
+			Start:                     layer.Rotation.start(location),
+			RotationTurnLengthSeconds: int(layer.Rotation.Length.Seconds()),
+			Users:                     buildUsers(layer.UserIDs),
+			Restrictions:              buildRestrictions(layer.Restrictions),
+		}
+
+		// start and virtual start are the same for new layers
+		newLayer.RotationVirtualStart = newLayer.Start
+
+		liveLayer := findLayer(liveLayers, layer.Name)
+		if liveLayer == nil {
+			out = append(out, newLayer)
+			continue
+		}
+
+		if layerMatches(liveLayer, newLayer, layer.Rotation, location) {
+			out = append(out, liveLayer)
+			continue
+		}
+
+		if liveLayer.Start.After(now) {
+			// the layer has not started yet so there is no history to preserve
+			handoff := layer.Rotation.nextHandoff(now, location)
+
+			newLayer.ID = liveLayer.ID
+			newLayer.Start = handoff
+			newLayer.RotationVirtualStart = handoff
+
+			out = append(out, newLayer)
+			continue
+		}
+
+		// the current shift of the live layer is kept; the new layer starts when it ends and its first turn
+		// starts at the first handoff of the new rotation from then
+		cutover := liveLayer.nextHandoff(now, location)
+		if cutover.IsZero() {
+			cutover = layer.Rotation.nextHandoff(now, location)
+		}
+
+		newLayer.Start = cutover
+		newLayer.RotationVirtualStart = layer.Rotation.nextHandoff(cutover.Add(-time.Nanosecond), location)
+
+		endedLayer := *liveLayer
+		endedLayer.End = &cutover
+
+		out = append(out, &endedLayer, newLayer)
+	}
+
+	for _, liveLayer := range activeLayers(liveLayers) {
+		if requested[liveLayer.Name] {
+			continue
+		}
+
+		endedLayer := *liveLayer
+		endedLayer.End = &now
+
+		out = append(out, &endedLayer)
+	}
+
+	return out, nil
+}
+
+// layerMatches returns true when the live layer has the same users, rotation and restrictions as the new layer
+func layerMatches(liveLayer, newLayer *scheduleLayer, rotation *Rotation, location *time.Location) bool {
+	if liveLayer.RotationTurnLengthSeconds != newLayer.RotationTurnLengthSeconds {
+		return false
+	}
+
+	if userIDs(liveLayer.Users) != userIDs(newLayer.Users) {
+		return false
+	}
+
+	if restrictionsKey(liveLayer.Restrictions) != restrictionsKey(newLayer.Restrictions) {
+		return false
+	}
+
+	return handoffMatches(liveLayer, rotation, location)
+}
+
+// handoffMatches returns true when the turns of the live layer start at the handoffs of the rotation.
+// Used by both the sync and the diff so that a plan never shows a handoff change that the sync does not make.
+func handoffMatches(liveLayer *scheduleLayer, rotation *Rotation, location *time.Location) bool {
+	if liveLayer.RotationVirtualStart.IsZero() {
+		return false
+	}
+
+	start := rotation.start(location)
+
+	if rotation.Length%day != 0 {
+		// turns shorter than (or not a whole number of) days have more than one handoff time per day
+		return start.Sub(liveLayer.RotationVirtualStart)%rotation.Length == 0
+	}
+
+	return liveHandoff(liveLayer, rotation, location) == rotation.describeHandoff(start)
+}
+
+// nextHandoff returns the end of the current turn of the live layer or zero when the layer has no rotation
+func (l *scheduleLayer) nextHandoff(now time.Time, location *time.Location) time.Time {
+	if l.RotationVirtualStart.IsZero() || l.RotationTurnLengthSeconds <= 0 {
+		return time.Time{}
+	}
+
+	return nextTurn(l.RotationVirtualStart, time.Duration(l.RotationTurnLengthSeconds)*time.Second, now, location)
+}
+
+// liveHandoff describes the handoff of the live layer (using the requested rotation to decide if the day is relevant)
+func liveHandoff(liveLayer *scheduleLayer, rotation *Rotation, location *time.Location) string {
+	if liveLayer.RotationVirtualStart.IsZero() {
+		return ""
+	}
+
+	return rotation.describeHandoff(liveLayer.RotationVirtualStart.In(location))
+}
+
+func userIDs(users []*user) string {
+	var out []string
+
+	for _, thisUser := range users {
+		out = append(out, thisUser.ID)
+	}
+
+	return strings.Join(out, ",")
+}
+
+func restrictionsKey(restrictions []*restriction) string {
+	var out []string
+
+	for _, thisRestriction := range restrictions {
+		out = append(out, thisRestriction.String())
+	}
+
+	sort.Strings(out)
+
+	return strings.Join(out, ",")
+}
+
+// activeLayers returns the layers that have not been ended
+func activeLayers(layers []*scheduleLayer) []*scheduleLayer {
+	var out []*scheduleLayer
+
+	for _, layer := range layers {
+		if layer.End == nil {
+			out = append(out, layer)
+		}
+	}
+
+	return out
+}
+
+func findLayer(layers []*scheduleLayer, name string) *scheduleLayer {
+	for _, layer := range activeLayers(layers) {
+		if layer.Name == name {
+			return layer
+		}
+	}
+
+	return nil
+}
+
+func buildUsers(userIDs []string) []*user {
+	var out []*user
+
+	for _, userID := range userIDs {
+		out = append(out, &user{
+			ID:   userID,
+			Type: "user",
+		})
+	}
+
+	return out
+}
+
+func buildRestrictions(restrictions []*Restriction) []*restriction {
+	out := []*restriction{}
+
+	for _, thisRestriction := range restrictions {
+		startTime := time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Add(thisRestriction.StartTime)
+
+		newRestriction := &restriction{
+			Type:            restrictionDaily,
+			StartTimeOfDay:  startTime.Format("15:04:05"),
+			DurationSeconds: int(thisRestriction.Duration.Seconds()),
+		}
+
+		if thisRestriction.Weekly {
+			newRestriction.Type = restrictionWeekly
+
+			// PagerDuty uses ISO-8601 days; 1 (Monday) to 7 (Sunday)
+			newRestriction.StartDayOfWeek = int(thisRestriction.StartDay)
+			if thisRestriction.StartDay == time.Sunday {
+				newRestriction.StartDayOfWeek = 7
+			}
+		}
+
+		out = append(out, newRestriction)
+	}
+
+	return out
+}
+
+type restriction struct {
+	Type            string `json:"type"`
+	StartTimeOfDay  string `json:"start_time_of_day"`
+	StartDayOfWeek  int    `json:"start_day_of_week,omitempty"`
+	DurationSeconds int    `json:"duration_seconds"`
+}
+
+// parse converts the restriction back into a Restriction (the inverse of buildRestrictions)
+func (r *restriction) parse() (*Restriction, error) {
+	startTime, err := time.Parse("15:04:05", r.StartTimeOfDay)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time '%s'", r.StartTimeOfDay)
+	}
+
+	out := &Restriction{
+		StartTime: time.Duration(startTime.Hour())*time.Hour + time.Duration(startTime.Minute())*time.Minute +
+			time.Duration(startTime.Second())*time.Second,
+		Duration: time.Duration(r.DurationSeconds) * time.Second,
+	}
+
+	switch r.Type {
+	case restrictionDaily:
+		// no start day
+
+	case restrictionWeekly:
+		if r.StartDayOfWeek < 1 || r.StartDayOfWeek > 7 {
+			return nil, fmt.Errorf("invalid start day %d", r.StartDayOfWeek)
+		}
+
+		// PagerDuty uses ISO-8601 days; 1 (Monday) to 7 (Sunday)
+		out.Weekly = true
+		out.StartDay = time.Weekday(r.StartDayOfWeek % 7)
+
+	default:
+		return nil, fmt.Errorf("unknown type '%s'", r.Type)
+	}
+
+	return out, nil
+}
+
+func (r *restriction) String() string {
+	if r.Type == restrictionWeekly {
+		return fmt.Sprintf("weekly from day %d %s for %ds", r.StartDayOfWeek, r.StartTimeOfDay, r.DurationSeconds)
+	}
+
+	return fmt.Sprintf("daily from %s for %ds", r.StartTimeOfDay, r.DurationSeconds)
+}
@@ -0,0 +1,461 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildLayers(t *testing.T) {
+	now := time.Date(2021, time.August, 11, 12, 0, 0, 0, time.UTC)
+	firstHandoff := time.Date(2021, time.July, 5, 11, 0, 0, 0, time.UTC)
+	nextHandoff := time.Date(2021, time.August, 16, 11, 0, 0, 0, time.UTC)
+	lastWeek := time.Date(2021, time.August, 2, 11, 0, 0, 0, time.UTC)
+	tonight := time.Date(2021, time.August, 11, 23, 0, 0, 0, time.UTC)
+	fortnightHandoff := time.Date(2021, time.August, 16, 11, 0, 0, 0, time.UTC)
+
+	scenarios := []struct {
+		desc       string
+		liveLayers []*scheduleLayer
+		layers     []*Layer
+		expected   []*scheduleLayer
+		expectErr  bool
+	}{
+		{
+			desc: "new layer with restrictions",
+			layers: []*Layer{
+				{
+					Name:     "Weekend",
+					UserIDs:  []string{"A"},
+					Rotation: DefaultRotation(),
+					Restrictions: []*Restriction{
+						{Weekly: true, StartDay: time.Saturday, Duration: 48 * time.Hour},
+					},
+				},
+			},
+			expected: []*scheduleLayer{
+				{
+					Name:                      "Weekend",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+					Restrictions: []*restriction{
+						{Type: restrictionWeekly, StartTimeOfDay: "00:00:00", StartDayOfWeek: 6, DurationSeconds: 172800},
+					},
+				},
+			},
+		},
+		{
+			desc: "unchanged layer is left alone",
+			liveLayers: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     lastWeek,
+					RotationVirtualStart:      lastWeek,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}, {ID: "B", Type: "user"}},
+				},
+			},
+			layers: []*Layer{
+				{Name: "Layer 1", UserIDs: []string{"A", "B"}, Rotation: DefaultRotation()},
+			},
+			expected: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     lastWeek,
+					RotationVirtualStart:      lastWeek,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}, {ID: "B", Type: "user"}},
+				},
+			},
+		},
+		{
+			desc: "changed layer is ended and replaced at the next handoff",
+			liveLayers: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+				},
+			},
+			layers: []*Layer{
+				{Name: "Layer 1", UserIDs: []string{"A", "B"}, Rotation: DefaultRotation()},
+			},
+			expected: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+					End:                       &nextHandoff,
+				},
+				{
+					Name:                      "Layer 1",
+					Start:                     nextHandoff,
+					RotationVirtualStart:      nextHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}, {ID: "B", Type: "user"}},
+					Restrictions:              []*restriction{},
+				},
+			},
+		},
+		{
+			desc: "changed layer that has not started yet is updated",
+			liveLayers: []*scheduleLayer{
+				{
+					ID:                        "L2",
+					Name:                      "Layer 1",
+					Start:                     nextHandoff,
+					RotationVirtualStart:      nextHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+				},
+			},
+			layers: []*Layer{
+				{Name: "Layer 1", UserIDs: []string{"B"}, Rotation: DefaultRotation()},
+			},
+			expected: []*scheduleLayer{
+				{
+					ID:                        "L2",
+					Name:                      "Layer 1",
+					Start:                     nextHandoff,
+					RotationVirtualStart:      nextHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "B", Type: "user"}},
+					Restrictions:              []*restriction{},
+				},
+			},
+		},
+		{
+			desc: "removed layer is ended and ended layers are kept",
+			liveLayers: []*scheduleLayer{
+				{ID: "L0", Name: "Old", End: &lastWeek},
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+					Restrictions:              []*restriction{},
+				},
+			},
+			layers: []*Layer{
+				{
+					Name:     "After hours",
+					UserIDs:  []string{"B"},
+					Rotation: DefaultRotation(),
+					Restrictions: []*Restriction{
+						{StartTime: 17 * time.Hour, Duration: 16 * time.Hour},
+					},
+				},
+			},
+			expected: []*scheduleLayer{
+				{ID: "L0", Name: "Old", End: &lastWeek},
+				{
+					Name:                      "After hours",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "B", Type: "user"}},
+					Restrictions: []*restriction{
+						{Type: restrictionDaily, StartTimeOfDay: "17:00:00", DurationSeconds: 57600},
+					},
+				},
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 604800,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+					Restrictions:              []*restriction{},
+					End:                       &now,
+				},
+			},
+		},
+		{
+			desc: "unchanged 12h layer is left alone",
+			liveLayers: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     tonight,
+					RotationVirtualStart:      tonight,
+					RotationTurnLengthSeconds: 43200,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+				},
+			},
+			layers: []*Layer{
+				{
+					Name:    "Layer 1",
+					UserIDs: []string{"A"},
+					Rotation: &Rotation{
+						Length:      12 * time.Hour,
+						HandoffTime: 11 * time.Hour,
+						StartDate:   firstHandoff,
+					},
+				},
+			},
+			expected: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     tonight,
+					RotationVirtualStart:      tonight,
+					RotationTurnLengthSeconds: 43200,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+				},
+			},
+		},
+		{
+			desc: "changed 12h layer keeps the current shift",
+			liveLayers: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 43200,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+				},
+			},
+			layers: []*Layer{
+				{
+					Name:    "Layer 1",
+					UserIDs: []string{"A", "B"},
+					Rotation: &Rotation{
+						Length:      12 * time.Hour,
+						HandoffTime: 11 * time.Hour,
+						StartDate:   firstHandoff,
+					},
+				},
+			},
+			expected: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 43200,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+					End:                       &tonight,
+				},
+				{
+					Name:                      "Layer 1",
+					Start:                     tonight,
+					RotationVirtualStart:      tonight,
+					RotationTurnLengthSeconds: 43200,
+					Users:                     []*user{{ID: "A", Type: "user"}, {ID: "B", Type: "user"}},
+					Restrictions:              []*restriction{},
+				},
+			},
+		},
+		{
+			desc: "changed handoff keeps the current fortnightly shift",
+			liveLayers: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 1209600,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+				},
+			},
+			layers: []*Layer{
+				{
+					Name:    "Layer 1",
+					UserIDs: []string{"A"},
+					Rotation: &Rotation{
+						Length:      2 * week,
+						HandoffDay:  time.Friday,
+						HandoffTime: 9 * time.Hour,
+						StartDate:   firstHandoff,
+					},
+				},
+			},
+			expected: []*scheduleLayer{
+				{
+					ID:                        "L1",
+					Name:                      "Layer 1",
+					Start:                     firstHandoff,
+					RotationVirtualStart:      firstHandoff,
+					RotationTurnLengthSeconds: 1209600,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+					End:                       &fortnightHandoff,
+				},
+				{
+					Name:                      "Layer 1",
+					Start:                     fortnightHandoff,
+					RotationVirtualStart:      time.Date(2021, time.August, 20, 9, 0, 0, 0, time.UTC),
+					RotationTurnLengthSeconds: 1209600,
+					Users:                     []*user{{ID: "A", Type: "user"}},
+					Restrictions:              []*restriction{},
+				},
+			},
+		},
+		{
+			desc:      "sad path - layer without users",
+			layers:    []*Layer{{Name: "Weekend", Rotation: DefaultRotation()}},
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result, resultErr := buildLayers(scenario.liveLayers, scenario.layers, time.UTC, now)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestBuildLayers_daylightSaving(t *testing.T) {
+	location, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	// a Wednesday in winter; the rotation started in summer time
+	now := time.Date(2021, time.November, 10, 12, 0, 0, 0, location)
+	summerHandoff := time.Date(2021, time.July, 5, 11, 0, 0, 0, location)
+	winterHandoff := time.Date(2021, time.November, 15, 11, 0, 0, 0, location)
+
+	liveLayers := []*scheduleLayer{
+		{
+			ID:                        "L1",
+			Name:                      "Layer 1",
+			Start:                     summerHandoff,
+			RotationVirtualStart:      summerHandoff,
+			RotationTurnLengthSeconds: 604800,
+			Users:                     []*user{{ID: "A", Type: "user"}},
+		},
+	}
+
+	layers := []*Layer{
+		{Name: "Layer 1", UserIDs: []string{"A", "B"}, Rotation: DefaultRotation()},
+	}
+
+	// call object under test
+	result, resultErr := buildLayers(liveLayers, layers, location, now)
+	require.NoError(t, resultErr)
+
+	// validation
+	require.Len(t, result, 2)
+	assert.True(t, winterHandoff.Equal(*result[0].End), "changed layer ends at the handoff in local time, not %s", result[0].End)
+	assert.True(t, winterHandoff.Equal(result[1].Start), "new layer starts at the handoff in local time, not %s", result[1].Start)
+	assert.True(t, winterHandoff.Equal(result[1].RotationVirtualStart))
+
+	// a later sync leaves the layers alone
+	again, againErr := buildLayers(result, layers, location, now.AddDate(0, 0, 14))
+	require.NoError(t, againErr)
+	assert.Equal(t, result, again)
+}
+
+func TestSchedule_ActiveLayers(t *testing.T) {
+	ended := time.Date(2021, time.August, 2, 11, 0, 0, 0, time.UTC)
+
+	scenarios := []struct {
+		desc      string
+		in        *Schedule
+		expected  []*Layer
+		expectErr bool
+	}{
+		{
+			desc: "rotations are in the time zone of the schedule",
+			in: &Schedule{
+				TimeZone: "Europe/London",
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Old",
+						End:                       &ended,
+						RotationVirtualStart:      time.Date(2021, time.July, 5, 11, 0, 0, 0, time.UTC),
+						RotationTurnLengthSeconds: 604800,
+						Users:                     []*user{{ID: "A", Type: "user"}},
+					},
+					{
+						Name:                      "Business hours",
+						RotationVirtualStart:      time.Date(2021, time.August, 5, 8, 30, 0, 0, time.UTC),
+						RotationTurnLengthSeconds: 1209600,
+						Users:                     []*user{{ID: "B", Type: "user"}, {ID: "A", Type: "user"}},
+						Restrictions: []*restriction{
+							{Type: restrictionWeekly, StartTimeOfDay: "09:00:00", StartDayOfWeek: 7, DurationSeconds: 28800},
+							{Type: restrictionDaily, StartTimeOfDay: "17:30:00", DurationSeconds: 57600},
+						},
+					},
+				},
+			},
+			expected: []*Layer{
+				{
+					Name:    "Business hours",
+					UserIDs: []string{"B", "A"},
+					Rotation: &Rotation{
+						Length:      14 * 24 * time.Hour,
+						HandoffDay:  time.Thursday,
+						HandoffTime: 9*time.Hour + 30*time.Minute,
+						StartDate:   time.Date(2021, time.August, 5, 0, 0, 0, 0, time.UTC),
+						TimeZone:    "Europe/London",
+					},
+					Restrictions: []*Restriction{
+						{Weekly: true, StartDay: time.Sunday, StartTime: 9 * time.Hour, Duration: 8 * time.Hour},
+						{StartTime: 17*time.Hour + 30*time.Minute, Duration: 16 * time.Hour},
+					},
+				},
+			},
+		},
+		{
+			desc: "sad path - handoff is not on a whole minute",
+			in: &Schedule{
+				TimeZone: "UTC",
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      time.Date(2021, time.August, 5, 8, 30, 15, 0, time.UTC),
+						RotationTurnLengthSeconds: 604800,
+					},
+				},
+			},
+			expectErr: true,
+		},
+		{
+			desc: "sad path - unknown restriction type",
+			in: &Schedule{
+				TimeZone: "UTC",
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      time.Date(2021, time.August, 5, 8, 30, 0, 0, time.UTC),
+						RotationTurnLengthSeconds: 604800,
+						Restrictions:              []*restriction{{Type: "monthly", StartTimeOfDay: "09:00:00"}},
+					},
+				},
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result, resultErr := scenario.in.ActiveLayers()
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
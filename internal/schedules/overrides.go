@@ -0,0 +1,122 @@
+package schedules
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+const (
+	overridesURI = "/schedules/%s/overrides"
+	overrideURI  = "/schedules/%s/overrides/%s"
+)
+
+// ListOverrides returns the overrides of the schedule that overlap the period
+func (u *Manager) ListOverrides(ctx context.Context, scheduleID string, since, until time.Time) ([]*Override, error) {
+	uri := fmt.Sprintf(overridesURI, scheduleID)
+
+	params := url.Values{}
+	params.Set("since", since.Format(time.RFC3339))
+	params.Set("until", until.Format(time.RFC3339))
+
+	respDTO := &listOverridesResponse{}
+
+	err := u.api.Get(ctx, uri, params, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list overrides of schedule '%s' with err: %s", scheduleID, err)
+	}
+
+	return respDTO.Overrides, nil
+}
+
+// AddOverride creates the override on the schedule and returns its ID
+func (u *Manager) AddOverride(ctx context.Context, scheduleID string, override ReqOverride) (string, error) {
+	uri := fmt.Sprintf(overridesURI, scheduleID)
+
+	reqDTO := &overrideRequest{
+		Override: &Override{
+			Start: override.GetStart(),
+			End:   override.GetEnd(),
+			User: &user{
+				ID:   override.GetUserID(),
+				Type: "user_reference",
+			},
+		},
+	}
+
+	respDTO := &overrideRequest{}
+
+	err := u.api.Post(ctx, uri, reqDTO, respDTO)
+	if err != nil {
+		return "", fmt.Errorf("failed to add override to schedule '%s' with err: %s", scheduleID, err)
+	}
+
+	return respDTO.Override.ID, nil
+}
+
+// DeleteOverride removes the override; overrides that have already started are ended instead
+func (u *Manager) DeleteOverride(ctx context.Context, scheduleID, overrideID string) error {
+	uri := fmt.Sprintf(overrideURI, scheduleID, overrideID)
+
+	err := u.api.Delete(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to delete override '%s' of schedule '%s' with err: %s", overrideID, scheduleID, err)
+	}
+
+	return nil
+}
+
+// FindOverride returns the live override with the same user, start and end as the requested override (or nil)
+func FindOverride(live []*Override, override ReqOverride) *Override {
+	for _, liveOverride := range live {
+		if liveOverride.User == nil || liveOverride.User.ID != override.GetUserID() {
+			continue
+		}
+
+		if liveOverride.Start.Equal(override.GetStart()) && liveOverride.End.Equal(override.GetEnd()) {
+			return liveOverride
+		}
+	}
+
+	return nil
+}
+
+type ReqOverride interface {
+	GetUserID() string
+	GetStart() time.Time
+	GetEnd() time.Time
+}
+
+type listOverridesResponse struct {
+	Overrides []*Override `json:"overrides"`
+}
+
+type overrideRequest struct {
+	Override *Override `json:"override"`
+}
+
+// Override puts the user on-call for the schedule between start and end
+type Override struct {
+	ID    string    `json:"id,omitempty"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+	User  *user     `json:"user"`
+}
+
+// GetUserID returns the ID of the user on-call during the override
+func (o *Override) GetUserID() string {
+	if o.User == nil {
+		return ""
+	}
+
+	return o.User.ID
+}
+
+func (o *Override) GetStart() time.Time {
+	return o.Start
+}
+
+func (o *Override) GetEnd() time.Time {
+	return o.End
+}
@@ -0,0 +1,216 @@
+package schedules
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_ListOverrides(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*Override
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(listOverridesHappyPathResponse))
+			}),
+			expected: []*Override{
+				{
+					ID:    "O1",
+					Start: time.Date(2021, time.December, 24, 9, 0, 0, 0, time.UTC),
+					End:   time.Date(2021, time.December, 27, 9, 0, 0, 0, time.UTC),
+					User:  &user{ID: "A", Type: "user_reference"},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			since := time.Date(2021, time.December, 1, 0, 0, 0, 0, time.UTC)
+			until := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.ListOverrides(ctx, "S", since, until)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, len(scenario.expected), len(result))
+			for index, expected := range scenario.expected {
+				assert.Equal(t, expected.ID, result[index].ID)
+				assert.True(t, expected.Start.Equal(result[index].Start))
+				assert.True(t, expected.End.Equal(result[index].End))
+				assert.Equal(t, expected.User, result[index].User)
+			}
+		})
+	}
+}
+
+func TestManager_AddOverride(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              string
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusCreated)
+				_, _ = resp.Write([]byte(addOverrideHappyPathResponse))
+			}),
+			expected:  "O2",
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			override := &Override{
+				Start: time.Date(2021, time.December, 24, 9, 0, 0, 0, time.UTC),
+				End:   time.Date(2021, time.December, 27, 9, 0, 0, 0, time.UTC),
+				User:  &user{ID: "A"},
+			}
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.AddOverride(ctx, "S", override)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestFindOverride(t *testing.T) {
+	christmas := time.Date(2021, time.December, 24, 9, 0, 0, 0, time.UTC)
+	boxingDay := time.Date(2021, time.December, 27, 9, 0, 0, 0, time.UTC)
+
+	sydney, err := time.LoadLocation("Australia/Sydney")
+	require.NoError(t, err)
+
+	live := []*Override{
+		{ID: "O1", Start: christmas.In(sydney), End: boxingDay.In(sydney), User: &user{ID: "A"}},
+		{ID: "O2", Start: christmas, End: boxingDay, User: &user{ID: "B"}},
+	}
+
+	scenarios := []struct {
+		desc     string
+		in       *Override
+		expected *Override
+	}{
+		{
+			desc:     "same user and period in another time zone",
+			in:       &Override{Start: christmas, End: boxingDay, User: &user{ID: "A"}},
+			expected: live[0],
+		},
+		{
+			desc:     "different end",
+			in:       &Override{Start: christmas, End: boxingDay.Add(time.Hour), User: &user{ID: "B"}},
+			expected: nil,
+		},
+		{
+			desc:     "different user",
+			in:       &Override{Start: christmas, End: boxingDay, User: &user{ID: "C"}},
+			expected: nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result := FindOverride(live, scenario.in)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+var listOverridesHappyPathResponse = `
+{
+  "overrides": [
+    {
+      "id": "O1",
+      "start": "2021-12-24T20:00:00+11:00",
+      "end": "2021-12-27T20:00:00+11:00",
+      "user": {
+        "id": "A",
+        "type": "user_reference"
+      }
+    }
+  ]
+}
+`
+
+var addOverrideHappyPathResponse = `
+{
+  "override": {
+    "id": "O2",
+    "start": "2021-12-24T09:00:00Z",
+    "end": "2021-12-27T09:00:00Z",
+    "user": {
+      "id": "A",
+      "type": "user_reference"
+    }
+  }
+}
+`
@@ -0,0 +1,120 @@
+package schedules
+
+import (
+	"time"
+)
+
+const (
+	day  = 24 * time.Hour
+	week = 7 * day
+
+	// format of a handoff when the day and time of day are not enough to tell handoffs apart
+	handoffDateFormat = "Mon 2006-01-02 15:04"
+)
+
+// DefaultRotation returns the rotation used when a schedule does not have one; weekly, handing off on Monday at 11:00
+func DefaultRotation() *Rotation {
+	return &Rotation{
+		Length:      week,
+		HandoffDay:  time.Monday,
+		HandoffTime: 11 * time.Hour,
+		StartDate:   time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC),
+	}
+}
+
+// Rotation contains the settings of the on-call rotation of a schedule
+type Rotation struct {
+	// Length of each turn
+	Length time.Duration
+	// HandoffDay is only used when the length is a whole number of weeks
+	HandoffDay time.Weekday
+	// HandoffTime is the time of day (since midnight) of the handoff
+	HandoffTime time.Duration
+	// StartDate is the date (the time is ignored) of the first turn; the first turn starts on the first handoff day on or after it
+	StartDate time.Time
+	// TimeZone of the schedule; the default time zone is used when empty
+	TimeZone string
+}
+
+// timeZone returns the time zone of the rotation
+func (r *Rotation) timeZone(defaultTimeZone string) string {
+	if r.TimeZone != "" {
+		return r.TimeZone
+	}
+
+	return defaultTimeZone
+}
+
+func (r *Rotation) isWeekly() bool {
+	return r.Length%week == 0
+}
+
+// start returns the start of the first turn
+func (r *Rotation) start(location *time.Location) time.Time {
+	start := time.Date(r.StartDate.Year(), r.StartDate.Month(), r.StartDate.Day(), 0, 0, 0, 0, location).Add(r.HandoffTime)
+
+	for r.isWeekly() && start.Weekday() != r.HandoffDay {
+		start = start.AddDate(0, 0, 1)
+	}
+
+	return start
+}
+
+// nextHandoff returns the first handoff after now; handoffs are every turn length from the start of the first turn
+func (r *Rotation) nextHandoff(now time.Time, location *time.Location) time.Time {
+	return nextTurn(r.start(location), r.Length, now, location)
+}
+
+// nextTurn returns the start of the first turn after the time, where turns of the length start at the anchor
+// (as PagerDuty does with the virtual start and turn length of a layer).
+// Turns of whole days are counted in the wall-clock time of the location so that the handoff keeps its time of day across
+// daylight saving changes.
+func nextTurn(anchor time.Time, length time.Duration, after time.Time, location *time.Location) time.Time {
+	anchor = anchor.In(location)
+
+	if anchor.After(after) || length <= 0 {
+		return anchor
+	}
+
+	if length%day != 0 {
+		turns := after.Sub(anchor)/length + 1
+
+		return anchor.Add(turns * length)
+	}
+
+	days := int(length / day)
+
+	// the estimate is off by at most one turn as daylight saving only moves the wall-clock by hours
+	turns := int(after.Sub(anchor) / length)
+	out := anchor.AddDate(0, 0, turns*days)
+
+	for out.After(after) {
+		turns--
+		out = anchor.AddDate(0, 0, turns*days)
+	}
+
+	for !out.After(after) {
+		turns++
+		out = anchor.AddDate(0, 0, turns*days)
+	}
+
+	return out
+}
+
+// describeHandoff returns the day (for weekly rotations) and time of the handoff at the time
+func (r *Rotation) describeHandoff(handoff time.Time) string {
+	if r.isWeekly() {
+		return handoff.Weekday().String() + " " + handoff.Format("15:04")
+	}
+
+	return handoff.Format("15:04")
+}
+
+func getRotation(schedule ReqSchedule) *Rotation {
+	rotation := schedule.GetRotation()
+	if rotation == nil {
+		return DefaultRotation()
+	}
+
+	return rotation
+}
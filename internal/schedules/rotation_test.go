@@ -0,0 +1,201 @@
+package schedules
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotation_start(t *testing.T) {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+
+	scenarios := []struct {
+		desc     string
+		rotation *Rotation
+		expected time.Time
+	}{
+		{
+			desc:     "default",
+			rotation: DefaultRotation(),
+			expected: time.Date(2021, time.July, 5, 11, 0, 0, 0, location),
+		},
+		{
+			desc: "weekly starts on the first handoff day",
+			rotation: &Rotation{
+				Length:      week,
+				HandoffDay:  time.Wednesday,
+				HandoffTime: 9*time.Hour + 30*time.Minute,
+				StartDate:   time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC),
+			},
+			expected: time.Date(2021, time.July, 7, 9, 30, 0, 0, location),
+		},
+		{
+			desc: "daily ignores the handoff day",
+			rotation: &Rotation{
+				Length:      24 * time.Hour,
+				HandoffDay:  time.Wednesday,
+				HandoffTime: 8 * time.Hour,
+				StartDate:   time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC),
+			},
+			expected: time.Date(2021, time.July, 5, 8, 0, 0, 0, location),
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result := scenario.rotation.start(location)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestRotation_nextHandoff(t *testing.T) {
+	location, _ := time.LoadLocation("Asia/Jakarta")
+
+	// a Wednesday
+	now := time.Date(2021, time.July, 7, 12, 0, 0, 0, location)
+
+	// a Monday
+	startDate := time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC)
+
+	scenarios := []struct {
+		desc     string
+		rotation *Rotation
+		expected time.Time
+	}{
+		{
+			desc:     "weekly - next Monday",
+			rotation: DefaultRotation(),
+			expected: time.Date(2021, time.July, 12, 11, 0, 0, 0, location),
+		},
+		{
+			desc: "weekly - later today",
+			rotation: &Rotation{
+				Length:      week,
+				HandoffDay:  time.Wednesday,
+				HandoffTime: 17 * time.Hour,
+				StartDate:   startDate,
+			},
+			expected: time.Date(2021, time.July, 7, 17, 0, 0, 0, location),
+		},
+		{
+			desc: "daily - tomorrow",
+			rotation: &Rotation{
+				Length:      24 * time.Hour,
+				HandoffTime: 9 * time.Hour,
+				StartDate:   startDate,
+			},
+			expected: time.Date(2021, time.July, 8, 9, 0, 0, 0, location),
+		},
+		{
+			desc: "12h - this evening",
+			rotation: &Rotation{
+				Length:      12 * time.Hour,
+				HandoffTime: 9 * time.Hour,
+				StartDate:   startDate,
+			},
+			expected: time.Date(2021, time.July, 7, 21, 0, 0, 0, location),
+		},
+		{
+			desc: "48h - the day after tomorrow",
+			rotation: &Rotation{
+				Length:      48 * time.Hour,
+				HandoffTime: 9 * time.Hour,
+				StartDate:   startDate,
+			},
+			expected: time.Date(2021, time.July, 9, 9, 0, 0, 0, location),
+		},
+		{
+			desc: "fortnightly - Monday after next",
+			rotation: &Rotation{
+				Length:      2 * week,
+				HandoffDay:  time.Monday,
+				HandoffTime: 11 * time.Hour,
+				StartDate:   startDate,
+			},
+			expected: time.Date(2021, time.July, 19, 11, 0, 0, 0, location),
+		},
+		{
+			desc: "first turn has not started yet",
+			rotation: &Rotation{
+				Length:      24 * time.Hour,
+				HandoffTime: 9 * time.Hour,
+				StartDate:   time.Date(2021, time.August, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expected: time.Date(2021, time.August, 1, 9, 0, 0, 0, location),
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result := scenario.rotation.nextHandoff(now, location)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestRotation_nextHandoff_daylightSaving(t *testing.T) {
+	location, err := time.LoadLocation("Europe/London")
+	require.NoError(t, err)
+
+	scenarios := []struct {
+		desc     string
+		rotation *Rotation
+		now      time.Time
+		expected time.Time
+	}{
+		{
+			desc:     "weekly - started in summer time, next handoff in winter",
+			rotation: DefaultRotation(),
+			now:      time.Date(2021, time.November, 10, 12, 0, 0, 0, location),
+			expected: time.Date(2021, time.November, 15, 11, 0, 0, 0, location),
+		},
+		{
+			desc:     "weekly - just before the handoff in winter",
+			rotation: DefaultRotation(),
+			now:      time.Date(2021, time.November, 15, 10, 30, 0, 0, location),
+			expected: time.Date(2021, time.November, 15, 11, 0, 0, 0, location),
+		},
+		{
+			desc: "daily - across the change to summer time",
+			rotation: &Rotation{
+				Length:      day,
+				HandoffTime: 9 * time.Hour,
+				StartDate:   time.Date(2022, time.January, 3, 0, 0, 0, 0, time.UTC),
+			},
+			now:      time.Date(2022, time.March, 27, 9, 30, 0, 0, location),
+			expected: time.Date(2022, time.March, 28, 9, 0, 0, 0, location),
+		},
+		{
+			desc: "12h - turns shorter than a day are not moved",
+			rotation: &Rotation{
+				Length:      12 * time.Hour,
+				HandoffTime: 9 * time.Hour,
+				StartDate:   time.Date(2021, time.October, 30, 0, 0, 0, 0, time.UTC),
+			},
+			now:      time.Date(2021, time.November, 1, 12, 0, 0, 0, location),
+			expected: time.Date(2021, time.November, 1, 20, 0, 0, 0, location),
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result := scenario.rotation.nextHandoff(scenario.now, location)
+
+			// validation
+			assert.True(t, scenario.expected.Equal(result), "expected %s but was %s", scenario.expected, result)
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
 	"github.com/corsc/pagerduty-manager/internal/pd"
 
 	"go.uber.org/zap"
@@ -17,12 +18,13 @@ const (
 	listURI   = "/schedules"
 	addURI    = "/schedules?overflow=true"
 	updateURI = "/schedules/%s?overflow=true"
+
+	layerName = "Layer 1"
 )
 
 var (
-	ErrNoSuchSchedule = errors.New("no such schedule")
-
-	rotationLengthSeconds = 60 * 60 * 24 * 7
+	ErrNoSuchSchedule    = errors.New("no such schedule")
+	ErrAmbiguousSchedule = errors.New("more than one schedule matches")
 )
 
 func New(cfg Config, logger *zap.Logger) *Manager {
@@ -57,55 +59,86 @@ func (u *Manager) Get(ctx context.Context, scheduleID string) (*Schedule, error)
 	return schedules.Schedule, nil
 }
 
+// GetByName returns the schedule with exactly the supplied name
 func (u *Manager) GetByName(ctx context.Context, name string) (*Schedule, error) {
 	params := url.Values{}
 	params.Set("query", name)
-	params.Set("total", "false")
-	params.Set("limit", "1")
 
-	schedules := &getScheduleResponse{}
+	var matches []*Schedule
 
-	err := u.api.Get(ctx, listURI, params, schedules)
+	err := u.api.List(ctx, listURI, params,
+		func() pd.Page {
+			return &getScheduleResponse{}
+		},
+		func(page pd.Page) {
+			for _, schedule := range page.(*getScheduleResponse).Schedules {
+				if pd.NamesMatch(name, schedule.Name, u.cfg.CaseInsensitiveNames()) {
+					matches = append(matches, schedule)
+				}
+			}
+		})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get schedules '%s' with err: %s", name, err)
 	}
 
-	if len(schedules.Schedules) == 0 {
+	switch len(matches) {
+	case 0:
 		return nil, ErrNoSuchSchedule
+
+	case 1:
+		return matches[0], nil
+
+	default:
+		return nil, fmt.Errorf("%w: %d schedules named '%s'", ErrAmbiguousSchedule, len(matches), name)
 	}
+}
 
-	return schedules.Schedules[0], nil
+// List returns every schedule in the account
+func (u *Manager) List(ctx context.Context) ([]*Schedule, error) {
+	var out []*Schedule
+
+	err := u.api.List(ctx, listURI, nil,
+		func() pd.Page {
+			return &getScheduleResponse{}
+		},
+		func(page pd.Page) {
+			out = append(out, page.(*getScheduleResponse).Schedules...)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list schedules with err: %s", err)
+	}
+
+	return out, nil
 }
 
 func (u *Manager) Add(ctx context.Context, schedule ReqSchedule, defaultTimeZone string) (string, error) {
-	location, err := time.LoadLocation(defaultTimeZone)
+	rotation := getRotation(schedule)
+	timeZone := rotation.timeZone(defaultTimeZone)
+
+	location, err := time.LoadLocation(timeZone)
 	if err != nil {
 		return "", fmt.Errorf("failed to determine location with err: %w", err)
 	}
 
-	if len(schedule.GetResponderIDs()) == 0 {
-		return "", fmt.Errorf("cannot create schedule with no responders")
+	layers, err := buildLayers(nil, getLayers(schedule, rotation), location, time.Now())
+	if err != nil {
+		return "", fmt.Errorf("cannot create schedule with err: %w", err)
 	}
 
 	reqDTO := &addRequest{
 		Schedule: &Schedule{
-			Name:        schedule.GetTeamName() + " Schedule",
+			Name:        schedule.GetScheduleName(),
 			Description: schedule.GetDescription(),
-			TimeZone:    defaultTimeZone,
+			TimeZone:    timeZone,
 			Teams: []*team{
 				{
 					ID: schedule.GetTeamID(),
 				},
 			},
-			ScheduleLayers: []*scheduleLayer{
-				buildMemberLayer(schedule, location),
-			},
+			ScheduleLayers: layers,
 		},
 	}
 
-	// start and virtual start the same
-	reqDTO.Schedule.ScheduleLayers[0].RotationVirtualStart = time.Date(2021, time.July, 5, 11, 0, 0, 0, location)
-
 	respDTO := &addResponse{}
 
 	err = u.api.Post(ctx, addURI, reqDTO, respDTO)
@@ -122,18 +155,28 @@ func (u *Manager) Update(ctx context.Context, scheduleID string, schedule ReqSch
 		return fmt.Errorf("failed to update schedule with err: %w", err)
 	}
 
-	location, err := time.LoadLocation(defaultTimeZone)
+	rotation := getRotation(schedule)
+	timeZone := rotation.timeZone(defaultTimeZone)
+
+	location, err := time.LoadLocation(timeZone)
 	if err != nil {
 		return fmt.Errorf("failed to determine location with err: %w", err)
 	}
 
-	if len(schedule.GetResponderIDs()) == 0 {
-		return fmt.Errorf("cannot update schedule with no responders")
+	layers, err := buildLayers(scheduleToUpdate.ScheduleLayers, getLayers(schedule, rotation), location, time.Now())
+	if err != nil {
+		return fmt.Errorf("cannot update schedule with err: %w", err)
 	}
 
-	updateLayer(scheduleToUpdate, schedule, defaultTimeZone, location)
-
-	updateMembers(schedule, scheduleToUpdate)
+	scheduleToUpdate.Name = schedule.GetScheduleName()
+	scheduleToUpdate.Description = schedule.GetDescription()
+	scheduleToUpdate.TimeZone = timeZone
+	scheduleToUpdate.Teams = []*team{
+		{
+			ID: schedule.GetTeamID(),
+		},
+	}
+	scheduleToUpdate.ScheduleLayers = layers
 
 	uri := fmt.Sprintf(updateURI, scheduleID)
 
@@ -145,84 +188,110 @@ func (u *Manager) Update(ctx context.Context, scheduleID string, schedule ReqSch
 	return nil
 }
 
-func updateLayer(scheduleToUpdate *Schedule, schedule ReqSchedule, defaultTimeZone string, location *time.Location) {
-	scheduleToUpdate.Name = schedule.GetTeamName() + " Schedule"
-	scheduleToUpdate.Description = schedule.GetDescription()
-	scheduleToUpdate.TimeZone = defaultTimeZone
-	scheduleToUpdate.Teams = []*team{
-		{
-			ID: schedule.GetTeamID(),
-		},
-	}
+// Diff returns the differences between the live schedule and the requested schedule.
+// Layers are compared by name.
+// Note: the start of the rotation is not compared, only the day (for weekly rotations) and time of the handoff.
+func (u *Manager) Diff(live *Schedule, schedule ReqSchedule, defaultTimeZone string) []*diff.Field {
+	rotation := getRotation(schedule)
 
-	scheduleToUpdate.ScheduleLayers[0].Name = "Layer 1"
-	scheduleToUpdate.ScheduleLayers[0].Start = time.Date(2021, time.July, 5, 11, 0, 0, 0, location)
-	scheduleToUpdate.ScheduleLayers[0].RotationTurnLengthSeconds = rotationLengthSeconds
+	location, err := time.LoadLocation(rotation.timeZone(defaultTimeZone))
+	if err != nil {
+		location = time.UTC
+	}
 
-	// virtual start is the next Monday
-	now := time.Now()
-	virtualStart := time.Date(now.Year(), now.Month(), now.Day(), 11, 0, 0, 0, location)
+	builder := &diff.Builder{}
+	builder.String("name", live.Name, schedule.GetScheduleName())
+	builder.String("description", live.Description, schedule.GetDescription())
+	builder.String("time_zone", live.TimeZone, rotation.timeZone(defaultTimeZone))
 
-	if time.Now().Weekday() == time.Sunday {
-		virtualStart = virtualStart.Add(24 * time.Hour)
-	} else {
-		virtualStart = virtualStart.Add(24 * time.Hour * time.Duration(8-time.Now().Weekday()))
+	var liveTeamIDs []string
+	for _, thisTeam := range live.Teams {
+		liveTeamIDs = append(liveTeamIDs, thisTeam.ID)
 	}
 
-	scheduleToUpdate.ScheduleLayers[0].RotationVirtualStart = virtualStart
-}
+	builder.Set("teams", liveTeamIDs, diff.IDs(schedule.GetTeamID()))
 
-func updateMembers(schedule ReqSchedule, scheduleToUpdate *Schedule) {
-	var members []*user
+	layers := getLayers(schedule, rotation)
 
-	for _, userID := range schedule.GetResponderIDs() {
-		members = append(members, &user{
-			ID:   userID,
-			Type: "user",
-		})
+	var liveLayerNames []string
+	for _, liveLayer := range activeLayers(live.ScheduleLayers) {
+		liveLayerNames = append(liveLayerNames, liveLayer.Name)
 	}
 
-	for _, userID := range schedule.GetLeadIDs() {
-		members = append(members, &user{
-			ID:   userID,
-			Type: "user",
-		})
+	var layerNames []string
+	for _, layer := range layers {
+		layerNames = append(layerNames, layer.Name)
 	}
 
-	scheduleToUpdate.ScheduleLayers[0].Users = members
-}
+	builder.List("layers", liveLayerNames, layerNames)
 
-func buildMemberLayer(schedule ReqSchedule, location *time.Location) *scheduleLayer {
-	var members []*user
+	for _, layer := range layers {
+		liveLayer := findLayer(live.ScheduleLayers, layer.Name)
+		if liveLayer == nil {
+			liveLayer = &scheduleLayer{}
+		}
 
-	for _, userID := range schedule.GetResponderIDs() {
-		members = append(members, &user{
-			ID:   userID,
-			Type: "user",
-		})
-	}
+		path := "layers[" + layer.Name + "]"
 
-	for _, userID := range schedule.GetLeadIDs() {
-		members = append(members, &user{
-			ID:   userID,
-			Type: "user",
-		})
+		builder.Int(path+".rotation_turn_length_seconds", liveLayer.RotationTurnLengthSeconds, int(layer.Rotation.Length.Seconds()))
+
+		if !handoffMatches(liveLayer, layer.Rotation, location) {
+			liveDescription := liveHandoff(liveLayer, layer.Rotation, location)
+			description := layer.Rotation.describeHandoff(layer.Rotation.start(location))
+
+			if liveDescription == description {
+				// turns that are not a whole number of days can start at the same time of day on different days
+				liveDescription = liveLayer.RotationVirtualStart.In(location).Format(handoffDateFormat)
+				description = layer.Rotation.start(location).Format(handoffDateFormat)
+			}
+
+			builder.String(path+".handoff", liveDescription, description)
+		}
+
+		var liveUserIDs []string
+		for _, thisUser := range liveLayer.Users {
+			liveUserIDs = append(liveUserIDs, thisUser.ID)
+		}
+
+		builder.List(path+".users", liveUserIDs, diff.IDs(layer.UserIDs...))
+
+		var liveRestrictions []string
+		for _, thisRestriction := range liveLayer.Restrictions {
+			liveRestrictions = append(liveRestrictions, thisRestriction.String())
+		}
+
+		var restrictions []string
+		for _, thisRestriction := range buildRestrictions(layer.Restrictions) {
+			restrictions = append(restrictions, thisRestriction.String())
+		}
+
+		builder.Set(path+".restrictions", liveRestrictions, restrictions)
 	}
 
-	return &scheduleLayer{
-		Name:                      "Layer 1",
-		Start:                     time.Date(2021, time.July, 5, 11, 0, 0, 0, location),
-		RotationTurnLengthSeconds: rotationLengthSeconds,
-		Users:                     members,
+	return builder.Fields()
+}
+
+func (u *Manager) Delete(ctx context.Context, scheduleID string) error {
+	uri := fmt.Sprintf(getURI, scheduleID)
+
+	err := u.api.Delete(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to delete schedule '%s' with err: %s", scheduleID, err)
 	}
+
+	return nil
 }
 
 type ReqSchedule interface {
-	GetTeamName() string
+	GetScheduleName() string
 	GetDescription() string
 	GetResponderIDs() []string
 	GetLeadIDs() []string
 	GetTeamID() string
+	// GetRotation returns the settings of the rotation or nil for the default rotation
+	GetRotation() *Rotation
+	// GetLayers returns the layers of the schedule or nil for a single layer of the responders and leads
+	GetLayers() []*Layer
 }
 
 type getServiceResponse struct {
@@ -230,6 +299,7 @@ type getServiceResponse struct {
 }
 
 type getScheduleResponse struct {
+	pd.Pagination
 	Schedules []*Schedule `json:"schedules"`
 }
 
@@ -242,6 +312,30 @@ type Schedule struct {
 	ScheduleLayers []*scheduleLayer `json:"schedule_layers"`
 }
 
+// GetUserIDs returns the IDs of the users of the active layers of the schedule
+func (s *Schedule) GetUserIDs() []string {
+	var out []string
+
+	for _, layer := range activeLayers(s.ScheduleLayers) {
+		for _, thisUser := range layer.Users {
+			out = append(out, thisUser.ID)
+		}
+	}
+
+	return out
+}
+
+// GetTeamIDs returns the IDs of the teams the schedule belongs to
+func (s *Schedule) GetTeamIDs() []string {
+	var out []string
+
+	for _, thisTeam := range s.Teams {
+		out = append(out, thisTeam.ID)
+	}
+
+	return out
+}
+
 type user struct {
 	ID   string `json:"id"`
 	Type string `json:"type"`
@@ -258,6 +352,9 @@ type scheduleLayer struct {
 	RotationVirtualStart      time.Time `json:"rotation_virtual_start"`
 	RotationTurnLengthSeconds int       `json:"rotation_turn_length_seconds"`
 	Users                     []*user   `json:"users"`
+
+	Restrictions []*restriction `json:"restrictions"`
+	End          *time.Time     `json:"end,omitempty"`
 }
 
 type addRequest struct {
@@ -272,4 +369,8 @@ type Config interface {
 	Debug() bool
 	BaseURL() string
 	AuthToken() string
+	PageSize() int
+	MaxRetries() int
+	MaxBackoff() time.Duration
+	CaseInsensitiveNames() bool
 }
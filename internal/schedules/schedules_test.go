@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -110,6 +111,22 @@ func TestManager_GetByName(t *testing.T) {
 			expected:  nil,
 			expectErr: true,
 		},
+		{
+			desc: "sad path - partial name match only",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"schedules": [{"id": "A", "name": "B Platform"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			desc: "sad path - ambiguous name",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"schedules": [{"id": "A", "name": "B"}, {"id": "B", "name": "B"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
 	}
 
 	for _, s := range scenarios {
@@ -131,7 +148,69 @@ func TestManager_GetByName(t *testing.T) {
 
 			// call object under test
 			manager := New(cfg, logger)
-			result, resultErr := manager.GetByName(ctx, "BOOK")
+			result, resultErr := manager.GetByName(ctx, "B")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result, "expected result")
+		})
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*Schedule
+		expectErr             bool
+	}{
+		{
+			desc: "happy path - multiple pages",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("offset") == "" {
+					_, _ = resp.Write([]byte(`{"schedules": [{"id": "A"}], "more": true}`))
+					return
+				}
+
+				_, _ = resp.Write([]byte(`{"schedules": [{"id": "B"}], "more": false}`))
+			}),
+			expected: []*Schedule{
+				{ID: "A"},
+				{ID: "B"},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL:  testServer.URL,
+				pageSize: 1,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.List(ctx)
 
 			// validation
 			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
@@ -269,6 +348,237 @@ func TestManager_Update(t *testing.T) {
 	}
 }
 
+func TestManager_Diff(t *testing.T) {
+	melbourne, err := time.LoadLocation("Australia/Melbourne")
+	require.NoError(t, err)
+
+	handoff := time.Date(2021, time.July, 5, 11, 0, 0, 0, melbourne)
+
+	scenarios := []struct {
+		desc     string
+		live     *Schedule
+		rotation *Rotation
+		layers   []*Layer
+		expected []*diff.Field
+	}{
+		{
+			desc: "no differences",
+			live: &Schedule{
+				Name:        "A Schedule",
+				Description: "B",
+				TimeZone:    "Australia/Melbourne",
+				Teams:       []*team{{ID: "D"}},
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      handoff,
+						RotationTurnLengthSeconds: 604800,
+						Users:                     []*user{{ID: "E"}, {ID: "F"}},
+					},
+				},
+			},
+			expected: nil,
+		},
+		{
+			desc: "member and description changed",
+			live: &Schedule{
+				Name:        "A Schedule",
+				Description: "Old",
+				TimeZone:    "Australia/Melbourne",
+				Teams:       []*team{{ID: "D"}},
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      handoff,
+						RotationTurnLengthSeconds: 604800,
+						Users:                     []*user{{ID: "E"}, {ID: "G"}},
+					},
+				},
+			},
+			expected: []*diff.Field{
+				{Path: "description", Live: "Old", Desired: "B"},
+				{Path: "layers[Layer 1].users", Live: "E, G", Desired: "E, F"},
+			},
+		},
+		{
+			desc: "changed to a daily rotation in another time zone",
+			live: &Schedule{
+				Name:        "A Schedule",
+				Description: "B",
+				TimeZone:    "Australia/Melbourne",
+				Teams:       []*team{{ID: "D"}},
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      handoff,
+						RotationTurnLengthSeconds: 604800,
+						Users:                     []*user{{ID: "E"}, {ID: "F"}},
+					},
+				},
+			},
+			rotation: &Rotation{
+				Length:   24 * time.Hour,
+				TimeZone: "Europe/London",
+			},
+			expected: []*diff.Field{
+				{Path: "time_zone", Live: "Australia/Melbourne", Desired: "Europe/London"},
+				{Path: "layers[Layer 1].rotation_turn_length_seconds", Live: "604800", Desired: "86400"},
+				{Path: "layers[Layer 1].handoff", Live: "02:00", Desired: "00:00"},
+			},
+		},
+		{
+			desc: "handoff moved to Wednesday",
+			live: &Schedule{
+				Name:        "A Schedule",
+				Description: "B",
+				TimeZone:    "Australia/Melbourne",
+				Teams:       []*team{{ID: "D"}},
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      handoff,
+						RotationTurnLengthSeconds: 604800,
+						Users:                     []*user{{ID: "E"}, {ID: "F"}},
+					},
+				},
+			},
+			rotation: &Rotation{
+				Length:      7 * 24 * time.Hour,
+				HandoffDay:  time.Wednesday,
+				HandoffTime: 11 * time.Hour,
+				StartDate:   time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC),
+			},
+			expected: []*diff.Field{
+				{Path: "layers[Layer 1].handoff", Live: "Monday 11:00", Desired: "Wednesday 11:00"},
+			},
+		},
+		{
+			desc: "layer replaced at a later handoff",
+			live: &Schedule{
+				Name:        "A Schedule",
+				Description: "B",
+				TimeZone:    "Australia/Melbourne",
+				Teams:       []*team{{ID: "D"}},
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      handoff.AddDate(0, 0, 21),
+						RotationTurnLengthSeconds: 604800,
+						Users:                     []*user{{ID: "E"}, {ID: "F"}},
+					},
+				},
+			},
+			expected: nil,
+		},
+		{
+			desc: "12h rotation starting in the evening",
+			live: &Schedule{
+				Name:        "A Schedule",
+				Description: "B",
+				TimeZone:    "Australia/Melbourne",
+				Teams:       []*team{{ID: "D"}},
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      handoff.Add(12 * time.Hour),
+						RotationTurnLengthSeconds: 43200,
+						Users:                     []*user{{ID: "E"}, {ID: "F"}},
+					},
+				},
+			},
+			rotation: &Rotation{
+				Length:      12 * time.Hour,
+				HandoffTime: 11 * time.Hour,
+				StartDate:   time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC),
+			},
+			expected: nil,
+		},
+		{
+			desc: "5h rotation starting on another day",
+			live: &Schedule{
+				Name:        "A Schedule",
+				Description: "B",
+				TimeZone:    "Australia/Melbourne",
+				Teams:       []*team{{ID: "D"}},
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      handoff.AddDate(0, 0, 1),
+						RotationTurnLengthSeconds: 18000,
+						Users:                     []*user{{ID: "E"}, {ID: "F"}},
+					},
+				},
+			},
+			rotation: &Rotation{
+				Length:      5 * time.Hour,
+				HandoffTime: 11 * time.Hour,
+				StartDate:   time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC),
+			},
+			expected: []*diff.Field{
+				{Path: "layers[Layer 1].handoff", Live: "Tue 2021-07-06 11:00", Desired: "Mon 2021-07-05 11:00"},
+			},
+		},
+		{
+			desc: "business hours layer added and layer 1 removed",
+			live: &Schedule{
+				Name:        "A Schedule",
+				Description: "B",
+				TimeZone:    "Australia/Melbourne",
+				Teams:       []*team{{ID: "D"}},
+				ScheduleLayers: []*scheduleLayer{
+					{
+						Name:                      "Layer 1",
+						RotationVirtualStart:      handoff,
+						RotationTurnLengthSeconds: 604800,
+						Users:                     []*user{{ID: "E"}, {ID: "F"}},
+					},
+				},
+			},
+			layers: []*Layer{
+				{
+					Name:    "Business hours",
+					UserIDs: []string{"E"},
+					Restrictions: []*Restriction{
+						{Weekly: true, StartDay: time.Monday, StartTime: 9 * time.Hour, Duration: 8 * time.Hour},
+					},
+				},
+			},
+			expected: []*diff.Field{
+				{Path: "layers", Live: "Layer 1", Desired: "Business hours"},
+				{Path: "layers[Business hours].rotation_turn_length_seconds", Live: "0", Desired: "604800"},
+				{Path: "layers[Business hours].handoff", Live: "", Desired: "Monday 11:00"},
+				{Path: "layers[Business hours].users", Live: "", Desired: "E"},
+				{Path: "layers[Business hours].restrictions", Live: "", Desired: "weekly from day 1 09:00:00 for 28800s"},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			newSchedule := &testSchedule{
+				name:         "A",
+				description:  "B",
+				teamID:       "D",
+				responderIDs: []string{"E"},
+				leadIDs:      []string{"F"},
+				rotation:     scenario.rotation,
+				layers:       scenario.layers,
+			}
+
+			// call object under test
+			manager := New(&testConfig{}, logger)
+			result := manager.Diff(scenario.live, newSchedule, "Australia/Melbourne")
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
 type testSchedule struct {
 	name         string
 	description  string
@@ -276,6 +586,8 @@ type testSchedule struct {
 	teamID       string
 	responderIDs []string
 	leadIDs      []string
+	rotation     *Rotation
+	layers       []*Layer
 }
 
 func (t *testSchedule) GetResponderIDs() []string {
@@ -290,8 +602,8 @@ func (t *testSchedule) GetTimeZone() string {
 	return t.timeZone
 }
 
-func (t *testSchedule) GetTeamName() string {
-	return t.name
+func (t *testSchedule) GetScheduleName() string {
+	return t.name + " Schedule"
 }
 
 func (t *testSchedule) GetDescription() string {
@@ -302,8 +614,20 @@ func (t *testSchedule) GetTeamID() string {
 	return t.teamID
 }
 
+func (t *testSchedule) GetRotation() *Rotation {
+	return t.rotation
+}
+
+func (t *testSchedule) GetLayers() []*Layer {
+	return t.layers
+}
+
 type testConfig struct {
-	baseURL string
+	baseURL         string
+	pageSize        int
+	caseInsensitive bool
+	maxRetries      int
+	maxBackoff      time.Duration
 }
 
 func (t *testConfig) AuthToken() string {
@@ -318,6 +642,22 @@ func (t *testConfig) BaseURL() string {
 	return t.baseURL
 }
 
+func (t *testConfig) PageSize() int {
+	return t.pageSize
+}
+
+func (t *testConfig) MaxRetries() int {
+	return t.maxRetries
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return t.maxBackoff
+}
+
+func (t *testConfig) CaseInsensitiveNames() bool {
+	return t.caseInsensitive
+}
+
 var getHappyPathResponse = `
 {
   "schedule": {
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"sort"
+)
+
+const (
+	fieldsURI      = "/services/custom_fields"
+	fieldValuesURI = "/services/%s/custom_fields/values"
+
+	fieldDataTypeURL     = "url"
+	fieldTypeSingleValue = "single_value"
+)
+
+// ListFields returns the service custom fields of the account
+func (u *Manager) ListFields(ctx context.Context) ([]*Field, error) {
+	respDTO := &listFieldsResponse{}
+
+	err := u.api.Get(ctx, fieldsURI, nil, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list service custom fields with err: %s", err)
+	}
+
+	return respDTO.Fields, nil
+}
+
+// AddURLField creates a service custom field that holds a URL and returns it
+func (u *Manager) AddURLField(ctx context.Context, name, displayName, description string) (*Field, error) {
+	reqDTO := &fieldRequest{
+		Field: &Field{
+			Name:        name,
+			DisplayName: displayName,
+			Description: description,
+			DataType:    fieldDataTypeURL,
+			FieldType:   fieldTypeSingleValue,
+			Enabled:     true,
+		},
+	}
+
+	respDTO := &fieldRequest{}
+
+	err := u.api.Post(ctx, fieldsURI, reqDTO, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add service custom field '%s' with err: %s", name, err)
+	}
+
+	return respDTO.Field, nil
+}
+
+// GetFieldValues returns the values of the custom fields of the service keyed by field ID
+func (u *Manager) GetFieldValues(ctx context.Context, serviceID string) (map[string]string, error) {
+	uri := fmt.Sprintf(fieldValuesURI, serviceID)
+
+	respDTO := &fieldValuesRequest{}
+
+	err := u.api.Get(ctx, uri, nil, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get custom field values of service '%s' with err: %s", serviceID, err)
+	}
+
+	out := map[string]string{}
+
+	for _, value := range respDTO.CustomFields {
+		if value.Value != nil {
+			out[value.ID] = *value.Value
+		}
+	}
+
+	return out, nil
+}
+
+// SetFieldValues sets the values of the custom fields of the service (keyed by field ID); empty values are cleared
+func (u *Manager) SetFieldValues(ctx context.Context, serviceID string, values map[string]string) error {
+	uri := fmt.Sprintf(fieldValuesURI, serviceID)
+
+	var fieldIDs []string
+	for fieldID := range values {
+		fieldIDs = append(fieldIDs, fieldID)
+	}
+
+	sort.Strings(fieldIDs)
+
+	reqDTO := &fieldValuesRequest{}
+
+	for _, fieldID := range fieldIDs {
+		fieldValue := &FieldValue{ID: fieldID}
+
+		if value := values[fieldID]; value != "" {
+			thisValue := value
+			fieldValue.Value = &thisValue
+		}
+
+		reqDTO.CustomFields = append(reqDTO.CustomFields, fieldValue)
+	}
+
+	err := u.api.Put(ctx, uri, reqDTO)
+	if err != nil {
+		return fmt.Errorf("failed to set custom field values of service '%s' with err: %s", serviceID, err)
+	}
+
+	return nil
+}
+
+// Field is a service custom field; their values are shown on the service and on its incidents
+type Field struct {
+	ID          string `json:"id,omitempty"`
+	Name        string `json:"name"`
+	DisplayName string `json:"display_name"`
+	Description string `json:"description,omitempty"`
+	DataType    string `json:"data_type"`
+	FieldType   string `json:"field_type"`
+	Enabled     bool   `json:"enabled"`
+}
+
+// FieldValue is the value of a custom field of a service (nil when it is not set)
+type FieldValue struct {
+	ID    string  `json:"id"`
+	Value *string `json:"value"`
+}
+
+type listFieldsResponse struct {
+	Fields []*Field `json:"fields"`
+}
+
+type fieldRequest struct {
+	Field *Field `json:"field"`
+}
+
+type fieldValuesRequest struct {
+	CustomFields []*FieldValue `json:"custom_fields"`
+}
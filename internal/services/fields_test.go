@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_GetFieldValues(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              map[string]string
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"custom_fields": [
+					{"id": "RUNBOOK", "name": "runbook", "value": "https://wiki.example.com/booking"},
+					{"id": "REPO", "name": "repo", "value": null}
+				]}`))
+			}),
+			expected:  map[string]string{"RUNBOOK": "https://wiki.example.com/booking"},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.GetFieldValues(ctx, "BOOK")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestManager_SetFieldValues(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	values := map[string]string{
+		"RUNBOOK":   "https://wiki.example.com/booking",
+		"DASHBOARD": "",
+	}
+
+	// mocks
+	var request string
+
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		payload, _ := ioutil.ReadAll(req.Body)
+		request = req.Method + " " + req.URL.Path + " " + string(payload)
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		baseURL: testServer.URL,
+	}
+
+	// call object under test
+	manager := New(cfg, logger)
+	resultErr := manager.SetFieldValues(ctx, "BOOK", values)
+
+	// validation
+	require.NoError(t, resultErr)
+	assert.Equal(t, `PUT /services/BOOK/custom_fields/values {"custom_fields":[{"id":"DASHBOARD","value":null},{"id":"RUNBOOK","value":"https://wiki.example.com/booking"}]}`, request,
+		"empty values are cleared")
+}
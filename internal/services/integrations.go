@@ -0,0 +1,190 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/corsc/pagerduty-manager/internal/pd"
+)
+
+const (
+	integrationsURI = "/services/%s/integrations"
+	integrationURI  = "/services/%s/integrations/%s"
+	vendorsURI      = "/vendors"
+)
+
+// PagerDuty integration types
+const (
+	IntegrationEventsAPIV2 = "events_api_v2_inbound_integration"
+	IntegrationEmail       = "generic_email_inbound_integration"
+)
+
+var ErrNoSuchVendor = errors.New("no such vendor")
+
+// GetIntegrations returns the integrations (with their keys) of the service
+func (u *Manager) GetIntegrations(ctx context.Context, serviceID string) ([]*Integration, error) {
+	uri := fmt.Sprintf(getURI, serviceID)
+
+	params := url.Values{}
+	params.Set("include[]", "integrations")
+
+	respDTO := &getServiceResponse{}
+
+	err := u.api.Get(ctx, uri, params, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get integrations of service '%s' with err: %s", serviceID, err)
+	}
+
+	if respDTO.Service == nil {
+		return nil, ErrNoSuchService
+	}
+
+	return respDTO.Service.Integrations, nil
+}
+
+// AddIntegration creates the integration on the service and returns it (including the integration key)
+func (u *Manager) AddIntegration(ctx context.Context, serviceID string, integration ReqIntegration) (*Integration, error) {
+	uri := fmt.Sprintf(integrationsURI, serviceID)
+
+	reqDTO := &integrationRequest{
+		Integration: &Integration{
+			Type:             integration.GetType(),
+			Name:             integration.GetName(),
+			IntegrationEmail: integration.GetEmail(),
+		},
+	}
+
+	if integration.GetVendorID() != "" {
+		reqDTO.Integration.Vendor = &reference{
+			ID:   integration.GetVendorID(),
+			Type: "vendor_reference",
+		}
+	}
+
+	respDTO := &integrationRequest{}
+
+	err := u.api.Post(ctx, uri, reqDTO, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add integration '%s' to service '%s' with err: %s", integration.GetName(), serviceID, err)
+	}
+
+	return respDTO.Integration, nil
+}
+
+// RenameIntegration changes the name of the integration (PagerDuty does not allow integrations to be deleted with the API)
+func (u *Manager) RenameIntegration(ctx context.Context, serviceID string, integration *Integration, name string) error {
+	uri := fmt.Sprintf(integrationURI, serviceID, integration.ID)
+
+	reqDTO := &integrationRequest{
+		Integration: &Integration{
+			Type: integration.Type,
+			Name: name,
+		},
+	}
+
+	err := u.api.Put(ctx, uri, reqDTO)
+	if err != nil {
+		return fmt.Errorf("failed to rename integration '%s' of service '%s' with err: %s", integration.ID, serviceID, err)
+	}
+
+	return nil
+}
+
+// GetVendorID returns the ID of the vendor (e.g. Prometheus or Datadog) with the name (ignoring case)
+func (u *Manager) GetVendorID(ctx context.Context, name string) (string, error) {
+	params := url.Values{}
+	params.Set("query", name)
+
+	var vendorID string
+
+	err := u.api.List(ctx, vendorsURI, params,
+		func() pd.Page {
+			return &listVendorsResponse{}
+		},
+		func(page pd.Page) {
+			for _, vendor := range page.(*listVendorsResponse).Vendors {
+				if strings.EqualFold(vendor.Name, name) {
+					vendorID = vendor.ID
+				}
+			}
+		})
+	if err != nil {
+		return "", fmt.Errorf("failed to get vendor '%s' with err: %s", name, err)
+	}
+
+	if vendorID == "" {
+		return "", fmt.Errorf("%w: '%s'", ErrNoSuchVendor, name)
+	}
+
+	return vendorID, nil
+}
+
+// FindIntegration returns the live integration with the name (or nil)
+func FindIntegration(live []*Integration, name string) *Integration {
+	for _, integration := range live {
+		if integration.Name == name {
+			return integration
+		}
+	}
+
+	return nil
+}
+
+type ReqIntegration interface {
+	GetName() string
+	GetType() string
+	// GetVendorID returns the vendor of the integration (or empty for generic integrations)
+	GetVendorID() string
+	// GetEmail returns the address of email integrations
+	GetEmail() string
+}
+
+type Integration struct {
+	ID               string     `json:"id,omitempty"`
+	Type             string     `json:"type"`
+	Name             string     `json:"name"`
+	IntegrationKey   string     `json:"integration_key,omitempty"`
+	IntegrationEmail string     `json:"integration_email,omitempty"`
+	Vendor           *reference `json:"vendor,omitempty"`
+}
+
+// GetVendorID returns the ID of the vendor of the integration (or empty)
+func (i *Integration) GetVendorID() string {
+	if i.Vendor == nil {
+		return ""
+	}
+
+	return i.Vendor.ID
+}
+
+// GetVendorName returns the name of the vendor of the integration (or empty)
+func (i *Integration) GetVendorName() string {
+	if i.Vendor == nil {
+		return ""
+	}
+
+	return i.Vendor.Summary
+}
+
+type reference struct {
+	ID      string `json:"id"`
+	Type    string `json:"type,omitempty"`
+	Summary string `json:"summary,omitempty"`
+}
+
+type integrationRequest struct {
+	Integration *Integration `json:"integration"`
+}
+
+type listVendorsResponse struct {
+	pd.Pagination
+	Vendors []*vendor `json:"vendors"`
+}
+
+type vendor struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
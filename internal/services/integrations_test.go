@@ -0,0 +1,322 @@
+package services
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_GetIntegrations(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*Integration
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "integrations", req.URL.Query().Get("include[]"))
+
+				_, _ = resp.Write([]byte(getIntegrationsHappyPathResponse))
+			}),
+			expected: []*Integration{
+				{
+					ID:             "INT",
+					Type:           IntegrationEventsAPIV2,
+					Name:           "Alertmanager",
+					IntegrationKey: "KEY",
+					Vendor:         &reference{ID: "PROM", Type: "vendor_reference"},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.GetIntegrations(ctx, "BOOK")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestManager_RenameIntegration(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				payload, _ := ioutil.ReadAll(req.Body)
+				if req.Method != http.MethodPut || req.URL.Path != "/services/BOOK/integrations/INT" ||
+					!strings.Contains(string(payload), `"name":"Alertmanager (replaced)"`) {
+					resp.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				resp.WriteHeader(http.StatusOK)
+			}),
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			integration := &Integration{
+				ID:   "INT",
+				Type: IntegrationEventsAPIV2,
+				Name: "Alertmanager",
+			}
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			resultErr := manager.RenameIntegration(ctx, "BOOK", integration, "Alertmanager (replaced)")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+		})
+	}
+}
+
+func TestManager_AddIntegration(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              *Integration
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusCreated)
+				_, _ = resp.Write([]byte(addIntegrationHappyPathResponse))
+			}),
+			expected: &Integration{
+				ID:             "INT",
+				Type:           IntegrationEventsAPIV2,
+				Name:           "Alertmanager",
+				IntegrationKey: "KEY",
+				Vendor:         &reference{ID: "PROM", Type: "vendor_reference"},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			integration := &Integration{
+				Type:   IntegrationEventsAPIV2,
+				Name:   "Alertmanager",
+				Vendor: &reference{ID: "PROM"},
+			}
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.AddIntegration(ctx, "BOOK", &testIntegration{integration})
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestManager_GetVendorID(t *testing.T) {
+	scenarios := []struct {
+		desc      string
+		in        string
+		expected  string
+		expectErr bool
+	}{
+		{
+			desc:      "happy path",
+			in:        "prometheus",
+			expected:  "PROM",
+			expectErr: false,
+		},
+		{
+			desc:      "sad path - partial name match only",
+			in:        "Prom",
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(listVendorsHappyPathResponse))
+			}))
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.GetVendorID(ctx, scenario.in)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+type testIntegration struct {
+	*Integration
+}
+
+func (t *testIntegration) GetName() string {
+	return t.Name
+}
+
+func (t *testIntegration) GetType() string {
+	return t.Type
+}
+
+func (t *testIntegration) GetEmail() string {
+	return t.IntegrationEmail
+}
+
+var getIntegrationsHappyPathResponse = `
+{
+  "service": {
+    "id": "BOOK",
+    "name": "The Booking Service",
+    "integrations": [
+      {
+        "id": "INT",
+        "type": "events_api_v2_inbound_integration",
+        "name": "Alertmanager",
+        "integration_key": "KEY",
+        "vendor": {
+          "id": "PROM",
+          "type": "vendor_reference"
+        }
+      }
+    ]
+  }
+}
+`
+
+var addIntegrationHappyPathResponse = `
+{
+  "integration": {
+    "id": "INT",
+    "type": "events_api_v2_inbound_integration",
+    "name": "Alertmanager",
+    "integration_key": "KEY",
+    "vendor": {
+      "id": "PROM",
+      "type": "vendor_reference"
+    }
+  }
+}
+`
+
+var listVendorsHappyPathResponse = `
+{
+  "vendors": [
+    {
+      "id": "PROMX",
+      "name": "Prometheus Exporter"
+    },
+    {
+      "id": "PROM",
+      "name": "Prometheus"
+    }
+  ],
+  "more": false
+}
+`
@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
 	"github.com/corsc/pagerduty-manager/internal/pd"
 
 	"go.uber.org/zap"
@@ -16,9 +18,15 @@ const (
 	listURI   = "/services"
 	addURI    = "/services"
 	updateURI = "/services/%s"
+
+	statusActive   = "active"
+	statusDisabled = "disabled"
 )
 
-var ErrNoSuchService = errors.New("no such service")
+var (
+	ErrNoSuchService    = errors.New("no such service")
+	ErrAmbiguousService = errors.New("more than one service matches")
+)
 
 func New(cfg Config, logger *zap.Logger) *Manager {
 	return &Manager{
@@ -52,24 +60,56 @@ func (u *Manager) Get(ctx context.Context, serviceID string) (*Service, error) {
 	return services.Service, nil
 }
 
+// GetByName returns the service with exactly the supplied name
 func (u *Manager) GetByName(ctx context.Context, name string) (*Service, error) {
 	params := url.Values{}
 	params.Set("query", name)
-	params.Set("total", "false")
-	params.Set("limit", "1")
 
-	services := &getServicesResponse{}
+	var matches []*Service
 
-	err := u.api.Get(ctx, listURI, params, services)
+	err := u.api.List(ctx, listURI, params,
+		func() pd.Page {
+			return &getServicesResponse{}
+		},
+		func(page pd.Page) {
+			for _, service := range page.(*getServicesResponse).Service {
+				if pd.NamesMatch(name, service.Name, u.cfg.CaseInsensitiveNames()) {
+					matches = append(matches, service)
+				}
+			}
+		})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get services '%s' with err: %s", name, err)
 	}
 
-	if len(services.Service) == 0 {
+	switch len(matches) {
+	case 0:
 		return nil, ErrNoSuchService
+
+	case 1:
+		return matches[0], nil
+
+	default:
+		return nil, fmt.Errorf("%w: %d services named '%s'", ErrAmbiguousService, len(matches), name)
+	}
+}
+
+// List returns every service in the account
+func (u *Manager) List(ctx context.Context) ([]*Service, error) {
+	var out []*Service
+
+	err := u.api.List(ctx, listURI, nil,
+		func() pd.Page {
+			return &getServicesResponse{}
+		},
+		func(page pd.Page) {
+			out = append(out, page.(*getServicesResponse).Service...)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services with err: %s", err)
 	}
 
-	return services.Service[0], nil
+	return out, nil
 }
 
 func (u *Manager) Add(ctx context.Context, service NewService, team NewTeam) (string, error) {
@@ -86,11 +126,11 @@ func (u *Manager) Add(ctx context.Context, service NewService, team NewTeam) (st
 }
 
 func (u *Manager) buildAddPayload(service NewService, team NewTeam) *addRequest {
-	return &addRequest{
+	reqDTO := &addRequest{
 		Service: &Service{
 			Name:        service.GetName(),
 			Description: service.GetDescription(),
-			Status:      "active",
+			Status:      statusActive,
 			EscalationPolicy: &EscalationPolicy{
 				ID:   team.GetEscalationPolicyID(),
 				Type: "escalation_policy_reference",
@@ -100,16 +140,13 @@ func (u *Manager) buildAddPayload(service NewService, team NewTeam) *addRequest
 					ID: team.GetTeamID(),
 				},
 			},
-			IncidentUrgencyRule: &IncidentUrgency{
-				Type:    "constant",
-				Urgency: "high",
-			},
 			AlertCreation: "create_alerts_and_incidents",
-			AlertGroupingParameters: &AlertGroupParameters{
-				Type: "intelligent",
-			},
 		},
 	}
+
+	applySettings(reqDTO.Service, getSettings(service))
+
+	return reqDTO
 }
 
 func (u *Manager) Update(ctx context.Context, serviceID string, service NewService, team NewTeam) error {
@@ -127,9 +164,117 @@ func (u *Manager) Update(ctx context.Context, serviceID string, service NewServi
 	return nil
 }
 
+// Disable stops the service from creating incidents without deleting it (or its history)
+func (u *Manager) Disable(ctx context.Context, serviceID string) error {
+	reqDTO := &statusRequest{
+		Service: &serviceStatus{
+			Type:   "service",
+			Status: statusDisabled,
+		},
+	}
+
+	uri := fmt.Sprintf(updateURI, serviceID)
+
+	err := u.api.Put(ctx, uri, reqDTO)
+	if err != nil {
+		return fmt.Errorf("failed to disable service '%s' with err: %s", serviceID, err)
+	}
+
+	return nil
+}
+
+// enabledStatus returns the status of the service as it is set; PagerDuty reports the status of an enabled service as
+// warning or critical while it has open incidents, which must not be reported as a difference
+func enabledStatus(status string) string {
+	if status == statusDisabled {
+		return statusDisabled
+	}
+
+	return statusActive
+}
+
+// Diff returns the differences between the live service and the requested service
+func (u *Manager) Diff(live *Service, service NewService, team NewTeam) []*diff.Field {
+	desired := u.buildAddPayload(service, team).Service
+
+	builder := &diff.Builder{}
+	builder.String("name", live.Name, desired.Name)
+	builder.String("description", live.Description, desired.Description)
+	builder.String("status", enabledStatus(live.Status), desired.Status)
+
+	liveEscalation := &EscalationPolicy{}
+	if live.EscalationPolicy != nil {
+		liveEscalation = live.EscalationPolicy
+	}
+
+	builder.String("escalation_policy", liveEscalation.ID, diff.IDs(desired.EscalationPolicy.ID)[0])
+
+	var liveTeamIDs []string
+	for _, thisTeam := range live.Teams {
+		liveTeamIDs = append(liveTeamIDs, thisTeam.ID)
+	}
+
+	builder.Set("teams", liveTeamIDs, diff.IDs(team.GetTeamID()))
+
+	liveUrgency := &IncidentUrgency{}
+	if live.IncidentUrgencyRule != nil {
+		liveUrgency = live.IncidentUrgencyRule
+	}
+
+	builder.String("incident_urgency_rule.type", liveUrgency.Type, desired.IncidentUrgencyRule.Type)
+	builder.String("incident_urgency_rule.urgency", liveUrgency.Urgency, desired.IncidentUrgencyRule.Urgency)
+
+	if desired.SupportHours != nil {
+		builder.String("incident_urgency_rule.during_support_hours.urgency",
+			liveUrgency.DuringSupportHours.getUrgency(), desired.IncidentUrgencyRule.DuringSupportHours.getUrgency())
+		builder.String("incident_urgency_rule.outside_support_hours.urgency",
+			liveUrgency.OutsideSupportHours.getUrgency(), desired.IncidentUrgencyRule.OutsideSupportHours.getUrgency())
+		builder.String("support_hours", live.SupportHours.String(), desired.SupportHours.String())
+	}
+
+	builder.String("alert_creation", live.AlertCreation, desired.AlertCreation)
+
+	liveGrouping := &AlertGroupParameters{}
+	if live.AlertGroupingParameters != nil {
+		liveGrouping = live.AlertGroupingParameters
+	}
+
+	builder.String("alert_grouping_parameters.type", liveGrouping.Type, desired.AlertGroupingParameters.Type)
+
+	if desired.AlertGroupingParameters.Config != nil {
+		liveConfig := &alertGroupingConfig{}
+		if liveGrouping.Config != nil {
+			liveConfig = liveGrouping.Config
+		}
+
+		builder.Int("alert_grouping_parameters.config.timeout", liveConfig.Timeout, desired.AlertGroupingParameters.Config.Timeout)
+	}
+
+	// timeouts are only compared when they are set in the config
+	if desired.AcknowledgementTimeout != nil {
+		builder.Int("acknowledgement_timeout", intValue(live.AcknowledgementTimeout), *desired.AcknowledgementTimeout)
+	}
+
+	if desired.AutoResolveTimeout != nil {
+		builder.Int("auto_resolve_timeout", intValue(live.AutoResolveTimeout), *desired.AutoResolveTimeout)
+	}
+
+	return builder.Fields()
+}
+
+func intValue(value *int) int {
+	if value == nil {
+		return 0
+	}
+
+	return *value
+}
+
 type NewService interface {
 	GetName() string
 	GetDescription() string
+	// GetSettings returns the settings of the service or nil for the defaults (see DefaultSettings)
+	GetSettings() *Settings
 }
 
 type NewTeam interface {
@@ -142,6 +287,7 @@ type getServiceResponse struct {
 }
 
 type getServicesResponse struct {
+	pd.Pagination
 	Service []*Service `json:"services"`
 }
 
@@ -156,6 +302,10 @@ type Service struct {
 	IncidentUrgencyRule     *IncidentUrgency      `json:"incident_urgency_rule"`
 	AlertCreation           string                `json:"alert_creation"`
 	AlertGroupingParameters *AlertGroupParameters `json:"alert_grouping_parameters"`
+	SupportHours            *supportHoursDTO      `json:"support_hours,omitempty"`
+	AcknowledgementTimeout  *int                  `json:"acknowledgement_timeout,omitempty"`
+	AutoResolveTimeout      *int                  `json:"auto_resolve_timeout,omitempty"`
+	Integrations            []*Integration        `json:"integrations,omitempty"`
 }
 
 type EscalationPolicy struct {
@@ -169,17 +319,38 @@ type Team struct {
 
 type IncidentUrgency struct {
 	Type    string `json:"type"`
-	Urgency string `json:"urgency"`
+	Urgency string `json:"urgency,omitempty"`
+
+	DuringSupportHours  *IncidentUrgency `json:"during_support_hours,omitempty"`
+	OutsideSupportHours *IncidentUrgency `json:"outside_support_hours,omitempty"`
+}
+
+func (i *IncidentUrgency) getUrgency() string {
+	if i == nil {
+		return ""
+	}
+
+	return i.Urgency
 }
 
 type AlertGroupParameters struct {
-	Type string `json:"type"`
+	Type   string               `json:"type"`
+	Config *alertGroupingConfig `json:"config,omitempty"`
 }
 
 type addRequest struct {
 	Service *Service `json:"service"`
 }
 
+type statusRequest struct {
+	Service *serviceStatus `json:"service"`
+}
+
+type serviceStatus struct {
+	Type   string `json:"type"`
+	Status string `json:"status"`
+}
+
 type addResponse struct {
 	Service *Service `json:"service"`
 }
@@ -188,4 +359,8 @@ type Config interface {
 	Debug() bool
 	BaseURL() string
 	AuthToken() string
+	PageSize() int
+	MaxRetries() int
+	MaxBackoff() time.Duration
+	CaseInsensitiveNames() bool
 }
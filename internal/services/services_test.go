@@ -2,12 +2,15 @@ package services
 
 import (
 	"context"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -97,6 +100,22 @@ func TestManager_GetByName(t *testing.T) {
 			expected:  nil,
 			expectErr: true,
 		},
+		{
+			desc: "sad path - partial name match only",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"services": [{"id": "A", "name": "The Booking Policy Platform"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			desc: "sad path - ambiguous name",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"services": [{"id": "A", "name": "The Booking Policy"}, {"id": "B", "name": "The Booking Policy"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
 	}
 
 	for _, s := range scenarios {
@@ -118,7 +137,69 @@ func TestManager_GetByName(t *testing.T) {
 
 			// call object under test
 			manager := New(cfg, logger)
-			result, resultErr := manager.GetByName(ctx, "BOOK")
+			result, resultErr := manager.GetByName(ctx, "The Booking Policy")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result, "expected result")
+		})
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*Service
+		expectErr             bool
+	}{
+		{
+			desc: "happy path - multiple pages",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("offset") == "" {
+					_, _ = resp.Write([]byte(`{"services": [{"id": "A"}], "more": true}`))
+					return
+				}
+
+				_, _ = resp.Write([]byte(`{"services": [{"id": "B"}], "more": false}`))
+			}),
+			expected: []*Service{
+				{ID: "A"},
+				{ID: "B"},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL:  testServer.URL,
+				pageSize: 1,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.List(ctx)
 
 			// validation
 			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
@@ -191,9 +272,182 @@ func TestManager_Add(t *testing.T) {
 	}
 }
 
+func TestManager_Disable(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				payload, _ := ioutil.ReadAll(req.Body)
+				if !strings.Contains(string(payload), `"status":"disabled"`) {
+					resp.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				resp.WriteHeader(http.StatusOK)
+			}),
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			resultErr := manager.Disable(ctx, "PIPELINE")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+		})
+	}
+}
+
+func TestManager_Diff(t *testing.T) {
+	scenarios := []struct {
+		desc     string
+		live     *Service
+		settings *Settings
+		expected []*diff.Field
+	}{
+		{
+			desc: "no differences",
+			live: &Service{
+				Name:                    "A",
+				Description:             "B",
+				Status:                  "active",
+				EscalationPolicy:        &EscalationPolicy{ID: "D"},
+				Teams:                   []*Team{{ID: "C"}},
+				IncidentUrgencyRule:     &IncidentUrgency{Type: "constant", Urgency: "high"},
+				AlertCreation:           "create_alerts_and_incidents",
+				AlertGroupingParameters: &AlertGroupParameters{Type: "intelligent"},
+			},
+			expected: nil,
+		},
+		{
+			desc: "open incidents are not a difference",
+			live: &Service{
+				Name:                    "A",
+				Description:             "B",
+				Status:                  "critical",
+				EscalationPolicy:        &EscalationPolicy{ID: "D"},
+				Teams:                   []*Team{{ID: "C"}},
+				IncidentUrgencyRule:     &IncidentUrgency{Type: "constant", Urgency: "high"},
+				AlertCreation:           "create_alerts_and_incidents",
+				AlertGroupingParameters: &AlertGroupParameters{Type: "intelligent"},
+			},
+			expected: nil,
+		},
+		{
+			desc: "changed by hand",
+			live: &Service{
+				Name:                "A",
+				Description:         "B",
+				Status:              "disabled",
+				EscalationPolicy:    &EscalationPolicy{ID: "X"},
+				Teams:               []*Team{{ID: "C"}},
+				IncidentUrgencyRule: &IncidentUrgency{Type: "constant", Urgency: "high"},
+				AlertCreation:       "create_alerts_and_incidents",
+			},
+			expected: []*diff.Field{
+				{Path: "status", Live: "disabled", Desired: "active"},
+				{Path: "escalation_policy", Live: "X", Desired: "D"},
+				{Path: "alert_grouping_parameters.type", Live: "", Desired: "intelligent"},
+			},
+		},
+		{
+			desc: "support hours, time grouping and timeouts",
+			live: &Service{
+				Name:                    "A",
+				Description:             "B",
+				Status:                  "active",
+				EscalationPolicy:        &EscalationPolicy{ID: "D"},
+				Teams:                   []*Team{{ID: "C"}},
+				IncidentUrgencyRule:     &IncidentUrgency{Type: "constant", Urgency: "high"},
+				AlertCreation:           "create_alerts_and_incidents",
+				AlertGroupingParameters: &AlertGroupParameters{Type: "intelligent"},
+			},
+			settings: &Settings{
+				Urgency: UrgencyHigh,
+				SupportHours: &SupportHours{
+					TimeZone: "Asia/Singapore",
+					Days:     []time.Weekday{time.Monday, time.Friday, time.Sunday},
+					Start:    9 * time.Hour,
+					End:      17*time.Hour + 30*time.Minute,
+				},
+				OutsideUrgency:         UrgencyLow,
+				AlertGrouping:          GroupingTime,
+				AlertGroupingTimeout:   15 * time.Minute,
+				AcknowledgementTimeout: 30 * time.Minute,
+			},
+			expected: []*diff.Field{
+				{Path: "incident_urgency_rule.type", Live: "constant", Desired: "use_support_hours"},
+				{Path: "incident_urgency_rule.urgency", Live: "high", Desired: ""},
+				{Path: "incident_urgency_rule.during_support_hours.urgency", Live: "", Desired: "high"},
+				{Path: "incident_urgency_rule.outside_support_hours.urgency", Live: "", Desired: "low"},
+				{Path: "support_hours", Live: "", Desired: "days 1,5,7 from 09:00:00 to 17:30:00 Asia/Singapore"},
+				{Path: "alert_grouping_parameters.type", Live: "intelligent", Desired: "time"},
+				{Path: "alert_grouping_parameters.config.timeout", Live: "0", Desired: "15"},
+				{Path: "acknowledgement_timeout", Live: "0", Desired: "1800"},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			newService := &testService{
+				name:        "A",
+				description: "B",
+				settings:    scenario.settings,
+			}
+
+			newTeam := &testTeam{
+				teamID:             "C",
+				escalationPolicyID: "D",
+			}
+
+			// call object under test
+			manager := New(&testConfig{}, logger)
+			result := manager.Diff(scenario.live, newService, newTeam)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
 type testService struct {
 	name        string
 	description string
+	settings    *Settings
 }
 
 func (t *testService) GetName() string {
@@ -204,6 +458,10 @@ func (t *testService) GetDescription() string {
 	return t.description
 }
 
+func (t *testService) GetSettings() *Settings {
+	return t.settings
+}
+
 type testTeam struct {
 	teamID             string
 	escalationPolicyID string
@@ -218,7 +476,11 @@ func (t *testTeam) GetTeamID() string {
 }
 
 type testConfig struct {
-	baseURL string
+	baseURL         string
+	pageSize        int
+	caseInsensitive bool
+	maxRetries      int
+	maxBackoff      time.Duration
 }
 
 func (t *testConfig) AuthToken() string {
@@ -233,6 +495,22 @@ func (t *testConfig) BaseURL() string {
 	return t.baseURL
 }
 
+func (t *testConfig) PageSize() int {
+	return t.pageSize
+}
+
+func (t *testConfig) MaxRetries() int {
+	return t.maxRetries
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return t.maxBackoff
+}
+
+func (t *testConfig) CaseInsensitiveNames() bool {
+	return t.caseInsensitive
+}
+
 var getHappyPathResponse = `
 {
  "service": {
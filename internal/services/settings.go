@@ -0,0 +1,162 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Urgencies
+const (
+	UrgencyHigh = "high"
+	UrgencyLow  = "low"
+)
+
+// Alert grouping types
+const (
+	GroupingIntelligent = "intelligent"
+	GroupingTime        = "time"
+)
+
+const (
+	urgencyConstant        = "constant"
+	urgencySupportHours    = "use_support_hours"
+	supportHoursFixedTimes = "fixed_time_per_day"
+)
+
+// Settings are how the service creates incidents
+type Settings struct {
+	// Urgency of incidents (during support hours when there are support hours)
+	Urgency string
+	// SupportHours are optional; outside of them incidents have the OutsideUrgency
+	SupportHours   *SupportHours
+	OutsideUrgency string
+	// AlertGrouping is intelligent or time
+	AlertGrouping string
+	// AlertGroupingTimeout is how long alerts are grouped for (time grouping only); zero groups until the incident is resolved
+	AlertGroupingTimeout time.Duration
+	// AcknowledgementTimeout is optional; the PagerDuty default is used when zero
+	AcknowledgementTimeout time.Duration
+	// AutoResolveTimeout is optional; the PagerDuty default is used when zero
+	AutoResolveTimeout time.Duration
+}
+
+// SupportHours are the hours of the days of the week the service is supported
+type SupportHours struct {
+	TimeZone string
+	Days     []time.Weekday
+	// Start and End are times of day (since midnight)
+	Start time.Duration
+	End   time.Duration
+}
+
+// DefaultSettings returns the settings used when a service does not have any; high urgency and intelligent alert grouping
+func DefaultSettings() *Settings {
+	return &Settings{
+		Urgency:       UrgencyHigh,
+		AlertGrouping: GroupingIntelligent,
+	}
+}
+
+func getSettings(service NewService) *Settings {
+	settings := service.GetSettings()
+	if settings == nil {
+		return DefaultSettings()
+	}
+
+	return settings
+}
+
+// applySettings sets the fields of the service from the settings
+func applySettings(service *Service, settings *Settings) {
+	service.IncidentUrgencyRule = &IncidentUrgency{
+		Type:    urgencyConstant,
+		Urgency: settings.Urgency,
+	}
+
+	if settings.SupportHours != nil {
+		service.IncidentUrgencyRule = &IncidentUrgency{
+			Type: urgencySupportHours,
+			DuringSupportHours: &IncidentUrgency{
+				Type:    urgencyConstant,
+				Urgency: settings.Urgency,
+			},
+			OutsideSupportHours: &IncidentUrgency{
+				Type:    urgencyConstant,
+				Urgency: settings.OutsideUrgency,
+			},
+		}
+
+		service.SupportHours = buildSupportHours(settings.SupportHours)
+	}
+
+	service.AlertGroupingParameters = &AlertGroupParameters{
+		Type: settings.AlertGrouping,
+	}
+
+	if settings.AlertGrouping == GroupingTime {
+		service.AlertGroupingParameters.Config = &alertGroupingConfig{
+			Timeout: int(settings.AlertGroupingTimeout.Minutes()),
+		}
+	}
+
+	if settings.AcknowledgementTimeout > 0 {
+		timeout := int(settings.AcknowledgementTimeout.Seconds())
+		service.AcknowledgementTimeout = &timeout
+	}
+
+	if settings.AutoResolveTimeout > 0 {
+		timeout := int(settings.AutoResolveTimeout.Seconds())
+		service.AutoResolveTimeout = &timeout
+	}
+}
+
+func buildSupportHours(supportHours *SupportHours) *supportHoursDTO {
+	out := &supportHoursDTO{
+		Type:      supportHoursFixedTimes,
+		TimeZone:  supportHours.TimeZone,
+		StartTime: formatTimeOfDay(supportHours.Start),
+		EndTime:   formatTimeOfDay(supportHours.End),
+	}
+
+	for _, day := range supportHours.Days {
+		// PagerDuty uses ISO-8601 days; 1 (Monday) to 7 (Sunday)
+		isoDay := int(day)
+		if day == time.Sunday {
+			isoDay = 7
+		}
+
+		out.DaysOfWeek = append(out.DaysOfWeek, isoDay)
+	}
+
+	return out
+}
+
+func formatTimeOfDay(timeOfDay time.Duration) string {
+	return time.Date(0, 1, 1, 0, 0, 0, 0, time.UTC).Add(timeOfDay).Format("15:04:05")
+}
+
+type supportHoursDTO struct {
+	Type       string `json:"type"`
+	TimeZone   string `json:"time_zone"`
+	DaysOfWeek []int  `json:"days_of_week"`
+	StartTime  string `json:"start_time"`
+	EndTime    string `json:"end_time"`
+}
+
+func (s *supportHoursDTO) String() string {
+	if s == nil {
+		return ""
+	}
+
+	var days []string
+	for _, day := range s.DaysOfWeek {
+		days = append(days, fmt.Sprint(day))
+	}
+
+	return fmt.Sprintf("days %s from %s to %s %s", strings.Join(days, ","), s.StartTime, s.EndTime, s.TimeZone)
+}
+
+type alertGroupingConfig struct {
+	Timeout int `json:"timeout"`
+}
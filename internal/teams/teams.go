@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
 	"github.com/corsc/pagerduty-manager/internal/pd"
 
 	"go.uber.org/zap"
@@ -15,13 +17,16 @@ const (
 	getURI         = "/teams/%s"
 	listURI        = "/teams"
 	addURI         = "/teams"
+	updateURI      = "/teams/%s"
 	listMembersURI = "/teams/%s/members"
 	addMemberURI   = "/teams/%s/users/%s"
+	memberURI      = "/teams/%s/users/%s"
 )
 
 var (
-	ErrNoSuchTeam = errors.New("no such team")
-	ErrNoMembers  = errors.New("no members")
+	ErrNoSuchTeam    = errors.New("no such team")
+	ErrAmbiguousTeam = errors.New("more than one team matches")
+	ErrNoMembers     = errors.New("no members")
 )
 
 func New(cfg Config, logger *zap.Logger) *Manager {
@@ -56,52 +61,84 @@ func (u *Manager) Get(ctx context.Context, teamID string) (*Team, error) {
 	return teams.Team, nil
 }
 
+// GetByName returns the team with exactly the supplied name
 func (u *Manager) GetByName(ctx context.Context, name string) (*Team, error) {
 	params := url.Values{}
 	params.Set("query", name)
-	params.Set("total", "false")
-	params.Set("limit", "1")
 
-	teams := &getTeamsResponse{}
+	var matches []*Team
 
-	err := u.api.Get(ctx, listURI, params, teams)
+	err := u.api.List(ctx, listURI, params,
+		func() pd.Page {
+			return &getTeamsResponse{}
+		},
+		func(page pd.Page) {
+			for _, thisTeam := range page.(*getTeamsResponse).Team {
+				if pd.NamesMatch(name, thisTeam.Name, u.cfg.CaseInsensitiveNames()) {
+					matches = append(matches, thisTeam)
+				}
+			}
+		})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get teams '%s' with err: %s", name, err)
 	}
 
-	if len(teams.Team) == 0 {
+	switch len(matches) {
+	case 0:
 		return nil, ErrNoSuchTeam
+
+	case 1:
+		return matches[0], nil
+
+	default:
+		return nil, fmt.Errorf("%w: %d teams named '%s'", ErrAmbiguousTeam, len(matches), name)
+	}
+}
+
+// List returns every team in the account
+func (u *Manager) List(ctx context.Context) ([]*Team, error) {
+	var out []*Team
+
+	err := u.api.List(ctx, listURI, nil,
+		func() pd.Page {
+			return &getTeamsResponse{}
+		},
+		func(page pd.Page) {
+			out = append(out, page.(*getTeamsResponse).Team...)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list teams with err: %s", err)
 	}
 
-	return teams.Team[0], nil
+	return out, nil
 }
 
 func (u *Manager) GetMembers(ctx context.Context, teamID string) ([]*Member, error) {
 	uri := fmt.Sprintf(listMembersURI, teamID)
 
-	params := url.Values{}
-	params.Set("total", "true")
-
-	team := &getTeamMembersResponse{}
+	var members []*Member
 
-	err := u.api.Get(ctx, uri, params, team)
+	err := u.api.List(ctx, uri, nil,
+		func() pd.Page {
+			return &getTeamMembersResponse{}
+		},
+		func(page pd.Page) {
+			for _, member := range page.(*getTeamMembersResponse).Members {
+				members = append(members, &Member{
+					ID:   member.User.ID,
+					Name: member.User.Summary,
+					Role: member.Role,
+				})
+			}
+		})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get team members for team '%s' with err: %s", teamID, err)
 	}
 
-	if team.Members == nil {
+	if len(members) == 0 {
 		return nil, ErrNoMembers
 	}
 
-	members := make([]*Member, len(team.Members))
-
-	for index, member := range team.Members {
-		members[index] = &Member{
-			ID:   member.User.ID,
-			Role: member.Role,
-		}
-	}
-
 	return members, nil
 }
 
@@ -123,6 +160,34 @@ func (u *Manager) Add(ctx context.Context, name, description string) (string, er
 	return respDTO.Team.ID, nil
 }
 
+func (u *Manager) Update(ctx context.Context, teamID, name, description string) error {
+	reqDTO := &addRequest{
+		Team: Team{
+			ID:          teamID,
+			Name:        name,
+			Description: description,
+		},
+	}
+
+	uri := fmt.Sprintf(updateURI, teamID)
+
+	err := u.api.Put(ctx, uri, reqDTO)
+	if err != nil {
+		return fmt.Errorf("failed to update team '%#v' with err: %s", reqDTO, err)
+	}
+
+	return nil
+}
+
+// Diff returns the differences between the live team and the requested name and description
+func (u *Manager) Diff(live *Team, name, description string) []*diff.Field {
+	builder := &diff.Builder{}
+	builder.String("name", live.Name, name)
+	builder.String("description", live.Description, description)
+
+	return builder.Fields()
+}
+
 func (u *Manager) AddMember(ctx context.Context, teamID string, user User) error {
 	uri := fmt.Sprintf(addMemberURI, teamID, user.GetUserID())
 
@@ -138,6 +203,17 @@ func (u *Manager) AddMember(ctx context.Context, teamID string, user User) error
 	return nil
 }
 
+func (u *Manager) RemoveMember(ctx context.Context, teamID, userID string) error {
+	uri := fmt.Sprintf(memberURI, teamID, userID)
+
+	err := u.api.Delete(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to remove user '%s' from team '%s' with err: %s", userID, teamID, err)
+	}
+
+	return nil
+}
+
 type User interface {
 	GetUserID() string
 	GetTeamRole() string
@@ -148,6 +224,7 @@ type getTeamResponse struct {
 }
 
 type getTeamsResponse struct {
+	pd.Pagination
 	Team []*Team `json:"teams"`
 }
 
@@ -159,10 +236,12 @@ type Team struct {
 
 type Member struct {
 	ID   string `json:"id"`
+	Name string `json:"name"`
 	Role string `json:"role"`
 }
 
 type getTeamMembersResponse struct {
+	pd.Pagination
 	Members []*member `json:"members"`
 }
 
@@ -172,7 +251,8 @@ type member struct {
 }
 
 type user struct {
-	ID string `json:"id"`
+	ID      string `json:"id"`
+	Summary string `json:"summary"`
 }
 
 type addRequest struct {
@@ -191,4 +271,8 @@ type Config interface {
 	Debug() bool
 	BaseURL() string
 	AuthToken() string
+	PageSize() int
+	MaxRetries() int
+	MaxBackoff() time.Duration
+	CaseInsensitiveNames() bool
 }
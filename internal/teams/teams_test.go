@@ -8,6 +8,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -74,6 +75,7 @@ func TestManager_GetByName(t *testing.T) {
 	scenarios := []struct {
 		desc                  string
 		configureMockResponse http.HandlerFunc
+		caseInsensitive       bool
 		expected              *Team
 		expectErr             bool
 	}{
@@ -97,6 +99,34 @@ func TestManager_GetByName(t *testing.T) {
 			expected:  nil,
 			expectErr: true,
 		},
+		{
+			desc: "sad path - partial name match only",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"teams": [{"id": "A", "name": "Flintstones Platform"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+		{
+			desc: "happy path - case insensitive",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"teams": [{"id": "FLINT", "name": "FLINTSTONES"}]}`))
+			}),
+			caseInsensitive: true,
+			expected: &Team{
+				ID:   "FLINT",
+				Name: "FLINTSTONES",
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - ambiguous name",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"teams": [{"id": "A", "name": "Flintstones"}, {"id": "B", "name": "Flintstones"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
 	}
 
 	for _, s := range scenarios {
@@ -113,12 +143,75 @@ func TestManager_GetByName(t *testing.T) {
 			defer testServer.Close()
 
 			cfg := &testConfig{
-				baseURL: testServer.URL,
+				baseURL:         testServer.URL,
+				caseInsensitive: scenario.caseInsensitive,
 			}
 
 			// call object under test
 			manager := New(cfg, logger)
-			result, resultErr := manager.GetByName(ctx, "FLINT")
+			result, resultErr := manager.GetByName(ctx, "Flintstones")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result, "expected result")
+		})
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*Team
+		expectErr             bool
+	}{
+		{
+			desc: "happy path - multiple pages",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("offset") == "" {
+					_, _ = resp.Write([]byte(`{"teams": [{"id": "A"}], "more": true}`))
+					return
+				}
+
+				_, _ = resp.Write([]byte(`{"teams": [{"id": "B"}], "more": false}`))
+			}),
+			expected: []*Team{
+				{ID: "A"},
+				{ID: "B"},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL:  testServer.URL,
+				pageSize: 1,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.List(ctx)
 
 			// validation
 			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
@@ -151,6 +244,28 @@ func TestManager_GetMembers(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			desc: "happy path - multiple pages",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("offset") == "" {
+					_, _ = resp.Write([]byte(`{"members": [{"user": {"id": "FRED"}, "role": "responder"}], "more": true}`))
+					return
+				}
+
+				_, _ = resp.Write([]byte(`{"members": [{"user": {"id": "WILMA"}, "role": "manager"}], "more": false}`))
+			}),
+			expected: []*Member{
+				{
+					ID:   "FRED",
+					Role: "responder",
+				},
+				{
+					ID:   "WILMA",
+					Role: "manager",
+				},
+			},
+			expectErr: false,
+		},
 		{
 			desc: "sad path - no members",
 			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
@@ -243,6 +358,76 @@ func TestManager_Add(t *testing.T) {
 	}
 }
 
+func TestManager_Update(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusOK)
+				_, _ = resp.Write([]byte(addHappyPathResponse))
+			}),
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			resultErr := manager.Update(ctx, "BEAT", "The Beatles", "The Fab Four!")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+		})
+	}
+}
+
+func TestManager_Diff(t *testing.T) {
+	logger, _ := zap.NewDevelopment()
+
+	live := &Team{
+		ID:          "BEAT",
+		Name:        "The Beatles",
+		Description: "A band",
+	}
+
+	// call object under test
+	manager := New(&testConfig{}, logger)
+	result := manager.Diff(live, "The Beatles", "The Fab Four!")
+
+	// validation
+	expected := []*diff.Field{
+		{Path: "description", Live: "A band", Desired: "The Fab Four!"},
+	}
+	assert.Equal(t, expected, result)
+}
+
 func TestManager_AddMember(t *testing.T) {
 	scenarios := []struct {
 		desc                  string
@@ -294,6 +479,55 @@ func TestManager_AddMember(t *testing.T) {
 	}
 }
 
+func TestManager_RemoveMember(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusNoContent)
+			}),
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			resultErr := manager.RemoveMember(ctx, "FLINT", "FRED")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+		})
+	}
+}
+
 type testUser struct {
 	userID string
 	role   string
@@ -308,7 +542,11 @@ func (t *testUser) GetTeamRole() string {
 }
 
 type testConfig struct {
-	baseURL string
+	baseURL         string
+	pageSize        int
+	caseInsensitive bool
+	maxRetries      int
+	maxBackoff      time.Duration
 }
 
 func (t *testConfig) AuthToken() string {
@@ -323,6 +561,22 @@ func (t *testConfig) BaseURL() string {
 	return t.baseURL
 }
 
+func (t *testConfig) PageSize() int {
+	return t.pageSize
+}
+
+func (t *testConfig) MaxRetries() int {
+	return t.maxRetries
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return t.maxBackoff
+}
+
+func (t *testConfig) CaseInsensitiveNames() bool {
+	return t.caseInsensitive
+}
+
 var getHappyPathResponse = `
 {
   "team": {
@@ -51,8 +51,8 @@ type testEscalation struct {
 	deptHeadsIDs []string
 }
 
-func (t *testEscalation) GetTeamName() string {
-	return t.teamName
+func (t *testEscalation) GetPolicyName() string {
+	return t.teamName + " Escalation"
 }
 
 func (t *testEscalation) GetDescription() string {
@@ -74,3 +74,7 @@ func (t *testEscalation) GetLeadIDs() []string {
 func (t *testEscalation) GetDeptHeadsIDs() []string {
 	return t.deptHeadsIDs
 }
+
+func (t *testEscalation) GetStructure() *escalations.Structure {
+	return nil
+}
@@ -59,14 +59,22 @@ func (t *testSchedule) GetLeadIDs() []string {
 	return t.leadIDs
 }
 
-func (t *testSchedule) GetTeamName() string {
-	return t.teamName
+func (t *testSchedule) GetScheduleName() string {
+	return t.teamName + " Schedule"
 }
 
 func (t *testSchedule) GetDescription() string {
 	return t.description
 }
 
+func (t *testSchedule) GetRotation() *schedules.Rotation {
+	return nil
+}
+
+func (t *testSchedule) GetLayers() []*schedules.Layer {
+	return nil
+}
+
 func (t *testSchedule) GetTeamID() string {
 	return t.teamID
 }
@@ -1,15 +1,38 @@
 package e2e
 
-import "os"
+import (
+	"os"
+	"time"
+)
 
 type testConfig struct {
-	baseURL string
+	baseURL         string
+	pageSize        int
+	caseInsensitive bool
+	maxRetries      int
+	maxBackoff      time.Duration
 }
 
 func (t *testConfig) Filename() string {
 	return "./test_data/e2e.json"
 }
 
+func (t *testConfig) DryRun() bool {
+	return false
+}
+
+func (t *testConfig) Prune() bool {
+	return false
+}
+
+func (t *testConfig) PruneUsers() bool {
+	return false
+}
+
+func (t *testConfig) Adopt(_ string) bool {
+	return false
+}
+
 func (t *testConfig) AuthToken() string {
 	return os.Getenv("PD_TOKEN")
 }
@@ -21,3 +44,19 @@ func (t *testConfig) Debug() bool {
 func (t *testConfig) BaseURL() string {
 	return t.baseURL
 }
+
+func (t *testConfig) PageSize() int {
+	return t.pageSize
+}
+
+func (t *testConfig) MaxRetries() int {
+	return t.maxRetries
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return t.maxBackoff
+}
+
+func (t *testConfig) CaseInsensitiveNames() bool {
+	return t.caseInsensitive
+}
@@ -63,3 +63,11 @@ func (t *testUser) GetTimeZone() string {
 func (t *testUser) GetUserRole() string {
 	return t.role
 }
+
+func (t *testUser) GetJobTitle() string {
+	return ""
+}
+
+func (t *testUser) GetDescription() string {
+	return ""
+}
@@ -0,0 +1,228 @@
+package users
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const (
+	contactMethodsURI    = "/users/%s/contact_methods"
+	contactMethodURI     = "/users/%s/contact_methods/%s"
+	notificationRulesURI = "/users/%s/notification_rules"
+	notificationRuleURI  = "/users/%s/notification_rules/%s"
+
+	notificationRuleType = "assignment_notification_rule"
+)
+
+// PagerDuty contact method types
+const (
+	ContactMethodEmail = "email_contact_method"
+	ContactMethodPhone = "phone_contact_method"
+	ContactMethodSMS   = "sms_contact_method"
+	ContactMethodPush  = "push_notification_contact_method"
+)
+
+// GetContactMethods returns all of the contact methods of the user
+func (u *Manager) GetContactMethods(ctx context.Context, userID string) ([]*ContactMethod, error) {
+	uri := fmt.Sprintf(contactMethodsURI, userID)
+
+	respDTO := &contactMethodsResponse{}
+
+	err := u.api.Get(ctx, uri, nil, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get contact methods of user '%s' with err: %s", userID, err)
+	}
+
+	return respDTO.ContactMethods, nil
+}
+
+// AddContactMethod creates the contact method for the user and returns its ID.
+// Note: push notification contact methods can only be created by the PagerDuty mobile app.
+func (u *Manager) AddContactMethod(ctx context.Context, userID string, method ReqContactMethod) (string, error) {
+	uri := fmt.Sprintf(contactMethodsURI, userID)
+
+	reqDTO := newContactMethodRequest(method)
+
+	respDTO := &contactMethodRequest{}
+
+	err := u.api.Post(ctx, uri, reqDTO, respDTO)
+	if err != nil {
+		return "", fmt.Errorf("failed to add contact method to user '%s' with err: %s", userID, err)
+	}
+
+	return respDTO.ContactMethod.ID, nil
+}
+
+// UpdateContactMethod changes the label of the contact method
+func (u *Manager) UpdateContactMethod(ctx context.Context, userID, methodID string, method ReqContactMethod) error {
+	uri := fmt.Sprintf(contactMethodURI, userID, methodID)
+
+	reqDTO := newContactMethodRequest(method)
+
+	err := u.api.Put(ctx, uri, reqDTO)
+	if err != nil {
+		return fmt.Errorf("failed to update contact method '%s' of user '%s' with err: %s", methodID, userID, err)
+	}
+
+	return nil
+}
+
+func (u *Manager) DeleteContactMethod(ctx context.Context, userID, methodID string) error {
+	uri := fmt.Sprintf(contactMethodURI, userID, methodID)
+
+	err := u.api.Delete(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to delete contact method '%s' of user '%s' with err: %s", methodID, userID, err)
+	}
+
+	return nil
+}
+
+// FindContactMethod returns the live contact method with the same type and address as the requested method (or nil)
+func FindContactMethod(live []*ContactMethod, method ReqContactMethod) *ContactMethod {
+	for _, liveMethod := range live {
+		if liveMethod.Type != method.GetType() || liveMethod.CountryCode != method.GetCountryCode() {
+			continue
+		}
+
+		if normalizeAddress(liveMethod.Address) == normalizeAddress(method.GetAddress()) {
+			return liveMethod
+		}
+	}
+
+	return nil
+}
+
+// PagerDuty stores phone numbers without formatting
+func normalizeAddress(address string) string {
+	return strings.ToLower(strings.NewReplacer(" ", "", "-", "", "(", "", ")", "").Replace(address))
+}
+
+// GetNotificationRules returns all of the notification rules (for all urgencies) of the user
+func (u *Manager) GetNotificationRules(ctx context.Context, userID string) ([]*NotificationRule, error) {
+	uri := fmt.Sprintf(notificationRulesURI, userID)
+
+	params := url.Values{}
+	params.Set("urgency", "any")
+
+	respDTO := &notificationRulesResponse{}
+
+	err := u.api.Get(ctx, uri, params, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notification rules of user '%s' with err: %s", userID, err)
+	}
+
+	return respDTO.NotificationRules, nil
+}
+
+// AddNotificationRule creates the notification rule for the user and returns its ID
+func (u *Manager) AddNotificationRule(ctx context.Context, userID string, rule ReqNotificationRule) (string, error) {
+	uri := fmt.Sprintf(notificationRulesURI, userID)
+
+	reqDTO := &notificationRuleRequest{
+		NotificationRule: &NotificationRule{
+			Type:                notificationRuleType,
+			StartDelayInMinutes: rule.GetDelayInMinutes(),
+			Urgency:             rule.GetUrgency(),
+			ContactMethod: &ContactMethod{
+				ID:   rule.GetContactMethodID(),
+				Type: rule.GetContactMethodType(),
+			},
+		},
+	}
+
+	respDTO := &notificationRuleRequest{}
+
+	err := u.api.Post(ctx, uri, reqDTO, respDTO)
+	if err != nil {
+		return "", fmt.Errorf("failed to add notification rule to user '%s' with err: %s", userID, err)
+	}
+
+	return respDTO.NotificationRule.ID, nil
+}
+
+func (u *Manager) DeleteNotificationRule(ctx context.Context, userID, ruleID string) error {
+	uri := fmt.Sprintf(notificationRuleURI, userID, ruleID)
+
+	err := u.api.Delete(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to delete notification rule '%s' of user '%s' with err: %s", ruleID, userID, err)
+	}
+
+	return nil
+}
+
+// FindNotificationRule returns the live notification rule with the same urgency, delay and contact method as the
+// requested rule (or nil)
+func FindNotificationRule(live []*NotificationRule, rule ReqNotificationRule) *NotificationRule {
+	for _, liveRule := range live {
+		if liveRule.Urgency != rule.GetUrgency() || liveRule.StartDelayInMinutes != rule.GetDelayInMinutes() {
+			continue
+		}
+
+		if liveRule.ContactMethod != nil && liveRule.ContactMethod.ID == rule.GetContactMethodID() {
+			return liveRule
+		}
+	}
+
+	return nil
+}
+
+type ReqContactMethod interface {
+	GetType() string
+	GetCountryCode() int
+	GetAddress() string
+	GetLabel() string
+}
+
+type ReqNotificationRule interface {
+	GetUrgency() string
+	GetDelayInMinutes() int
+	GetContactMethodID() string
+	GetContactMethodType() string
+}
+
+func newContactMethodRequest(method ReqContactMethod) *contactMethodRequest {
+	return &contactMethodRequest{
+		ContactMethod: &ContactMethod{
+			Type:        method.GetType(),
+			CountryCode: method.GetCountryCode(),
+			Address:     method.GetAddress(),
+			Label:       method.GetLabel(),
+		},
+	}
+}
+
+type contactMethodsResponse struct {
+	ContactMethods []*ContactMethod `json:"contact_methods"`
+}
+
+type contactMethodRequest struct {
+	ContactMethod *ContactMethod `json:"contact_method"`
+}
+
+type notificationRulesResponse struct {
+	NotificationRules []*NotificationRule `json:"notification_rules"`
+}
+
+type notificationRuleRequest struct {
+	NotificationRule *NotificationRule `json:"notification_rule"`
+}
+
+type ContactMethod struct {
+	ID          string `json:"id,omitempty"`
+	Type        string `json:"type"`
+	CountryCode int    `json:"country_code,omitempty"`
+	Address     string `json:"address,omitempty"`
+	Label       string `json:"label,omitempty"`
+}
+
+type NotificationRule struct {
+	ID                  string         `json:"id,omitempty"`
+	Type                string         `json:"type"`
+	StartDelayInMinutes int            `json:"start_delay_in_minutes"`
+	Urgency             string         `json:"urgency"`
+	ContactMethod       *ContactMethod `json:"contact_method"`
+}
@@ -0,0 +1,211 @@
+package users
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_GetContactMethods(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*ContactMethod
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"contact_methods": [{"id": "A", "type": "sms_contact_method", "country_code": 1, "address": "5550100"}]}`))
+			}),
+			expected: []*ContactMethod{
+				{ID: "A", Type: ContactMethodSMS, CountryCode: 1, Address: "5550100"},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.GetContactMethods(ctx, "FRED")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result, "expected result")
+		})
+	}
+}
+
+func TestManager_AddContactMethod(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              string
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				payload, _ := ioutil.ReadAll(req.Body)
+				if !strings.Contains(string(payload), `"type":"phone_contact_method"`) {
+					resp.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				resp.WriteHeader(http.StatusCreated)
+				_, _ = resp.Write([]byte(`{"contact_method": {"id": "NEW"}}`))
+			}),
+			expected:  "NEW",
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusBadRequest)
+			}),
+			expected:  "",
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			method := &testContactMethod{
+				methodType:  ContactMethodPhone,
+				countryCode: 1,
+				address:     "5550100",
+			}
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.AddContactMethod(ctx, "FRED", method)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result, "expected result")
+		})
+	}
+}
+
+func TestFindContactMethod(t *testing.T) {
+	live := []*ContactMethod{
+		{ID: "A", Type: ContactMethodSMS, CountryCode: 1, Address: "5550100"},
+		{ID: "B", Type: ContactMethodPhone, CountryCode: 1, Address: "5550100"},
+	}
+
+	// call object under test
+	result := FindContactMethod(live, &testContactMethod{methodType: ContactMethodPhone, countryCode: 1, address: "555-0100"})
+
+	// validation
+	require.NotNil(t, result)
+	assert.Equal(t, "B", result.ID)
+	assert.Nil(t, FindContactMethod(live, &testContactMethod{methodType: ContactMethodPhone, countryCode: 44, address: "5550100"}))
+}
+
+func TestFindNotificationRule(t *testing.T) {
+	live := []*NotificationRule{
+		{ID: "A", Urgency: "high", StartDelayInMinutes: 0, ContactMethod: &ContactMethod{ID: "SMS"}},
+		{ID: "B", Urgency: "high", StartDelayInMinutes: 5, ContactMethod: &ContactMethod{ID: "SMS"}},
+	}
+
+	// call object under test
+	result := FindNotificationRule(live, &testNotificationRule{urgency: "high", delay: 5, contactMethodID: "SMS"})
+
+	// validation
+	require.NotNil(t, result)
+	assert.Equal(t, "B", result.ID)
+	assert.Nil(t, FindNotificationRule(live, &testNotificationRule{urgency: "low", delay: 5, contactMethodID: "SMS"}))
+}
+
+type testContactMethod struct {
+	methodType  string
+	countryCode int
+	address     string
+	label       string
+}
+
+func (t *testContactMethod) GetType() string {
+	return t.methodType
+}
+
+func (t *testContactMethod) GetCountryCode() int {
+	return t.countryCode
+}
+
+func (t *testContactMethod) GetAddress() string {
+	return t.address
+}
+
+func (t *testContactMethod) GetLabel() string {
+	return t.label
+}
+
+type testNotificationRule struct {
+	urgency         string
+	delay           int
+	contactMethodID string
+}
+
+func (t *testNotificationRule) GetUrgency() string {
+	return t.urgency
+}
+
+func (t *testNotificationRule) GetDelayInMinutes() int {
+	return t.delay
+}
+
+func (t *testNotificationRule) GetContactMethodID() string {
+	return t.contactMethodID
+}
+
+func (t *testNotificationRule) GetContactMethodType() string {
+	return ContactMethodSMS
+}
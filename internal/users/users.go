@@ -5,13 +5,23 @@ import (
 	"errors"
 	"fmt"
 	"net/url"
+	"strings"
+	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
+	"github.com/corsc/pagerduty-manager/internal/ownership"
 	"github.com/corsc/pagerduty-manager/internal/pd"
 
 	"go.uber.org/zap"
 )
 
-const listURI = "/users"
+const (
+	getURI  = "/users/%s"
+	listURI = "/users"
+
+	roleOwner = "owner"
+	roleAdmin = "admin"
+)
 
 var ErrNoSuchUser = errors.New("no such user")
 
@@ -30,24 +40,68 @@ type Manager struct {
 	api    *pd.API
 }
 
+func (u *Manager) Get(ctx context.Context, userID string) (*User, error) {
+	uri := fmt.Sprintf(getURI, userID)
+
+	respDTO := &getResponse{}
+
+	err := u.api.Get(ctx, uri, nil, respDTO)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user '%s' with err: %s", userID, err)
+	}
+
+	if respDTO.User == nil {
+		return nil, ErrNoSuchUser
+	}
+
+	return respDTO.User, nil
+}
+
+// GetByEmail returns the user with exactly the supplied email (ignoring case)
 func (u *Manager) GetByEmail(ctx context.Context, email string) (*User, error) {
 	params := url.Values{}
 	params.Set("query", email)
-	params.Set("total", "false")
-	params.Set("limit", "1")
 
-	users := &listResponse{}
+	var match *User
 
-	err := u.api.Get(ctx, listURI, params, users)
+	err := u.api.List(ctx, listURI, params,
+		func() pd.Page {
+			return &listResponse{}
+		},
+		func(page pd.Page) {
+			for _, user := range page.(*listResponse).Users {
+				if strings.EqualFold(email, user.Email) {
+					match = user
+				}
+			}
+		})
 	if err != nil {
 		return nil, fmt.Errorf("failed to get user by email '%s` with err: %s", email, err)
 	}
 
-	if len(users.Users) == 0 {
+	if match == nil {
 		return nil, ErrNoSuchUser
 	}
 
-	return users.Users[0], nil
+	return match, nil
+}
+
+// List returns every user in the account
+func (u *Manager) List(ctx context.Context) ([]*User, error) {
+	var out []*User
+
+	err := u.api.List(ctx, listURI, nil,
+		func() pd.Page {
+			return &listResponse{}
+		},
+		func(page pd.Page) {
+			out = append(out, page.(*listResponse).Users...)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list users with err: %s", err)
+	}
+
+	return out, nil
 }
 
 func (u *Manager) Add(ctx context.Context, user NewUser, defaultTimeZone string) (string, error) {
@@ -63,11 +117,78 @@ func (u *Manager) Add(ctx context.Context, user NewUser, defaultTimeZone string)
 	return respDTO.User.ID, nil
 }
 
+// Update changes the name, time zone, base role, job title and description of the user to match the requested user.
+// The role of the account owner and of admins that were not made admins by this tool is never reduced.
+func (u *Manager) Update(ctx context.Context, live *User, user NewUser, defaultTimeZone string) error {
+	reqDTO := u.buildUpdateRequest(live, user, defaultTimeZone)
+
+	uri := fmt.Sprintf(getURI, live.ID)
+
+	err := u.api.Put(ctx, uri, reqDTO)
+	if err != nil {
+		return fmt.Errorf("failed to update user '%s' with err: %s", live.ID, err)
+	}
+
+	return nil
+}
+
+// Diff returns the differences between the live user and the requested user
+func (u *Manager) Diff(live *User, user NewUser, defaultTimeZone string) []*diff.Field {
+	desired := u.buildUpdateRequest(live, user, defaultTimeZone).User
+
+	builder := &diff.Builder{}
+	builder.String("name", live.Name, desired.Name)
+	builder.String("time_zone", live.TimeZone, desired.TimeZone)
+	builder.String("role", live.Role, desired.Role)
+	builder.String("job_title", live.JobTitle, desired.JobTitle)
+	builder.String("description", live.Description, desired.Description)
+
+	return builder.Fields()
+}
+
+func (u *Manager) buildUpdateRequest(live *User, user NewUser, defaultTimeZone string) *newUserRequest {
+	out := newNewUserRequest(user, defaultTimeZone)
+
+	// guard against demoting the owner or admins that were not made admins by this tool.
+	// The ownership marker cannot be used for this as adopting the user adds it.
+	if live.Role == roleOwner || (live.Role == roleAdmin && !ownership.IsAdminOwned(live.Description)) {
+		if out.User.Role != live.Role {
+			u.logger.Warn("not changing role of user as they are the account owner or an admin that was not made admin by this tool",
+				zap.String("email", live.Email))
+		}
+
+		out.User.Role = live.Role
+	}
+
+	// only record the admin role when it was granted by this tool, so admins made by hand stay protected
+	out.User.Description = ownership.UnmarkAdmin(out.User.Description)
+	if out.User.Role == roleAdmin && (live.Role != roleAdmin || ownership.IsAdminOwned(live.Description)) {
+		out.User.Description = ownership.MarkAdmin(out.User.Description)
+	}
+
+	return out
+}
+
+// Delete removes the user from the account.
+// Note: PagerDuty will refuse to delete users that are still on-call or have open incidents.
+func (u *Manager) Delete(ctx context.Context, userID string) error {
+	uri := fmt.Sprintf(getURI, userID)
+
+	err := u.api.Delete(ctx, uri)
+	if err != nil {
+		return fmt.Errorf("failed to delete user '%s' with err: %s", userID, err)
+	}
+
+	return nil
+}
+
 type NewUser interface {
 	GetName() string
 	GetEmail() string
 	GetTimeZone() string
 	GetUserRole() string
+	GetJobTitle() string
+	GetDescription() string
 }
 
 func newNewUserRequest(user NewUser, defaultTimeZone string) *newUserRequest {
@@ -83,9 +204,16 @@ func newNewUserRequest(user NewUser, defaultTimeZone string) *newUserRequest {
 			Email:    user.GetEmail(),
 			TimeZone: timeZone,
 			Role:     user.GetUserRole(),
+			JobTitle: user.GetJobTitle(),
+
+			Description: user.GetDescription(),
 		},
 	}
 
+	if out.User.Role == roleAdmin {
+		out.User.Description = ownership.MarkAdmin(out.User.Description)
+	}
+
 	return out
 }
 
@@ -104,17 +232,30 @@ type userFormat struct {
 	Email    string `json:"email"`
 	TimeZone string `json:"time_zone"`
 	Role     string `json:"role"`
+	JobTitle string `json:"job_title"`
+
+	Description string `json:"description"`
+}
+
+type getResponse struct {
+	User *User `json:"user"`
 }
 
 type listResponse struct {
+	pd.Pagination
 	Users []*User `json:"users"`
 }
 
 type User struct {
-	ID    string `json:"id"`
-	Name  string `json:"name"`
-	Email string `json:"email"`
-	Teams []Team `json:"teams"`
+	ID       string `json:"id"`
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Role     string `json:"role"`
+	TimeZone string `json:"time_zone"`
+	JobTitle string `json:"job_title"`
+	Teams    []Team `json:"teams"`
+
+	Description string `json:"description"`
 }
 
 type Team struct {
@@ -126,4 +267,7 @@ type Config interface {
 	Debug() bool
 	BaseURL() string
 	AuthToken() string
+	PageSize() int
+	MaxRetries() int
+	MaxBackoff() time.Duration
 }
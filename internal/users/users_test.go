@@ -2,12 +2,17 @@ package users
 
 import (
 	"context"
+	"encoding/json"
+	"io/ioutil"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
 	"time"
 
+	"github.com/corsc/pagerduty-manager/internal/diff"
+	"github.com/corsc/pagerduty-manager/internal/ownership"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
@@ -37,6 +42,14 @@ func TestManager_GetByEmail(t *testing.T) {
 			},
 			expectErr: false,
 		},
+		{
+			desc: "sad path - partial email match only",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				_, _ = resp.Write([]byte(`{"users": [{"email": "fred@flintsones.com.au"}]}`))
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
 		{
 			desc: "sad path - no user found",
 			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
@@ -66,7 +79,69 @@ func TestManager_GetByEmail(t *testing.T) {
 
 			// call object under test
 			manager := New(cfg, logger)
-			result, resultErr := manager.GetByEmail(ctx, "fu@bar.com")
+			result, resultErr := manager.GetByEmail(ctx, "Fred@Flintsones.com")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result, "expected result")
+		})
+	}
+}
+
+func TestManager_List(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*User
+		expectErr             bool
+	}{
+		{
+			desc: "happy path - multiple pages",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.URL.Query().Get("offset") == "" {
+					_, _ = resp.Write([]byte(`{"users": [{"id": "A"}], "more": true}`))
+					return
+				}
+
+				_, _ = resp.Write([]byte(`{"users": [{"id": "B"}], "more": false}`))
+			}),
+			expected: []*User{
+				{ID: "A"},
+				{ID: "B"},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expected:  nil,
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL:  testServer.URL,
+				pageSize: 1,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.List(ctx)
 
 			// validation
 			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
@@ -136,11 +211,190 @@ func TestManager_Add(t *testing.T) {
 	}
 }
 
+func TestManager_Update(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				payload, _ := ioutil.ReadAll(req.Body)
+				if req.Method != http.MethodPut || !strings.Contains(string(payload), `"job_title":"Bass"`) {
+					resp.WriteHeader(http.StatusBadRequest)
+					return
+				}
+
+				resp.WriteHeader(http.StatusOK)
+			}),
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			live := &User{ID: "PPAUL", Role: "user"}
+			user := &testUser{name: "Paul", email: "paul@beatles.com", role: "admin", jobTitle: "Bass"}
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			resultErr := manager.Update(ctx, live, user, "Europe/London")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+		})
+	}
+}
+
+func TestManager_Diff(t *testing.T) {
+	scenarios := []struct {
+		desc     string
+		live     *User
+		user     *testUser
+		expected []*diff.Field
+	}{
+		{
+			desc: "no differences",
+			live: &User{Name: "Paul", Role: "user", TimeZone: "Europe/London", JobTitle: "Bass"},
+			user: &testUser{name: "Paul", role: "user", jobTitle: "Bass"},
+		},
+		{
+			desc: "changed name, time zone and role",
+			live: &User{Name: "Paul", Role: "limited_user", TimeZone: "Europe/London", JobTitle: "Bass"},
+			user: &testUser{name: "Sir Paul", role: "user", timeZone: "America/New_York", jobTitle: "Bass"},
+			expected: []*diff.Field{
+				{Path: "name", Live: "Paul", Desired: "Sir Paul"},
+				{Path: "time_zone", Live: "Europe/London", Desired: "America/New_York"},
+				{Path: "role", Live: "limited_user", Desired: "user"},
+			},
+		},
+		{
+			desc: "owner is never demoted",
+			live: &User{Name: "Paul", Role: "owner", TimeZone: "Europe/London", Description: ownership.Marker},
+			user: &testUser{name: "Paul", role: "limited_user", description: ownership.Marker},
+		},
+		{
+			desc: "unmanaged admin is not demoted",
+			live: &User{Name: "Paul", Role: "admin", TimeZone: "Europe/London"},
+			user: &testUser{name: "Paul", role: "user", description: ownership.Marker},
+			expected: []*diff.Field{
+				{Path: "description", Live: "", Desired: ownership.Marker},
+			},
+		},
+		{
+			desc: "adopted admin is not demoted",
+			live: &User{Name: "Paul", Role: "admin", TimeZone: "Europe/London", Description: ownership.Marker},
+			user: &testUser{name: "Paul", role: "user", description: ownership.Marker},
+		},
+		{
+			desc: "admin made by this tool is demoted",
+			live: &User{Name: "Paul", Role: "admin", TimeZone: "Europe/London", Description: ownership.Marker + " " + ownership.AdminMarker},
+			user: &testUser{name: "Paul", role: "user", description: ownership.Marker},
+			expected: []*diff.Field{
+				{Path: "role", Live: "admin", Desired: "user"},
+				{Path: "description", Live: ownership.Marker + " " + ownership.AdminMarker, Desired: ownership.Marker},
+			},
+		},
+		{
+			desc: "promoted to admin",
+			live: &User{Name: "Paul", Role: "user", TimeZone: "Europe/London", Description: ownership.Marker},
+			user: &testUser{name: "Paul", role: "admin", description: ownership.Marker},
+			expected: []*diff.Field{
+				{Path: "role", Live: "user", Desired: "admin"},
+				{Path: "description", Live: ownership.Marker, Desired: ownership.Marker + " " + ownership.AdminMarker},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			// call object under test
+			manager := New(&testConfig{}, logger)
+			result := manager.Diff(scenario.live, scenario.user, "Europe/London")
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestManager_Update_adminMadeByHand(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	// the user was made admin by hand and is then adopted
+	live := &User{ID: "PPAUL", Name: "Paul", Role: "admin", TimeZone: "Europe/London"}
+	user := &testUser{name: "Paul", role: "user", description: ownership.Marker}
+
+	// mocks
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		// store the update so the next sync sees it
+		reqDTO := &newUserRequest{}
+		err := json.NewDecoder(req.Body).Decode(reqDTO)
+		require.NoError(t, err)
+
+		live.Role = reqDTO.User.Role
+		live.Description = reqDTO.User.Description
+
+		resp.WriteHeader(http.StatusOK)
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		baseURL: testServer.URL,
+	}
+
+	manager := New(cfg, logger)
+
+	// call object under test; sync twice
+	for run := 1; run <= 2; run++ {
+		resultErr := manager.Update(ctx, live, user, "Europe/London")
+		require.NoError(t, resultErr)
+
+		// validation
+		assert.Equal(t, "admin", live.Role, "run %d", run)
+		assert.Equal(t, ownership.Marker, live.Description, "run %d", run)
+		assert.Empty(t, manager.Diff(live, user, "Europe/London"), "run %d", run)
+	}
+}
+
 type testUser struct {
-	name     string
-	email    string
-	timeZone string
-	role     string
+	name        string
+	email       string
+	timeZone    string
+	role        string
+	jobTitle    string
+	description string
 }
 
 func (t *testUser) GetName() string {
@@ -159,8 +413,19 @@ func (t *testUser) GetUserRole() string {
 	return t.role
 }
 
+func (t *testUser) GetJobTitle() string {
+	return t.jobTitle
+}
+
+func (t *testUser) GetDescription() string {
+	return t.description
+}
+
 type testConfig struct {
-	baseURL string
+	baseURL    string
+	pageSize   int
+	maxRetries int
+	maxBackoff time.Duration
 }
 
 func (t *testConfig) AuthToken() string {
@@ -175,6 +440,18 @@ func (t *testConfig) BaseURL() string {
 	return t.baseURL
 }
 
+func (t *testConfig) PageSize() int {
+	return t.pageSize
+}
+
+func (t *testConfig) MaxRetries() int {
+	return t.maxRetries
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return t.maxBackoff
+}
+
 var getHappyPathResponse = `
 {
   "users": [
@@ -0,0 +1,216 @@
+package webhooks
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/diff"
+	"github.com/corsc/pagerduty-manager/internal/pd"
+
+	"go.uber.org/zap"
+)
+
+const (
+	listURI   = "/webhook_subscriptions"
+	addURI    = "/webhook_subscriptions"
+	updateURI = "/webhook_subscriptions/%s"
+)
+
+const (
+	typeSubscription  = "webhook_subscription"
+	typeHTTPDelivery  = "http_delivery_method"
+	typeServiceFilter = "service_reference"
+)
+
+// IncidentEvents are the incident events sent to the webhooks
+var IncidentEvents = []string{
+	"incident.acknowledged",
+	"incident.escalated",
+	"incident.priority_updated",
+	"incident.reassigned",
+	"incident.resolved",
+	"incident.triggered",
+	"incident.unacknowledged",
+}
+
+func New(cfg Config, logger *zap.Logger) *Manager {
+	return &Manager{
+		cfg:    cfg,
+		logger: logger,
+		api:    pd.New(cfg, logger),
+	}
+}
+
+// Manager allows for loading, creating and updating the webhook subscriptions of services
+type Manager struct {
+	cfg    Config
+	logger *zap.Logger
+	api    *pd.API
+}
+
+// ListForService returns the webhook subscriptions of the service
+func (u *Manager) ListForService(ctx context.Context, serviceID string) ([]*Subscription, error) {
+	params := url.Values{}
+	params.Set("filter_type", typeServiceFilter)
+	params.Set("filter_id", serviceID)
+
+	var out []*Subscription
+
+	err := u.api.List(ctx, listURI, params,
+		func() pd.Page {
+			return &listResponse{}
+		},
+		func(page pd.Page) {
+			out = append(out, page.(*listResponse).Subscriptions...)
+		})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list webhook subscriptions of service '%s' with err: %s", serviceID, err)
+	}
+
+	return out, nil
+}
+
+// Add creates a webhook subscription for the incidents of the service
+func (u *Manager) Add(ctx context.Context, serviceID string, subscription NewSubscription) (string, error) {
+	reqDTO := &addRequest{
+		Subscription: u.buildSubscription(serviceID, subscription),
+	}
+
+	respDTO := &addResponse{}
+
+	err := u.api.Post(ctx, addURI, reqDTO, respDTO)
+	if err != nil {
+		return "", fmt.Errorf("failed to add webhook subscription '%#v' with err: %s", reqDTO, err)
+	}
+
+	return respDTO.Subscription.ID, nil
+}
+
+// Update replaces the URL, events and description of the webhook subscription.
+// PagerDuty does not allow the filter of a subscription to be changed.
+func (u *Manager) Update(ctx context.Context, subscriptionID string, subscription NewSubscription) error {
+	reqDTO := &updateRequest{
+		Subscription: &updateSubscription{
+			Description: subscription.GetDescription(),
+			Events:      IncidentEvents,
+			Active:      true,
+			DeliveryMethod: &DeliveryMethod{
+				URL: subscription.GetURL(),
+			},
+		},
+	}
+
+	uri := fmt.Sprintf(updateURI, subscriptionID)
+
+	err := u.api.Put(ctx, uri, reqDTO)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook subscription '%s' with err: %s", subscriptionID, err)
+	}
+
+	return nil
+}
+
+// Diff returns the differences between the live webhook subscription and the requested one
+func (u *Manager) Diff(live *Subscription, subscription NewSubscription) []*diff.Field {
+	liveURL := ""
+	if live.DeliveryMethod != nil {
+		liveURL = live.DeliveryMethod.URL
+	}
+
+	builder := &diff.Builder{}
+	builder.String("delivery_method.url", liveURL, subscription.GetURL())
+	builder.String("active", fmt.Sprint(live.Active), "true")
+	builder.Set("events", live.Events, IncidentEvents)
+
+	return builder.Fields()
+}
+
+func (u *Manager) buildSubscription(serviceID string, subscription NewSubscription) *Subscription {
+	return &Subscription{
+		Type:        typeSubscription,
+		Description: subscription.GetDescription(),
+		Events:      IncidentEvents,
+		Active:      true,
+		DeliveryMethod: &DeliveryMethod{
+			Type: typeHTTPDelivery,
+			URL:  subscription.GetURL(),
+		},
+		Filter: &Filter{
+			Type: typeServiceFilter,
+			ID:   serviceID,
+		},
+	}
+}
+
+// FindSubscription returns the subscription with the description or nil
+func FindSubscription(subscriptions []*Subscription, description string) *Subscription {
+	for _, subscription := range subscriptions {
+		if subscription.Description == description {
+			return subscription
+		}
+	}
+
+	return nil
+}
+
+// NewSubscription is the webhook subscription requested for a service
+type NewSubscription interface {
+	// GetDescription is used to find the subscription
+	GetDescription() string
+	GetURL() string
+}
+
+type Subscription struct {
+	ID             string          `json:"id,omitempty"`
+	Type           string          `json:"type"`
+	Description    string          `json:"description"`
+	Events         []string        `json:"events"`
+	Active         bool            `json:"active"`
+	DeliveryMethod *DeliveryMethod `json:"delivery_method"`
+	Filter         *Filter         `json:"filter"`
+}
+
+type DeliveryMethod struct {
+	Type string `json:"type,omitempty"`
+	URL  string `json:"url"`
+}
+
+type Filter struct {
+	Type string `json:"type"`
+	ID   string `json:"id"`
+}
+
+type listResponse struct {
+	pd.Pagination
+	Subscriptions []*Subscription `json:"webhook_subscriptions"`
+}
+
+type addRequest struct {
+	Subscription *Subscription `json:"webhook_subscription"`
+}
+
+type addResponse struct {
+	Subscription *Subscription `json:"webhook_subscription"`
+}
+
+type updateRequest struct {
+	Subscription *updateSubscription `json:"webhook_subscription"`
+}
+
+type updateSubscription struct {
+	Description    string          `json:"description"`
+	Events         []string        `json:"events"`
+	Active         bool            `json:"active"`
+	DeliveryMethod *DeliveryMethod `json:"delivery_method"`
+}
+
+type Config interface {
+	Debug() bool
+	BaseURL() string
+	AuthToken() string
+	PageSize() int
+	MaxRetries() int
+	MaxBackoff() time.Duration
+}
@@ -0,0 +1,257 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/diff"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_ListForService(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              []*Subscription
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				assert.Equal(t, "service_reference", req.URL.Query().Get("filter_type"))
+				assert.Equal(t, "BOOK", req.URL.Query().Get("filter_id"))
+
+				_, _ = resp.Write([]byte(listHappyPathResponse))
+			}),
+			expected: []*Subscription{
+				{
+					ID:             "WEB",
+					Type:           "webhook_subscription",
+					Description:    "Slack notifications",
+					Events:         []string{"incident.triggered"},
+					Active:         true,
+					DeliveryMethod: &DeliveryMethod{Type: "http_delivery_method", URL: "https://relay.example.com/booking"},
+					Filter:         &Filter{Type: "service_reference", ID: "BOOK"},
+				},
+			},
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.ListForService(ctx, "BOOK")
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestManager_Add(t *testing.T) {
+	scenarios := []struct {
+		desc                  string
+		configureMockResponse http.HandlerFunc
+		expected              string
+		expectErr             bool
+	}{
+		{
+			desc: "happy path",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				reqDTO := &addRequest{}
+				require.NoError(t, json.NewDecoder(req.Body).Decode(reqDTO))
+
+				assert.Equal(t, &Filter{Type: "service_reference", ID: "BOOK"}, reqDTO.Subscription.Filter)
+				assert.Equal(t, "https://relay.example.com/booking", reqDTO.Subscription.DeliveryMethod.URL)
+				assert.Equal(t, IncidentEvents, reqDTO.Subscription.Events)
+
+				resp.WriteHeader(http.StatusCreated)
+				_, _ = resp.Write([]byte(`{"webhook_subscription": {"id": "WEB"}}`))
+			}),
+			expected:  "WEB",
+			expectErr: false,
+		},
+		{
+			desc: "sad path - system error",
+			configureMockResponse: http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				resp.WriteHeader(http.StatusInternalServerError)
+			}),
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			testServer := httptest.NewServer(scenario.configureMockResponse)
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+			}
+
+			subscription := &testSubscription{
+				description: "Slack notifications",
+				url:         "https://relay.example.com/booking",
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result, resultErr := manager.Add(ctx, "BOOK", subscription)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestManager_Diff(t *testing.T) {
+	scenarios := []struct {
+		desc     string
+		live     *Subscription
+		expected []*diff.Field
+	}{
+		{
+			desc: "no differences",
+			live: &Subscription{
+				Events:         IncidentEvents,
+				Active:         true,
+				DeliveryMethod: &DeliveryMethod{URL: "https://relay.example.com/booking"},
+			},
+			expected: nil,
+		},
+		{
+			desc: "disabled by hand and moved channel",
+			live: &Subscription{
+				Events:         IncidentEvents,
+				Active:         false,
+				DeliveryMethod: &DeliveryMethod{URL: "https://relay.example.com/general"},
+			},
+			expected: []*diff.Field{
+				{Path: "delivery_method.url", Live: "https://relay.example.com/general", Desired: "https://relay.example.com/booking"},
+				{Path: "active", Live: "false", Desired: "true"},
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			subscription := &testSubscription{
+				description: "Slack notifications",
+				url:         "https://relay.example.com/booking",
+			}
+
+			// call object under test
+			manager := New(&testConfig{}, logger)
+			result := manager.Diff(scenario.live, subscription)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+type testSubscription struct {
+	description string
+	url         string
+}
+
+func (t *testSubscription) GetDescription() string {
+	return t.description
+}
+
+func (t *testSubscription) GetURL() string {
+	return t.url
+}
+
+type testConfig struct {
+	baseURL    string
+	pageSize   int
+	maxRetries int
+	maxBackoff time.Duration
+}
+
+func (t *testConfig) AuthToken() string {
+	return os.Getenv("PD_TOKEN")
+}
+
+func (t *testConfig) Debug() bool {
+	return true
+}
+
+func (t *testConfig) BaseURL() string {
+	return t.baseURL
+}
+
+func (t *testConfig) PageSize() int {
+	return t.pageSize
+}
+
+func (t *testConfig) MaxRetries() int {
+	return t.maxRetries
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return t.maxBackoff
+}
+
+var listHappyPathResponse = `
+{
+  "webhook_subscriptions": [
+    {
+      "id": "WEB",
+      "type": "webhook_subscription",
+      "description": "Slack notifications",
+      "events": ["incident.triggered"],
+      "active": true,
+      "delivery_method": {"type": "http_delivery_method", "url": "https://relay.example.com/booking"},
+      "filter": {"type": "service_reference", "id": "BOOK"}
+    }
+  ],
+  "more": false
+}
+`
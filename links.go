@@ -0,0 +1,198 @@
+package pdmanager
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"github.com/corsc/pagerduty-manager/internal/diff"
+	"github.com/corsc/pagerduty-manager/internal/ownership"
+	"github.com/corsc/pagerduty-manager/internal/services"
+	"go.uber.org/zap"
+)
+
+// max length of the name of a service custom field
+const maxFieldNameLength = 50
+
+// syncLinks sets the links of the service as the values of service custom fields (one field per link name), as PagerDuty
+// shows the custom fields of the service on its incidents.
+// Missing fields are created. Fields that were created by this tool but are no longer a link of the service are cleared.
+func (m *Manager) syncLinks(ctx context.Context, service *Service, serviceID string) error {
+	err := m.loadLinkFields(ctx)
+	if err != nil {
+		return err
+	}
+
+	desired := map[string]string{}
+
+	for _, link := range service.allLinks() {
+		fieldName := linkFieldName(link.Name)
+
+		err = m.addLinkField(ctx, fieldName, link.Name)
+		if err != nil {
+			return err
+		}
+
+		desired[fieldName] = link.URL
+	}
+
+	live := map[string]string{}
+
+	if serviceID != "" && (len(desired) > 0 || hasOwnedFields(m.linkFields)) {
+		live, err = m.serviceManager.GetFieldValues(ctx, serviceID)
+		if err != nil {
+			m.logger.Error("failed to sync service - fetch custom field values failed", zap.Error(err))
+			return err
+		}
+	}
+
+	builder := &diff.Builder{}
+	values := map[string]string{}
+
+	for _, field := range sortedFields(m.linkFields) {
+		desiredURL, isLink := desired[field.Name]
+		if !isLink && (!ownership.IsOwned(field.Description) || live[field.ID] == "") {
+			continue
+		}
+
+		if live[field.ID] == desiredURL {
+			continue
+		}
+
+		builder.String("links."+field.DisplayName, live[field.ID], desiredURL)
+		values[field.ID] = desiredURL
+	}
+
+	if len(builder.Fields()) == 0 {
+		return nil
+	}
+
+	change := &Change{
+		Action:   ActionUpdate,
+		Resource: resourceServiceLinks,
+		Name:     service.Name,
+		Fields:   builder.Fields(),
+	}
+
+	err = m.apply(change, func() error {
+		return m.serviceManager.SetFieldValues(ctx, serviceID, values)
+	})
+	if err != nil {
+		m.logger.Error("failed to sync service - set custom field values failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// loadLinkFields fetches the service custom fields (once)
+func (m *Manager) loadLinkFields(ctx context.Context) error {
+	if m.linkFields != nil {
+		return nil
+	}
+
+	fields, err := m.serviceManager.ListFields(ctx)
+	if err != nil {
+		m.logger.Error("failed to sync service - list custom fields failed", zap.Error(err))
+		return err
+	}
+
+	m.linkFields = map[string]*services.Field{}
+	for _, field := range fields {
+		m.linkFields[field.Name] = field
+	}
+
+	return nil
+}
+
+// addLinkField creates the service custom field for the link when it does not exist
+func (m *Manager) addLinkField(ctx context.Context, fieldName, linkName string) error {
+	if m.linkFields[fieldName] != nil {
+		return nil
+	}
+
+	change := &Change{
+		Action:   ActionCreate,
+		Resource: resourceServiceField,
+		Name:     fieldName,
+		Details: []string{
+			"display name: " + linkName,
+		},
+	}
+
+	// during a dry-run the field is only recorded (without an ID) so that it is planned once
+	field := &services.Field{
+		Name:        fieldName,
+		DisplayName: linkName,
+		Description: ownership.Marker,
+	}
+
+	err := m.apply(change, func() (addErr error) {
+		field, addErr = m.serviceManager.AddURLField(ctx, fieldName, linkName, ownership.Marker)
+		return addErr
+	})
+	if err != nil {
+		m.logger.Error("failed to sync service - add custom field failed", zap.Error(err))
+		return err
+	}
+
+	m.linkFields[fieldName] = field
+
+	return nil
+}
+
+// linkFieldName returns the name of the service custom field of the link; PagerDuty only allows lowercase letters, digits
+// and underscores (e.g. "Runbook Wiki" => "runbook_wiki")
+func linkFieldName(linkName string) string {
+	builder := &strings.Builder{}
+	separate := false
+
+	for _, char := range strings.ToLower(linkName) {
+		if (char < 'a' || char > 'z') && (char < '0' || char > '9') {
+			separate = builder.Len() > 0
+			continue
+		}
+
+		if separate {
+			builder.WriteRune('_')
+			separate = false
+		}
+
+		builder.WriteRune(char)
+	}
+
+	out := builder.String()
+	if len(out) > maxFieldNameLength {
+		out = strings.TrimRight(out[:maxFieldNameLength], "_")
+	}
+
+	return out
+}
+
+// hasOwnedFields returns true when any of the fields were created by this tool
+func hasOwnedFields(fields map[string]*services.Field) bool {
+	for _, field := range fields {
+		if ownership.IsOwned(field.Description) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// sortedFields returns the fields ordered by name
+func sortedFields(fields map[string]*services.Field) []*services.Field {
+	var names []string
+	for name := range fields {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	out := make([]*services.Field, len(names))
+	for index, name := range names {
+		out[index] = fields[name]
+	}
+
+	return out
+}
@@ -0,0 +1,155 @@
+package pdmanager
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/corsc/pagerduty-manager/internal/ownership"
+	"github.com/corsc/pagerduty-manager/internal/services"
+)
+
+func TestManager_syncLinks(t *testing.T) {
+	scenarios := []struct {
+		desc              string
+		dryRun            bool
+		serviceID         string
+		expectedPlan      []string
+		expectedMutations []string
+	}{
+		{
+			desc:   "dry-run - new service",
+			dryRun: true,
+			expectedPlan: []string{
+				`create service field "repo"`,
+				`update service links "Booking API": links.Repo: "" => "https://git.example.com/booking"; links.Runbook: "" => "https://wiki.example.com/booking"`,
+			},
+		},
+		{
+			desc:      "dry-run - existing service",
+			dryRun:    true,
+			serviceID: "API",
+			expectedPlan: []string{
+				`create service field "repo"`,
+				`update service links "Booking API": links.Dashboard: "https://grafana.example.com/old" => ""; links.Repo: "" => "https://git.example.com/booking"`,
+			},
+		},
+		{
+			desc:      "existing service",
+			serviceID: "API",
+			expectedPlan: []string{
+				`create service field "repo"`,
+				`update service links "Booking API": links.Dashboard: "https://grafana.example.com/old" => ""; links.Repo: "" => "https://git.example.com/booking"`,
+			},
+			expectedMutations: []string{
+				`POST /services/custom_fields {"field":{"name":"repo","display_name":"Repo","description":"` + ownership.Marker + `","data_type":"url","field_type":"single_value","enabled":true}}`,
+				`PUT /services/API/custom_fields/values {"custom_fields":[{"id":"DASHBOARD","value":null},{"id":"REPO","value":"https://git.example.com/booking"}]}`,
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			service := &Service{
+				Name: "Booking API",
+				Links: []*ServiceLink{
+					{Name: "Runbook", URL: "https://wiki.example.com/booking"},
+					{Name: "Repo", URL: "https://git.example.com/booking"},
+				},
+			}
+
+			// mocks
+			var mutex sync.Mutex
+			var mutations []string
+
+			testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodGet {
+					payload, _ := ioutil.ReadAll(req.Body)
+
+					mutex.Lock()
+					mutations = append(mutations, req.Method+" "+req.URL.Path+" "+string(payload))
+					mutex.Unlock()
+
+					if req.Method == http.MethodPost {
+						resp.WriteHeader(http.StatusCreated)
+						_, _ = resp.Write([]byte(`{"field": {"id": "REPO", "name": "repo", "display_name": "Repo", "description": "` + ownership.Marker + `"}}`))
+					}
+
+					return
+				}
+
+				response, ok := linkResponses[req.URL.Path]
+				require.True(t, ok, "unexpected request %s", req.URL.Path)
+
+				_, _ = resp.Write([]byte(response))
+			}))
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL: testServer.URL,
+				dryRun:  scenario.dryRun,
+			}
+
+			manager := New(cfg, logger)
+			manager.serviceManager = services.New(cfg, logger)
+
+			// call object under test
+			resultErr := manager.syncLinks(ctx, service, scenario.serviceID)
+			require.NoError(t, resultErr)
+
+			// validation
+			var result []string
+			for _, change := range manager.Plan().Changes {
+				line := change.String()[2:]
+				for index, field := range change.Fields {
+					separator := "; "
+					if index == 0 {
+						separator = ": "
+					}
+
+					line += separator + field.String()
+				}
+
+				result = append(result, line)
+			}
+
+			assert.Equal(t, scenario.expectedPlan, result)
+			assert.Equal(t, scenario.expectedMutations, mutations)
+		})
+	}
+}
+
+var linkResponses = map[string]string{
+	"/services/custom_fields": `{"fields": [
+		{"id": "RUNBOOK", "name": "runbook", "display_name": "Runbook", "description": "` + ownership.Marker + `"},
+		{"id": "DASHBOARD", "name": "dashboard", "display_name": "Dashboard", "description": "` + ownership.Marker + `"},
+		{"id": "TIER", "name": "tier", "display_name": "Tier", "description": "made by hand"}
+	]}`,
+	"/services/API/custom_fields/values": `{"custom_fields": [
+		{"id": "RUNBOOK", "value": "https://wiki.example.com/booking"},
+		{"id": "DASHBOARD", "value": "https://grafana.example.com/old"},
+		{"id": "TIER", "value": "https://tiers.example.com/1"}
+	]}`,
+}
+
+func TestLinkFieldName(t *testing.T) {
+	assert.Equal(t, "runbook", linkFieldName("Runbook"))
+	assert.Equal(t, "runbook_wiki", linkFieldName("Runbook Wiki"))
+	assert.Equal(t, "on_call_guide_v2", linkFieldName("  On-call guide (v2) "))
+	assert.Equal(t, "", linkFieldName("???"))
+}
@@ -2,20 +2,26 @@ package pdmanager
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
-	"io/ioutil"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/corsc/pagerduty-manager/internal/services"
 
 	"github.com/corsc/pagerduty-manager/internal/escalations"
 
+	"github.com/corsc/pagerduty-manager/internal/naming"
+
+	"github.com/corsc/pagerduty-manager/internal/ownership"
+
 	"github.com/corsc/pagerduty-manager/internal/schedules"
 
 	"github.com/corsc/pagerduty-manager/internal/teams"
 
 	"github.com/corsc/pagerduty-manager/internal/users"
+	"github.com/corsc/pagerduty-manager/internal/webhooks"
 
 	"go.uber.org/zap"
 )
@@ -27,6 +33,32 @@ const (
 	roleDeptHead = "dept-head"
 )
 
+const (
+	resourceUser       = "user"
+	resourceTeam       = "team"
+	resourceTeamMember = "team member"
+	resourceSchedule   = "schedule"
+	resourceEscalation = "escalation policy"
+	resourceService    = "service"
+
+	resourceServiceLinks     = "service links"
+	resourceServiceField     = "service field"
+	resourceContactMethod    = "contact method"
+	resourceNotificationRule = "notification rule"
+)
+
+// Resource types that can be adopted (see Config.Adopt)
+const (
+	AdoptUser       = "user"
+	AdoptTeam       = "team"
+	AdoptSchedule   = "schedule"
+	AdoptEscalation = "escalation_policy"
+	AdoptService    = "service"
+)
+
+// AdoptableResources are all of the resource types that can be adopted
+var AdoptableResources = []string{AdoptUser, AdoptTeam, AdoptSchedule, AdoptEscalation, AdoptService}
+
 // map of our roles to PD user roles
 var rolesToPDUserRoles = map[string]string{
 	roleMember:   "limited_user",
@@ -35,7 +67,14 @@ var rolesToPDUserRoles = map[string]string{
 	roleDeptHead: "admin",
 }
 
-// map of our roles to PD user roles
+// PD user roles from least to most privileged
+var pdUserRolePrivilege = map[string]int{
+	"limited_user": 1,
+	"user":         2,
+	"admin":        3,
+}
+
+// map of our roles to PD team roles
 var rolesToPDTeamRoles = map[string]string{
 	roleMember:   "responder",
 	roleObserver: "responder",
@@ -48,6 +87,10 @@ func New(cfg Config, logger *zap.Logger) *Manager {
 		cfg:           cfg,
 		logger:        logger,
 		companyConfig: &companyConfig{},
+		plan:          &Plan{},
+		secrets:       &Secrets{},
+		vendorIDs:     map[string]string{},
+		removedFrom:   map[string]map[string]bool{},
 	}
 }
 
@@ -56,50 +99,207 @@ type Manager struct {
 	cfg    Config
 	logger *zap.Logger
 
-	companyConfig *companyConfig
+	companyConfig   *companyConfig
+	overridesConfig *overridesConfig
+	plan            *Plan
+	naming          *naming.Strategy
+
+	// secrets of the integrations of the services in the config
+	secrets *Secrets
+	// vendor IDs keyed by vendor name
+	vendorIDs map[string]string
+	// service custom fields keyed by name (nil until they are fetched)
+	linkFields map[string]*services.Field
+	// problems found when validating the config
+	problems []*Problem
+
+	// team IDs each user has been removed from, keyed by user ID
+	removedFrom map[string]map[string]bool
 
 	userManager       *users.Manager
 	teamManager       *teams.Manager
 	scheduleManager   *schedules.Manager
 	escalationManager *escalations.Manager
 	serviceManager    *services.Manager
+	webhookManager    *webhooks.Manager
 }
 
 // Parse attempts to parse the provide file into this manager
 func (m *Manager) Parse(_ context.Context) error {
 	m.logger.Debug("loading data from file", zap.String("file", m.cfg.Filename()))
 
-	fileContents, err := ioutil.ReadFile(m.cfg.Filename())
+	config, err := loadConfig(m.cfg.Filename())
 	if err != nil {
-		return fmt.Errorf("failed to read input file with err: %w", err)
+		return err
 	}
 
-	err = json.Unmarshal(fileContents, m.companyConfig)
+	m.companyConfig = config
+
+	err = m.validate()
+	if err != nil {
+		return err
+	}
+
+	return m.applyNaming()
+}
+
+// applyNaming sets the names of the PagerDuty objects that are created for each team
+func (m *Manager) applyNaming() error {
+	strategy, err := naming.New(m.companyConfig.Naming.Templates, m.companyConfig.Naming.Legacy)
 	if err != nil {
-		return fmt.Errorf("failed to parse config JSON with err: %w", err)
+		return fmt.Errorf("invalid naming config with err: %w", err)
 	}
 
-	return m.validate()
+	m.naming = strategy
+
+	for _, team := range m.companyConfig.Teams {
+		team.ScheduleName = strategy.Name(naming.ResourceSchedule, team.Name)
+		team.PolicyName = strategy.Name(naming.ResourceEscalation, team.Name)
+		team.ServiceName = strategy.Name(naming.ResourceTeamService, team.Name)
+	}
+
+	return nil
 }
 
 func (m *Manager) validate() error {
+	v := &validator{
+		problems: m.companyConfig.problems,
+	}
+
 	if len(m.companyConfig.Teams) == 0 {
-		return errors.New("no teams found in the JSON")
+		v.errorf("teams", "no teams found in the config")
 	}
 
-	for _, thisTeam := range m.companyConfig.Teams {
-		for _, thisMember := range thisTeam.Members {
-			_, ok := rolesToPDUserRoles[thisMember.Role]
-			if !ok {
-				return fmt.Errorf("invalid role 'value in: %v", thisMember)
+	m.validateDefaultTimezone(v)
+	v.check("slack.webhook_url", m.companyConfig.Slack.validate())
+
+	teamNames := map[string]bool{}
+	serviceNames := map[string]bool{}
+
+	for teamIndex, thisTeam := range m.companyConfig.Teams {
+		v.source = thisTeam.source
+		path := fmt.Sprintf("teams[%d]", teamIndex)
+
+		if teamNames[thisTeam.Name] {
+			v.errorf(path+".name", "duplicate team '%s'", thisTeam.Name)
+		}
+
+		teamNames[thisTeam.Name] = true
+
+		m.validateTeam(v, path, thisTeam)
+
+		for serviceIndex, thisService := range thisTeam.Services {
+			if serviceNames[thisService.Name] {
+				v.errorf(fmt.Sprintf("%s.services[%d].name", path, serviceIndex), "duplicate service '%s'", thisService.Name)
 			}
+
+			serviceNames[thisService.Name] = true
 		}
 	}
 
-	return nil
+	v.source = ""
+
+	m.validateMembersAcrossTeams(v)
+
+	errs := v.errorCount()
+
+	m.resolveTeamReferences(v)
+
+	// cycles can only be found once every reference has been resolved
+	if v.errorCount() == errs {
+		_, err := m.escalationOrder()
+
+		var cycleErr *escalationCycleError
+		if errors.As(err, &cycleErr) {
+			v.errorf(cycleErr.path, "%s", cycleErr)
+		}
+	}
+
+	m.validateNotificationProfiles(v)
+
+	m.problems = v.problems
+
+	for _, problem := range m.problems {
+		if problem.Severity == SeverityWarning {
+			m.logger.Warn("problem with the config", zap.String("problem", problem.String()))
+		}
+	}
+
+	return v.err()
+}
+
+// validateTeam validates the team and parses its settings
+func (m *Manager) validateTeam(v *validator, path string, team *Team) {
+	if team.Name == "" {
+		v.errorf(path+".name", "missing name")
+	}
+
+	team.rotation = team.Schedule.rotation(v, path+".schedule")
+
+	// the layers are validated against the default rotation when the rotation of the schedule is invalid
+	baseRotation := team.rotation
+	if baseRotation == nil {
+		baseRotation = schedules.DefaultRotation()
+	}
+
+	team.layers = team.Schedule.layers(v, path+".schedule", team, baseRotation)
+
+	v.check(path+".slack", m.validateSlack(team))
+	team.Escalation.validate(v, path+".escalation")
+
+	for memberIndex, thisMember := range team.Members {
+		m.validateMember(v, fmt.Sprintf("%s.members[%d]", path, memberIndex), thisMember)
+	}
+
+	validateOnCall(v, path, team)
+
+	for serviceIndex, thisService := range team.Services {
+		servicePath := fmt.Sprintf("%s.services[%d]", path, serviceIndex)
+
+		if thisService.Name == "" {
+			v.errorf(servicePath+".name", "missing name")
+		}
+
+		validateIntegrations(v, servicePath, thisService)
+		thisService.validateLinks(v, servicePath)
+
+		thisService.settings = thisService.parseSettings(v, servicePath, m.companyConfig.DefaultTimezone)
+	}
+}
+
+// Plan returns the changes made by the sync methods so far.
+// During a dry-run these are the changes that would have been made.
+func (m *Manager) Plan() *Plan {
+	return m.plan
+}
+
+// apply records the change in the plan and then makes it, unless this is a dry-run
+func (m *Manager) apply(change *Change, makeChange func() error) error {
+	m.plan.add(change)
+
+	if m.cfg.DryRun() {
+		return nil
+	}
+
+	return makeChange()
 }
 
-// Sync calls all of the Sync Methods in the correct order
+// isManaged returns true when the object was created by this tool (has the ownership marker)
+// or when objects of this type have been adopted.
+// Objects that are not managed must not be modified.
+func (m *Manager) isManaged(adoptResource, resource, name, description string) bool {
+	if ownership.IsOwned(description) || m.cfg.Adopt(adoptResource) {
+		return true
+	}
+
+	m.logger.Warn("skipping "+resource+" as it was not created by this tool; use adopt to manage it",
+		zap.String("name", name))
+
+	return false
+}
+
+// Sync calls all of the Sync Methods in the correct order and then Prune.
+// During a dry-run the current state is read from PagerDuty but no changes are made; use Plan() to see what would change.
 func (m *Manager) Sync(ctx context.Context) error {
 	err := m.SyncUsers(ctx)
 	if err != nil {
@@ -126,11 +326,12 @@ func (m *Manager) Sync(ctx context.Context) error {
 		return err
 	}
 
-	return nil
+	return m.Prune(ctx)
 }
 
 // SyncUsers attempts to download the existing users and create any that do not yet exist.
-// Note: existing data will not be modified in any way.
+// Existing users are compared with the config and only updated when they differ.
+// The contact methods and notification rules of each user are then synced (see syncNotifications).
 func (m *Manager) SyncUsers(ctx context.Context) error {
 	var members []*Member
 	for _, team := range m.companyConfig.Teams {
@@ -139,11 +340,39 @@ func (m *Manager) SyncUsers(ctx context.Context) error {
 
 	m.userManager = users.New(m.cfg, m.logger)
 
+	m.resolveUserRoles()
+	m.resolveUserFields()
+
+	// members can appear in multiple teams
+	userIDs := map[string]string{}
+
 	for _, member := range members {
+		userID, found := userIDs[member.Email]
+		if found {
+			member.ID = userID
+			continue
+		}
+
 		fetchedUser, err := m.userManager.GetByEmail(ctx, member.Email)
 		if err == nil {
 			// user exists
 			member.ID = fetchedUser.ID
+			userIDs[member.Email] = member.ID
+
+			if !m.isManaged(AdoptUser, resourceUser, member.Email, fetchedUser.Description) {
+				continue
+			}
+
+			err = m.updateUser(ctx, member, fetchedUser)
+			if err != nil {
+				return err
+			}
+
+			err = m.syncNotifications(ctx, member)
+			if err != nil {
+				return err
+			}
+
 			continue
 		}
 
@@ -152,18 +381,130 @@ func (m *Manager) SyncUsers(ctx context.Context) error {
 			return err
 		}
 
-		member.ID, err = m.userManager.Add(ctx, member, m.companyConfig.DefaultTimezone)
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceUser,
+			Name:     member.Email,
+			Details: []string{
+				"name: " + member.Name,
+				"role: " + member.GetUserRole(),
+			},
+		}
+
+		err = m.apply(change, func() (addErr error) {
+			member.ID, addErr = m.userManager.Add(ctx, member, m.companyConfig.DefaultTimezone)
+			return addErr
+		})
 		if err != nil {
 			m.logger.Error("failed to sync users - add user failed", zap.Error(err))
 			return err
 		}
+
+		userIDs[member.Email] = member.ID
+
+		err = m.syncNotifications(ctx, member)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// resolveUserRoles sets the user (base) role of each member.
+// Members can appear in multiple teams with different roles; the most privileged role wins.
+func (m *Manager) resolveUserRoles() {
+	userRoles := map[string]string{}
+
+	for _, team := range m.companyConfig.Teams {
+		for _, member := range team.Members {
+			role := rolesToPDUserRoles[member.Role]
+			if pdUserRolePrivilege[role] > pdUserRolePrivilege[userRoles[member.Email]] {
+				userRoles[member.Email] = role
+			}
+		}
+	}
+
+	for _, team := range m.companyConfig.Teams {
+		for _, member := range team.Members {
+			member.userRole = userRoles[member.Email]
+		}
+	}
+}
+
+// resolveUserFields sets the job title, description, contact methods and notification profile of each member.
+// Members can appear in multiple teams and can set these in any one of them; the first team that sets a field wins
+// (validation rejects teams that set it to a different value).
+func (m *Manager) resolveUserFields() {
+	shared := map[string]*Member{}
+
+	for _, team := range m.companyConfig.Teams {
+		for _, member := range team.Members {
+			user, ok := shared[member.Email]
+			if !ok {
+				user = &Member{}
+				shared[member.Email] = user
+			}
+
+			if user.JobTitle == "" {
+				user.JobTitle = member.JobTitle
+			}
+
+			if user.Description == "" {
+				user.Description = member.Description
+			}
+
+			if len(user.ContactMethods) == 0 {
+				user.ContactMethods = member.ContactMethods
+			}
+
+			if user.NotificationProfile == "" {
+				user.NotificationProfile = member.NotificationProfile
+			}
+		}
+	}
+
+	for _, team := range m.companyConfig.Teams {
+		for _, member := range team.Members {
+			user := shared[member.Email]
+
+			member.JobTitle = user.JobTitle
+			member.Description = user.Description
+			member.ContactMethods = user.ContactMethods
+			member.NotificationProfile = user.NotificationProfile
+		}
+	}
+}
+
+// updateUser changes the name, time zone, base role, job title and description of an existing user when they differ from the config.
+// Roles of the account owner and of admins that are not managed by this tool are never reduced.
+func (m *Manager) updateUser(ctx context.Context, member *Member, fetchedUser *users.User) error {
+	fields := m.userManager.Diff(fetchedUser, member, m.companyConfig.DefaultTimezone)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	change := &Change{
+		Action:   ActionUpdate,
+		Resource: resourceUser,
+		Name:     member.Email,
+		Fields:   fields,
+	}
+
+	err := m.apply(change, func() error {
+		return m.userManager.Update(ctx, fetchedUser, member, m.companyConfig.DefaultTimezone)
+	})
+	if err != nil {
+		m.logger.Error("failed to sync users - update user failed", zap.Error(err))
+		return err
 	}
 
 	return nil
 }
 
 // SyncTeams attempts to download the existing teams and create any that do not yet exist.
-// Note: existing data will not be modified in any way.
+// Existing teams and team roles are compared with the config and only updated when they differ.
+// Members that are no longer in the config are removed from the team.
 // Note: creating a team also creates a matching service so we can have an `@oncall-[team]` slack alias
 func (m *Manager) SyncTeams(ctx context.Context) error {
 	m.teamManager = teams.New(m.cfg, m.logger)
@@ -174,6 +515,16 @@ func (m *Manager) SyncTeams(ctx context.Context) error {
 			// team exists
 			team.ID = fetchedTeam.ID
 
+			team.Managed = m.isManaged(AdoptTeam, resourceTeam, team.Name, fetchedTeam.Description)
+			if !team.Managed {
+				continue
+			}
+
+			err = m.updateTeam(ctx, team, fetchedTeam)
+			if err != nil {
+				return err
+			}
+
 			err = m.syncTeamMembers(ctx, team)
 			if err != nil {
 				return err
@@ -187,12 +538,26 @@ func (m *Manager) SyncTeams(ctx context.Context) error {
 			return err
 		}
 
-		team.ID, err = m.teamManager.Add(ctx, team.Name, team.Description)
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceTeam,
+			Name:     team.Name,
+			Details: []string{
+				"description: " + team.Description,
+			},
+		}
+
+		err = m.apply(change, func() (addErr error) {
+			team.ID, addErr = m.teamManager.Add(ctx, team.Name, team.GetDescription())
+			return addErr
+		})
 		if err != nil {
 			m.logger.Error("failed to sync teams - add team failed", zap.Error(err))
 			return err
 		}
 
+		team.Managed = true
+
 		err = m.syncTeamMembers(ctx, team)
 		if err != nil {
 			return err
@@ -202,29 +567,183 @@ func (m *Manager) SyncTeams(ctx context.Context) error {
 	return nil
 }
 
+func (m *Manager) updateTeam(ctx context.Context, team *Team, fetchedTeam *teams.Team) error {
+	fields := m.teamManager.Diff(fetchedTeam, team.Name, team.GetDescription())
+	if len(fields) == 0 {
+		return nil
+	}
+
+	change := &Change{
+		Action:   ActionUpdate,
+		Resource: resourceTeam,
+		Name:     team.Name,
+		Fields:   fields,
+	}
+
+	err := m.apply(change, func() error {
+		return m.teamManager.Update(ctx, team.ID, team.Name, team.GetDescription())
+	})
+	if err != nil {
+		m.logger.Error("failed to sync teams - update team failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
 func (m *Manager) syncTeamMembers(ctx context.Context, team *Team) error {
+	liveRoles, err := m.getTeamRoles(ctx, team)
+	if err != nil {
+		return err
+	}
+
 	for _, member := range team.Members {
-		err := m.teamManager.AddMember(ctx, team.ID, member)
+		liveRole, found := liveRoles[member.ID]
+		if found && liveRole == member.GetTeamRole() {
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceTeamMember,
+			Name:     team.Name + "/" + member.Email,
+			Details: []string{
+				"role: " + member.GetTeamRole(),
+			},
+		}
+
+		if found {
+			change.Action = ActionUpdate
+			change.Details = nil
+			change.Fields = []*FieldDiff{
+				{
+					Path:    "role",
+					Live:    liveRole,
+					Desired: member.GetTeamRole(),
+				},
+			}
+		}
+
+		err = m.apply(change, func() error {
+			return m.teamManager.AddMember(ctx, team.ID, member)
+		})
 		if err != nil {
 			m.logger.Error("failed to sync teams - add team member failed", zap.Error(err))
 			return err
 		}
 	}
 
+	return m.removeTeamMembers(ctx, team, liveRoles)
+}
+
+// removeTeamMembers removes the members of the team that are no longer in the config
+func (m *Manager) removeTeamMembers(ctx context.Context, team *Team, liveRoles map[string]string) error {
+	desiredUserIDs := map[string]bool{}
+	for _, member := range team.Members {
+		desiredUserIDs[member.ID] = true
+	}
+
+	var extraUserIDs []string
+
+	for userID := range liveRoles {
+		if !desiredUserIDs[userID] {
+			extraUserIDs = append(extraUserIDs, userID)
+		}
+	}
+
+	sort.Strings(extraUserIDs)
+
+	for _, userID := range extraUserIDs {
+		// the members of a team only include the user ID; the email is easier to review in the plan
+		user, err := m.userManager.Get(ctx, userID)
+		if err != nil {
+			m.logger.Error("failed to sync teams - fetch team member failed", zap.Error(err))
+			return err
+		}
+
+		change := &Change{
+			Action:   ActionDelete,
+			Resource: resourceTeamMember,
+			Name:     team.Name + "/" + user.Email,
+		}
+
+		userID := userID
+
+		err = m.apply(change, func() error {
+			return m.teamManager.RemoveMember(ctx, team.ID, userID)
+		})
+		if err != nil {
+			m.logger.Error("failed to sync teams - remove team member failed", zap.Error(err))
+			return err
+		}
+
+		if m.removedFrom[userID] == nil {
+			m.removedFrom[userID] = map[string]bool{}
+		}
+
+		m.removedFrom[userID][team.ID] = true
+	}
+
 	return nil
 }
 
+// getTeamRoles returns the current team role of each member of the team, keyed by user ID
+func (m *Manager) getTeamRoles(ctx context.Context, team *Team) (map[string]string, error) {
+	roles := map[string]string{}
+
+	if team.ID == "" {
+		// team is yet to be created
+		return roles, nil
+	}
+
+	members, err := m.teamManager.GetMembers(ctx, team.ID)
+	if err != nil && !errors.Is(err, teams.ErrNoMembers) {
+		m.logger.Error("failed to sync teams - fetch team members failed", zap.Error(err))
+		return nil, err
+	}
+
+	for _, member := range members {
+		roles[member.ID] = member.Role
+	}
+
+	return roles, nil
+}
+
 // SyncSchedules attempts to download the existing schedules and create any that do not yet exist.
-// Note: existing data will not be modified in any way.
+// Existing data is compared with the config and only updated when they differ.
 func (m *Manager) SyncSchedules(ctx context.Context) error {
 	m.scheduleManager = schedules.New(m.cfg, m.logger)
 
 	for _, team := range m.companyConfig.Teams {
-		fetchedSchedule, err := m.scheduleManager.GetByName(ctx, team.Name)
+		fetchedSchedule, err := m.getSchedule(ctx, team)
 		if err == nil {
 			team.ScheduleID = fetchedSchedule.ID
 
-			err = m.scheduleManager.Update(ctx, fetchedSchedule.ID, team, m.companyConfig.DefaultTimezone)
+			liveSchedule, err := m.scheduleManager.Get(ctx, fetchedSchedule.ID)
+			if err != nil {
+				m.logger.Error("failed to sync schedule - fetch schedule failed", zap.Error(err))
+				return err
+			}
+
+			if !m.isManaged(AdoptSchedule, resourceSchedule, liveSchedule.Name, liveSchedule.Description) {
+				continue
+			}
+
+			fields := m.scheduleManager.Diff(liveSchedule, team, m.companyConfig.DefaultTimezone)
+			if len(fields) == 0 {
+				continue
+			}
+
+			change := &Change{
+				Action:   ActionUpdate,
+				Resource: resourceSchedule,
+				Name:     team.ScheduleName,
+				Fields:   fields,
+			}
+
+			err = m.apply(change, func() error {
+				return m.scheduleManager.Update(ctx, fetchedSchedule.ID, team, m.companyConfig.DefaultTimezone)
+			})
 			if err != nil {
 				m.logger.Error("failed to sync schedule - update schedule failed", zap.Error(err))
 				return err
@@ -238,7 +757,17 @@ func (m *Manager) SyncSchedules(ctx context.Context) error {
 			return err
 		}
 
-		team.ScheduleID, err = m.scheduleManager.Add(ctx, team, m.companyConfig.DefaultTimezone)
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceSchedule,
+			Name:     team.ScheduleName,
+			Details:  scheduleDetails(team),
+		}
+
+		err = m.apply(change, func() (addErr error) {
+			team.ScheduleID, addErr = m.scheduleManager.Add(ctx, team, m.companyConfig.DefaultTimezone)
+			return addErr
+		})
 		if err != nil {
 			m.logger.Error("failed to sync schedule - add schedule failed", zap.Error(err))
 			return err
@@ -248,17 +777,71 @@ func (m *Manager) SyncSchedules(ctx context.Context) error {
 	return nil
 }
 
+// getSchedule finds the team's schedule by name.
+// When it does not exist, any legacy names are tried so that existing schedules are adopted (and renamed).
+func (m *Manager) getSchedule(ctx context.Context, team *Team) (*schedules.Schedule, error) {
+	fetchedSchedule, err := m.scheduleManager.GetByName(ctx, team.ScheduleName)
+	if !errors.Is(err, schedules.ErrNoSuchSchedule) {
+		return fetchedSchedule, err
+	}
+
+	for _, legacyName := range m.naming.LegacyNames(naming.ResourceSchedule, team.Name) {
+		fetchedSchedule, err = m.scheduleManager.GetByName(ctx, legacyName)
+		if !errors.Is(err, schedules.ErrNoSuchSchedule) {
+			m.logger.Debug("found schedule with legacy name", zap.String("name", legacyName))
+			return fetchedSchedule, err
+		}
+	}
+
+	return nil, schedules.ErrNoSuchSchedule
+}
+
 // SyncEscalation attempts to download the existing escalation policies and create any that do not yet exist.
-// Note: existing data will not be modified in any way.
+// Existing data is compared with the config and only updated when they differ.
 func (m *Manager) SyncEscalation(ctx context.Context) error {
 	m.escalationManager = escalations.New(m.cfg, m.logger)
 
-	for _, team := range m.companyConfig.Teams {
-		fetchedEscalation, err := m.escalationManager.GetByName(ctx, team.Name)
+	// teams are synced after the teams their escalation policy refers to
+	orderedTeams, err := m.escalationOrder()
+	if err != nil {
+		return err
+	}
+
+	for _, team := range orderedTeams {
+		err := m.resolveEscalationTargets(ctx, team)
+		if err != nil {
+			return err
+		}
+
+		fetchedEscalation, err := m.getPolicy(ctx, team)
 		if err == nil {
 			team.PolicyID = fetchedEscalation.ID
 
-			err = m.escalationManager.Update(ctx, fetchedEscalation.ID, team)
+			livePolicy, err := m.escalationManager.Get(ctx, fetchedEscalation.ID)
+			if err != nil {
+				m.logger.Error("failed to sync escalation - fetch escalation failed", zap.Error(err))
+				return err
+			}
+
+			if !m.isManaged(AdoptEscalation, resourceEscalation, livePolicy.Name, livePolicy.Description) {
+				continue
+			}
+
+			fields := m.escalationManager.Diff(livePolicy, team)
+			if len(fields) == 0 {
+				continue
+			}
+
+			change := &Change{
+				Action:   ActionUpdate,
+				Resource: resourceEscalation,
+				Name:     team.PolicyName,
+				Fields:   fields,
+			}
+
+			err = m.apply(change, func() error {
+				return m.escalationManager.Update(ctx, fetchedEscalation.ID, team)
+			})
 			if err != nil {
 				m.logger.Error("failed to sync escalation - update escalation failed", zap.Error(err))
 				return err
@@ -272,7 +855,17 @@ func (m *Manager) SyncEscalation(ctx context.Context) error {
 			return err
 		}
 
-		team.PolicyID, err = m.escalationManager.Add(ctx, team)
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceEscalation,
+			Name:     team.PolicyName,
+			Details:  escalationDetails(team),
+		}
+
+		err = m.apply(change, func() (addErr error) {
+			team.PolicyID, addErr = m.escalationManager.Add(ctx, team)
+			return addErr
+		})
 		if err != nil {
 			m.logger.Error("failed to sync escalation - add escalation failed", zap.Error(err))
 			return err
@@ -282,10 +875,30 @@ func (m *Manager) SyncEscalation(ctx context.Context) error {
 	return nil
 }
 
+// getPolicy finds the team's escalation policy by name.
+// When it does not exist, any legacy names are tried so that existing policies are adopted (and renamed).
+func (m *Manager) getPolicy(ctx context.Context, team *Team) (*escalations.EscalationPolicy, error) {
+	fetchedPolicy, err := m.escalationManager.GetByName(ctx, team.PolicyName)
+	if !errors.Is(err, escalations.ErrNoSuchPolicy) {
+		return fetchedPolicy, err
+	}
+
+	for _, legacyName := range m.naming.LegacyNames(naming.ResourceEscalation, team.Name) {
+		fetchedPolicy, err = m.escalationManager.GetByName(ctx, legacyName)
+		if !errors.Is(err, escalations.ErrNoSuchPolicy) {
+			m.logger.Debug("found escalation policy with legacy name", zap.String("name", legacyName))
+			return fetchedPolicy, err
+		}
+	}
+
+	return nil, escalations.ErrNoSuchPolicy
+}
+
 // SyncServices attempts to download the existing services and create any that do not yet exist.
-// Note: existing data will not be modified in any way.
+// Existing data is compared with the config and only updated when they differ.
 func (m *Manager) SyncServices(ctx context.Context) error {
 	m.serviceManager = services.New(m.cfg, m.logger)
+	m.webhookManager = webhooks.New(m.cfg, m.logger)
 
 	for _, team := range m.companyConfig.Teams {
 		for _, service := range team.Services {
@@ -297,11 +910,13 @@ func (m *Manager) SyncServices(ctx context.Context) error {
 
 		// fake service for the team (to make @oncall-[team]
 		teamService := &Service{
-			Name:      team.Name,
-			Dashboard: team.Description,
+			Name:        team.ServiceName,
+			Description: team.Description,
 		}
 
-		err := m.upsertService(ctx, teamService, team)
+		legacyNames := m.naming.LegacyNames(naming.ResourceTeamService, team.Name)
+
+		err := m.upsertService(ctx, teamService, team, legacyNames...)
 		if err != nil {
 			return err
 		}
@@ -310,16 +925,56 @@ func (m *Manager) SyncServices(ctx context.Context) error {
 	return nil
 }
 
-func (m *Manager) upsertService(ctx context.Context, service *Service, team *Team) error {
+// upsertService creates or updates the service.
+// When a service with the name does not exist, any legacy names are tried so that existing services are adopted (and renamed).
+func (m *Manager) upsertService(ctx context.Context, service *Service, team *Team, legacyNames ...string) error {
+	change := &Change{
+		Action:   ActionCreate,
+		Resource: resourceService,
+		Name:     service.Name,
+		Details: []string{
+			"description: " + service.GetDescription(),
+			"team: " + team.Name,
+		},
+	}
+
 	fetchedService, err := m.serviceManager.GetByName(ctx, service.Name)
+	for _, legacyName := range legacyNames {
+		if !errors.Is(err, services.ErrNoSuchService) {
+			break
+		}
+
+		fetchedService, err = m.serviceManager.GetByName(ctx, legacyName)
+	}
+
 	if err == nil {
-		err = m.serviceManager.Update(ctx, fetchedService.ID, service, team)
+		liveService, err := m.serviceManager.Get(ctx, fetchedService.ID)
+		if err != nil {
+			m.logger.Error("failed to sync service - fetch service failed", zap.Error(err))
+			return err
+		}
+
+		if !m.isManaged(AdoptService, resourceService, liveService.Name, liveService.Description) {
+			return nil
+		}
+
+		change.Fields = m.serviceManager.Diff(liveService, service, team)
+		if len(change.Fields) == 0 {
+			return m.syncServiceChildren(ctx, service, team, fetchedService.ID)
+		}
+
+		change.Action = ActionUpdate
+		change.Details = nil
+
+		err = m.apply(change, func() error {
+			return m.serviceManager.Update(ctx, fetchedService.ID, service, team)
+		})
 		if err != nil {
 			m.logger.Error("failed to sync service - update service failed", zap.Error(err))
 			return err
 		}
 
-		return nil
+		return m.syncServiceChildren(ctx, service, team, fetchedService.ID)
 	}
 
 	if !errors.Is(err, services.ErrNoSuchService) {
@@ -327,37 +982,109 @@ func (m *Manager) upsertService(ctx context.Context, service *Service, team *Tea
 		return err
 	}
 
-	_, err = m.serviceManager.Add(ctx, service, team)
+	var serviceID string
+
+	err = m.apply(change, func() (addErr error) {
+		serviceID, addErr = m.serviceManager.Add(ctx, service, team)
+		return addErr
+	})
 	if err != nil {
 		m.logger.Error("failed to sync service - add service failed", zap.Error(err))
 		return err
 	}
 
-	return nil
+	return m.syncServiceChildren(ctx, service, team, serviceID)
+}
+
+// syncServiceChildren syncs the links, integrations and Slack webhook subscription of the service
+func (m *Manager) syncServiceChildren(ctx context.Context, service *Service, team *Team, serviceID string) error {
+	err := m.syncLinks(ctx, service, serviceID)
+	if err != nil {
+		return err
+	}
+
+	err = m.syncIntegrations(ctx, service, serviceID)
+	if err != nil {
+		return err
+	}
+
+	return m.syncSlack(ctx, service, team, serviceID)
+}
+
+func scheduleDetails(team *Team) []string {
+	return []string{
+		"members: " + strings.Join(team.getEmails(roleMember, roleLead), ", "),
+	}
 }
 
 // Config is the config for this package
 type Config interface {
 	Debug() bool
+	DryRun() bool
+	Prune() bool
+	// PruneUsers returns true when pruning should also delete the users that are no longer in any team
+	PruneUsers() bool
 	Filename() string
 	BaseURL() string
 	AuthToken() string
+	PageSize() int
+	MaxRetries() int
+	MaxBackoff() time.Duration
+	CaseInsensitiveNames() bool
+	// Adopt returns true when existing objects of the resource type (see AdoptableResources) that were not created by
+	// this tool should be managed (and marked as managed) anyway
+	Adopt(resource string) bool
 }
 
 type companyConfig struct {
 	Teams           []*Team `json:"teams"`
 	DefaultTimezone string  `json:"default_timezone"`
+	Naming          Naming  `json:"naming"`
+
+	// Slack is optional; without it the Slack channels of the teams are not used
+	Slack *SlackConfig `json:"slack"`
+
+	// NotificationProfiles are the notification rules that can be applied to members, keyed by profile name
+	NotificationProfiles map[string][]*NotificationRule `json:"notification_profiles"`
+
+	// problems found when the files were decoded (e.g. unknown fields)
+	problems []*Problem
+}
+
+// Naming contains the templates used to name the schedule, escalation policy and service created for each team.
+// Templates are keyed by resource type (schedule, escalation_policy or team_service) and "{team}" is replaced with the team name.
+// Legacy templates are only used to find (and adopt) objects created with a previous naming scheme.
+type Naming struct {
+	Templates map[string]string   `json:"templates"`
+	Legacy    map[string][]string `json:"legacy"`
 }
 
 type Team struct {
-	ID          string     `json:"-"`
-	Name        string     `json:"name"`
-	Description string     `json:"description"`
-	Slack       string     `json:"slack"`
-	Members     []*Member  `json:"members"`
-	Services    []*Service `json:"services"`
-	ScheduleID  string     `json:"-"`
-	PolicyID    string     `json:"-"`
+	ID          string          `json:"-"`
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Slack       string          `json:"slack"`
+	Members     []*Member       `json:"members"`
+	Services    []*Service      `json:"services"`
+	Schedule    *TeamSchedule   `json:"schedule"`
+	Escalation  *TeamEscalation `json:"escalation"`
+	ScheduleID  string          `json:"-"`
+	PolicyID    string          `json:"-"`
+
+	// Managed is true when the team was created by this tool or has been adopted
+	Managed bool `json:"-"`
+
+	ScheduleName string `json:"-"`
+	PolicyName   string `json:"-"`
+	ServiceName  string `json:"-"`
+
+	rotation *schedules.Rotation
+	layers   []*teamLayer
+
+	// IDs of the schedules and users (by name or email) the escalation policy refers to
+	escalationIDs map[string]string
+	// source is the file and line the team is defined at (used in errors)
+	source string
 }
 
 func (t *Team) GetEscalationPolicyID() string {
@@ -404,18 +1131,76 @@ func (t *Team) GetLeadIDs() []string {
 	return ids
 }
 
+func (t *Team) getEmails(roles ...string) []string {
+	var emails []string
+
+	for _, member := range t.Members {
+		for _, role := range roles {
+			if member.Role == role {
+				emails = append(emails, member.Email)
+			}
+		}
+	}
+
+	return emails
+}
+
+func (t *Team) findMember(email string) *Member {
+	for _, member := range t.Members {
+		if strings.EqualFold(member.Email, email) {
+			return member
+		}
+	}
+
+	return nil
+}
+
 func (t *Team) GetTeamName() string {
 	return t.Name
 }
 
+func (t *Team) GetScheduleName() string {
+	return t.ScheduleName
+}
+
+func (t *Team) GetPolicyName() string {
+	return t.PolicyName
+}
+
+// GetDescription returns the description (with the ownership marker) used for the team and its schedule and escalation policy
 func (t *Team) GetDescription() string {
-	return t.Description
+	return ownership.Mark(t.Description)
 }
 
 func (t *Team) GetTimeZone() string {
 	return ""
 }
 
+func (t *Team) GetRotation() *schedules.Rotation {
+	return t.rotation
+}
+
+// GetLayers returns the layers of the schedule with the IDs of their members
+func (t *Team) GetLayers() []*schedules.Layer {
+	var out []*schedules.Layer
+
+	for _, thisLayer := range t.layers {
+		layer := *thisLayer.layer
+
+		if len(thisLayer.emails) == 0 {
+			layer.UserIDs = append(t.GetResponderIDs(), t.GetLeadIDs()...)
+		}
+
+		for _, email := range thisLayer.emails {
+			layer.UserIDs = append(layer.UserIDs, t.findMember(email).ID)
+		}
+
+		out = append(out, &layer)
+	}
+
+	return out
+}
+
 func (t *Team) GetTeamID() string {
 	return t.ID
 }
@@ -426,6 +1211,15 @@ type Member struct {
 	Email    string `json:"email"`
 	Timezone string `json:"timezone"`
 	Role     string `json:"role"`
+	JobTitle string `json:"job_title"`
+
+	Description string `json:"description"`
+
+	ContactMethods      []*ContactMethod `json:"contact_methods"`
+	NotificationProfile string           `json:"notification_profile"`
+
+	// most privileged user role of the member across all teams
+	userRole string
 }
 
 func (m *Member) GetUserID() string {
@@ -445,22 +1239,60 @@ func (m *Member) GetTimeZone() string {
 }
 
 func (m *Member) GetUserRole() string {
+	if m.userRole != "" {
+		return m.userRole
+	}
+
 	return rolesToPDUserRoles[m.Role]
 }
 
+func (m *Member) GetJobTitle() string {
+	return m.JobTitle
+}
+
+func (m *Member) GetDescription() string {
+	return ownership.Mark(m.Description)
+}
+
 func (m *Member) GetTeamRole() string {
 	return rolesToPDTeamRoles[m.Role]
 }
 
 type Service struct {
-	Name      string `json:"name"`
-	Dashboard string `json:"dashboard"`
+	Name                   string                `json:"name"`
+	Description            string                `json:"description"`
+	Links                  []*ServiceLink        `json:"links"`
+	Dashboard              string                `json:"dashboard"` // Deprecated: use a link named Dashboard
+	Integrations           []*ServiceIntegration `json:"integrations"`
+	Urgency                string                `json:"urgency"`
+	SupportHours           *ServiceSupportHours  `json:"support_hours"`
+	AlertGrouping          string                `json:"alert_grouping"`
+	AlertGroupingTimeout   string                `json:"alert_grouping_timeout"`
+	AcknowledgementTimeout string                `json:"acknowledgement_timeout"`
+	AutoResolveTimeout     string                `json:"auto_resolve_timeout"`
+
+	settings *services.Settings
 }
 
 func (s *Service) GetName() string {
 	return s.Name
 }
 
+// GetDescription returns the description followed by the links of the service and the ownership marker, each on its own line
 func (s *Service) GetDescription() string {
-	return s.Dashboard
+	lines := []string{}
+	if s.Description != "" {
+		lines = append(lines, s.Description)
+	}
+
+	for _, link := range s.allLinks() {
+		lines = append(lines, link.Name+": "+link.URL)
+	}
+
+	return strings.Join(append(lines, ownership.Marker), "\n")
+}
+
+// GetSettings returns the incident settings of the service (nil for the defaults)
+func (s *Service) GetSettings() *services.Settings {
+	return s.settings
 }
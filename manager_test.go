@@ -1,12 +1,19 @@
 package pdmanager
 
 import (
+	"bytes"
 	"context"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
 	"go.uber.org/zap"
 
+	"github.com/corsc/pagerduty-manager/internal/ownership"
+	"github.com/corsc/pagerduty-manager/internal/teams"
+	"github.com/corsc/pagerduty-manager/internal/users"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
 
@@ -21,11 +28,26 @@ func TestManager_Parse(t *testing.T) {
 			in:        "./test_data/simple.json",
 			expectErr: false,
 		},
+		{
+			desc:      "happy path - yaml with includes",
+			in:        "./test_data/yaml/company.yaml",
+			expectErr: false,
+		},
 		{
 			desc:      "sad path - empty file",
 			in:        "./test_data/empty.json",
 			expectErr: true,
 		},
+		{
+			desc:      "sad path - naming template without team name",
+			in:        "./test_data/invalid_naming.json",
+			expectErr: true,
+		},
+		{
+			desc:      "sad path - invalid notification profile",
+			in:        "./test_data/invalid_notification_profile.json",
+			expectErr: true,
+		},
 		{
 			desc:      "sad path - invalid file",
 			in:        "./test_data/invalid.json",
@@ -56,12 +78,255 @@ func TestManager_Parse(t *testing.T) {
 	}
 }
 
+func TestManager_Sync_DryRun(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	// mocks
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method, "dry-run must not make changes")
+
+		// nothing exists yet
+		_, _ = resp.Write([]byte(`{}`))
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		filename: "./test_data/simple.json",
+		baseURL:  testServer.URL,
+		dryRun:   true,
+	}
+
+	// call object under test
+	manager := New(cfg, logger)
+
+	resultErr := manager.Parse(ctx)
+	require.NoError(t, resultErr)
+
+	resultErr = manager.Sync(ctx)
+	require.NoError(t, resultErr)
+
+	// validation
+	plan := manager.Plan()
+	assert.Equal(t, 16, plan.Count(ActionCreate))
+	assert.Equal(t, 0, plan.Count(ActionUpdate))
+
+	output := &bytes.Buffer{}
+	require.NoError(t, plan.Print(output))
+	assert.Contains(t, output.String(), `+ create user "john@beatles.com"`)
+	assert.Contains(t, output.String(), "Plan: 16 to create, 0 to update, 0 to delete.")
+}
+
+func TestManager_isManaged(t *testing.T) {
+	scenarios := []struct {
+		desc        string
+		description string
+		adopt       map[string]bool
+		expected    bool
+	}{
+		{
+			desc:        "created by this tool",
+			description: "The Fab Four " + ownership.Marker,
+			expected:    true,
+		},
+		{
+			desc:        "created by hand",
+			description: "The Fab Four",
+			expected:    false,
+		},
+		{
+			desc:        "created by hand and adopted",
+			description: "The Fab Four",
+			adopt:       map[string]bool{AdoptSchedule: true},
+			expected:    true,
+		},
+		{
+			desc:        "created by hand and other resource adopted",
+			description: "The Fab Four",
+			adopt:       map[string]bool{AdoptService: true},
+			expected:    false,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			cfg := &testConfig{
+				adopt: scenario.adopt,
+			}
+
+			// call object under test
+			manager := New(cfg, logger)
+			result := manager.isManaged(AdoptSchedule, resourceSchedule, "The Beatles Schedule", scenario.description)
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestManager_resolveUserRoles(t *testing.T) {
+	// inputs
+	logger, _ := zap.NewDevelopment()
+
+	paulInA := &Member{Email: "paul@beatles.com", Role: roleMember}
+	paulInB := &Member{Email: "paul@beatles.com", Role: roleDeptHead}
+	john := &Member{Email: "john@beatles.com", Role: roleObserver}
+
+	manager := New(&testConfig{}, logger)
+	manager.companyConfig.Teams = []*Team{
+		{Name: "A", Members: []*Member{paulInA, john}},
+		{Name: "B", Members: []*Member{paulInB}},
+	}
+
+	// call object under test
+	manager.resolveUserRoles()
+
+	// validation
+	assert.Equal(t, "admin", paulInA.GetUserRole(), "most privileged role should win")
+	assert.Equal(t, "admin", paulInB.GetUserRole())
+	assert.Equal(t, "limited_user", john.GetUserRole())
+	assert.Equal(t, "responder", paulInA.GetTeamRole(), "team roles are per team")
+}
+
+func TestManager_resolveUserFields(t *testing.T) {
+	// inputs
+	logger, _ := zap.NewDevelopment()
+
+	methods := []*ContactMethod{{Type: contactSMS, CountryCode: 44, Address: "7700900123"}}
+
+	paulInA := &Member{Email: "paul@beatles.com", JobTitle: "Bassist"}
+	paulInB := &Member{Email: "paul@beatles.com", ContactMethods: methods, NotificationProfile: "default"}
+	john := &Member{Email: "john@beatles.com", Description: "Sings"}
+
+	manager := New(&testConfig{}, logger)
+	manager.companyConfig.Teams = []*Team{
+		{Name: "A", Members: []*Member{paulInA, john}},
+		{Name: "B", Members: []*Member{paulInB}},
+	}
+
+	// call object under test
+	manager.resolveUserFields()
+
+	// validation
+	for _, paul := range []*Member{paulInA, paulInB} {
+		assert.Equal(t, "Bassist", paul.JobTitle, "fields set in any team are used")
+		assert.Equal(t, methods, paul.ContactMethods)
+		assert.Equal(t, "default", paul.NotificationProfile)
+	}
+
+	assert.Equal(t, "Sings", john.Description)
+	assert.Empty(t, john.ContactMethods)
+}
+
+func TestManager_syncTeamMembers(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	// mocks
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method, "dry-run must not make changes")
+
+		if req.URL.Path == "/users/PETE" {
+			_, _ = resp.Write([]byte(`{"user": {"id": "PETE", "email": "pete@beatles.com"}}`))
+			return
+		}
+
+		_, _ = resp.Write([]byte(`{"members": [
+			{"user": {"id": "PAUL"}, "role": "responder"},
+			{"user": {"id": "RINGO"}, "role": "responder"},
+			{"user": {"id": "PETE"}, "role": "responder"}
+		]}`))
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		baseURL: testServer.URL,
+		dryRun:  true,
+	}
+
+	team := &Team{
+		ID:   "BEATLES",
+		Name: "The Beatles",
+		Members: []*Member{
+			{ID: "JOHN", Email: "john@beatles.com", Role: roleMember},
+			{ID: "PAUL", Email: "paul@beatles.com", Role: roleLead},
+			{ID: "RINGO", Email: "ringo@beatles.com", Role: roleMember},
+		},
+	}
+
+	manager := New(cfg, logger)
+	manager.teamManager = teams.New(cfg, logger)
+	manager.userManager = users.New(cfg, logger)
+
+	// call object under test
+	resultErr := manager.syncTeamMembers(ctx, team)
+	require.NoError(t, resultErr)
+
+	// validation
+	expected := []string{
+		`create team member "The Beatles/john@beatles.com"`,
+		`update team member "The Beatles/paul@beatles.com"`,
+		`delete team member "The Beatles/pete@beatles.com"`,
+	}
+
+	var result []string
+	for _, change := range manager.Plan().Changes {
+		result = append(result, change.String()[2:])
+	}
+
+	assert.Equal(t, expected, result)
+	assert.Equal(t, map[string]map[string]bool{"PETE": {"BEATLES": true}}, manager.removedFrom)
+}
+
 type testConfig struct {
-	filename string
+	filename   string
+	baseURL    string
+	dryRun     bool
+	prune      bool
+	pruneUsers bool
+	adopt      map[string]bool
 }
 
 func (t *testConfig) BaseURL() string {
-	return ""
+	return t.baseURL
+}
+
+func (t *testConfig) Prune() bool {
+	return t.prune
+}
+
+func (t *testConfig) PruneUsers() bool {
+	return t.pruneUsers
+}
+
+func (t *testConfig) Adopt(resource string) bool {
+	return t.adopt[resource]
+}
+
+func (t *testConfig) PageSize() int {
+	return 0
+}
+
+func (t *testConfig) MaxRetries() int {
+	return 0
+}
+
+func (t *testConfig) MaxBackoff() time.Duration {
+	return 0
+}
+
+func (t *testConfig) CaseInsensitiveNames() bool {
+	return false
 }
 
 func (t *testConfig) AuthToken() string {
@@ -72,6 +337,10 @@ func (t *testConfig) Debug() bool {
 	return true
 }
 
+func (t *testConfig) DryRun() bool {
+	return t.dryRun
+}
+
 func (t *testConfig) Filename() string {
 	return t.filename
 }
@@ -0,0 +1,431 @@
+package pdmanager
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/corsc/pagerduty-manager/internal/diff"
+	"github.com/corsc/pagerduty-manager/internal/users"
+	"go.uber.org/zap"
+)
+
+const (
+	contactEmail = "email"
+	contactPhone = "phone"
+	contactSMS   = "sms"
+	contactPush  = "push"
+)
+
+// map of our contact method types to PD contact method types
+var contactTypesToPD = map[string]string{
+	contactEmail: users.ContactMethodEmail,
+	contactPhone: users.ContactMethodPhone,
+	contactSMS:   users.ContactMethodSMS,
+	contactPush:  users.ContactMethodPush,
+}
+
+var urgencies = map[string]bool{
+	"high": true,
+	"low":  true,
+}
+
+// validateNotifications records the problems with the contact methods and notification profile of the member; path is the
+// path of the member
+func (m *Manager) validateNotifications(v *validator, path string, member *Member) {
+	for index, method := range member.ContactMethods {
+		methodPath := fmt.Sprintf("%s.contact_methods[%d]", path, index)
+
+		if method.Type == contactEmail || contactTypesToPD[method.Type] == "" {
+			v.errorf(methodPath+".type", "invalid contact method type '%s' (must be phone, sms or push)", method.Type)
+			continue
+		}
+
+		if method.Type == contactPush {
+			continue
+		}
+
+		// PagerDuty rejects phone numbers without a country code or with anything but digits in the address
+		if method.CountryCode <= 0 {
+			v.errorf(methodPath+".country_code", "missing country_code of %s contact method", method.Type)
+		}
+
+		switch {
+		case method.Address == "":
+			v.errorf(methodPath+".address", "missing address of %s contact method", method.Type)
+
+		case !isDigits(method.Address):
+			v.errorf(methodPath+".address", "invalid address '%s' of %s contact method (must be digits only)", method.Address, method.Type)
+		}
+	}
+
+	if member.NotificationProfile == "" {
+		return
+	}
+
+	_, ok := m.companyConfig.NotificationProfiles[member.NotificationProfile]
+	if !ok {
+		v.errorf(path+".notification_profile", "unknown notification profile '%s'", member.NotificationProfile)
+	}
+}
+
+// isDigits returns true when the value only contains the digits 0 to 9
+func isDigits(value string) bool {
+	for _, char := range value {
+		if char < '0' || char > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+// validateNotificationProfiles records the problems with the rules of the notification profiles (in order of profile name)
+func (m *Manager) validateNotificationProfiles(v *validator) {
+	var names []string
+	for name := range m.companyConfig.NotificationProfiles {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	for _, name := range names {
+		for index, rule := range m.companyConfig.NotificationProfiles[name] {
+			rulePath := fmt.Sprintf("notification_profiles.%s[%d]", name, index)
+
+			if !urgencies[rule.Urgency] {
+				v.errorf(rulePath+".urgency", "invalid urgency '%s' (must be high or low)", rule.Urgency)
+			}
+
+			if contactTypesToPD[rule.ContactMethod] == "" {
+				v.errorf(rulePath+".contact_method", "invalid contact method '%s'", rule.ContactMethod)
+			}
+
+			if rule.DelayMinutes < 0 {
+				v.errorf(rulePath+".delay_minutes", "invalid delay %d", rule.DelayMinutes)
+			}
+		}
+	}
+}
+
+// syncNotifications creates the contact methods and notification rules of the member and removes those not in the config.
+// Contact methods are only changed when the member has contact methods in the config and notification rules are only changed
+// when the member has a notification profile.
+// Email contact methods are never removed and push contact methods can only be created with the PagerDuty mobile app.
+func (m *Manager) syncNotifications(ctx context.Context, member *Member) error {
+	if len(member.ContactMethods) == 0 && member.NotificationProfile == "" {
+		return nil
+	}
+
+	var liveMethods []*users.ContactMethod
+	var liveRules []*users.NotificationRule
+
+	if member.ID != "" {
+		var err error
+
+		liveMethods, err = m.userManager.GetContactMethods(ctx, member.ID)
+		if err != nil {
+			m.logger.Error("failed to sync users - fetch contact methods failed", zap.Error(err))
+			return err
+		}
+
+		liveRules, err = m.userManager.GetNotificationRules(ctx, member.ID)
+		if err != nil {
+			m.logger.Error("failed to sync users - fetch notification rules failed", zap.Error(err))
+			return err
+		}
+	}
+
+	methods, extraMethods, err := m.syncContactMethods(ctx, member, liveMethods)
+	if err != nil {
+		return err
+	}
+
+	if member.NotificationProfile != "" {
+		err = m.syncNotificationRules(ctx, member, methods, liveRules)
+		if err != nil {
+			return err
+		}
+	}
+
+	// removed last as they may be used by the notification rules that were just replaced
+	for _, method := range extraMethods {
+		change := &Change{
+			Action:   ActionDelete,
+			Resource: resourceContactMethod,
+			Name:     member.Email + "/" + method.Address,
+		}
+
+		methodID := method.ID
+
+		err = m.apply(change, func() error {
+			return m.userManager.DeleteContactMethod(ctx, member.ID, methodID)
+		})
+		if err != nil {
+			m.logger.Error("failed to sync users - delete contact method failed", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// syncContactMethods creates and updates the contact methods of the member.
+// Returns the contact methods the member will have and the live phone and SMS contact methods that are not in the config.
+func (m *Manager) syncContactMethods(ctx context.Context, member *Member, liveMethods []*users.ContactMethod) ([]*users.ContactMethod, []*users.ContactMethod, error) {
+	matched := map[*users.ContactMethod]bool{}
+
+	var created []*users.ContactMethod
+
+	for _, method := range member.ContactMethods {
+		liveMethod := users.FindContactMethod(liveMethods, method)
+		if liveMethod != nil {
+			matched[liveMethod] = true
+
+			if liveMethod.Label == method.Label || method.Label == "" {
+				continue
+			}
+
+			change := &Change{
+				Action:   ActionUpdate,
+				Resource: resourceContactMethod,
+				Name:     member.Email + "/" + method.Address,
+				Fields: []*FieldDiff{
+					{Path: "label", Live: liveMethod.Label, Desired: method.Label},
+				},
+			}
+
+			methodID := liveMethod.ID
+
+			err := m.apply(change, func() error {
+				return m.userManager.UpdateContactMethod(ctx, member.ID, methodID, method)
+			})
+			if err != nil {
+				m.logger.Error("failed to sync users - update contact method failed", zap.Error(err))
+				return nil, nil, err
+			}
+
+			continue
+		}
+
+		if method.Type == contactPush {
+			m.logger.Warn("push contact method not found; it must be set up with the PagerDuty mobile app",
+				zap.String("email", member.Email))
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceContactMethod,
+			Name:     member.Email + "/" + method.Address,
+			Details: []string{
+				"type: " + method.Type,
+				"label: " + method.Label,
+			},
+		}
+
+		newMethod := &users.ContactMethod{
+			Type:        method.GetType(),
+			CountryCode: method.GetCountryCode(),
+			Address:     method.GetAddress(),
+			Label:       method.GetLabel(),
+		}
+
+		err := m.apply(change, func() (addErr error) {
+			newMethod.ID, addErr = m.userManager.AddContactMethod(ctx, member.ID, method)
+			return addErr
+		})
+		if err != nil {
+			m.logger.Error("failed to sync users - add contact method failed", zap.Error(err))
+			return nil, nil, err
+		}
+
+		created = append(created, newMethod)
+	}
+
+	var methods, extraMethods []*users.ContactMethod
+
+	for _, liveMethod := range liveMethods {
+		isManagedType := liveMethod.Type == users.ContactMethodPhone || liveMethod.Type == users.ContactMethodSMS
+		if !matched[liveMethod] && isManagedType && len(member.ContactMethods) > 0 {
+			extraMethods = append(extraMethods, liveMethod)
+			continue
+		}
+
+		methods = append(methods, liveMethod)
+	}
+
+	return append(methods, created...), extraMethods, nil
+}
+
+// syncNotificationRules creates the notification rules of the member's profile and removes any other rules of the
+// urgencies in the profile; rules of other urgencies are kept.
+// Each rule uses the first of the member's contact methods with the type of the rule.
+// When any rule of the profile is skipped (as the member has no contact method of its type) no rules are removed,
+// so that the member is never left without a way to be paged.
+func (m *Manager) syncNotificationRules(ctx context.Context, member *Member, methods []*users.ContactMethod, liveRules []*users.NotificationRule) error {
+	matched := map[*users.NotificationRule]bool{}
+	profileUrgencies := map[string]bool{}
+	skipped := false
+
+	for _, rule := range m.companyConfig.NotificationProfiles[member.NotificationProfile] {
+		profileUrgencies[rule.Urgency] = true
+
+		method := findMethodOfType(methods, contactTypesToPD[rule.ContactMethod])
+		if method == nil {
+			m.logger.Warn("skipping notification rule as the user has no contact method of the required type",
+				zap.String("email", member.Email),
+				zap.String("type", rule.ContactMethod))
+
+			skipped = true
+			continue
+		}
+
+		reqRule := &notificationRule{
+			NotificationRule: rule,
+			contactMethod:    method,
+		}
+
+		liveRule := users.FindNotificationRule(liveRules, reqRule)
+		if liveRule != nil {
+			matched[liveRule] = true
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceNotificationRule,
+			Name:     member.Email + "/" + rule.String(),
+			Details: []string{
+				"contact method: " + diff.IDs(method.ID)[0],
+			},
+		}
+
+		err := m.apply(change, func() error {
+			_, addErr := m.userManager.AddNotificationRule(ctx, member.ID, reqRule)
+			return addErr
+		})
+		if err != nil {
+			m.logger.Error("failed to sync users - add notification rule failed", zap.Error(err))
+			return err
+		}
+	}
+
+	if skipped {
+		m.logger.Warn("not removing notification rules as rules of the profile were skipped", zap.String("email", member.Email))
+		return nil
+	}
+
+	for _, liveRule := range liveRules {
+		if matched[liveRule] || !profileUrgencies[liveRule.Urgency] {
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionDelete,
+			Resource: resourceNotificationRule,
+			Name:     member.Email + "/" + liveRuleName(liveRule),
+		}
+
+		ruleID := liveRule.ID
+
+		err := m.apply(change, func() error {
+			return m.userManager.DeleteNotificationRule(ctx, member.ID, ruleID)
+		})
+		if err != nil {
+			m.logger.Error("failed to sync users - delete notification rule failed", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+func findMethodOfType(methods []*users.ContactMethod, methodType string) *users.ContactMethod {
+	for _, method := range methods {
+		if method.Type == methodType {
+			return method
+		}
+	}
+
+	return nil
+}
+
+func liveRuleName(rule *users.NotificationRule) string {
+	name := rule.Urgency + " urgency after " + strconv.Itoa(rule.StartDelayInMinutes) + " min"
+
+	if rule.ContactMethod != nil {
+		name += " via " + rule.ContactMethod.Type
+	}
+
+	return name
+}
+
+// contactMethodsKey describes the contact methods so that they can be compared (empty when there are none)
+func contactMethodsKey(methods []*ContactMethod) string {
+	var out []string
+
+	for _, method := range methods {
+		out = append(out, fmt.Sprintf("%s +%d %s (%s)", method.Type, method.CountryCode, method.Address, method.Label))
+	}
+
+	return strings.Join(out, ", ")
+}
+
+type ContactMethod struct {
+	Type        string `json:"type"`
+	CountryCode int    `json:"country_code"`
+	Address     string `json:"address"`
+	Label       string `json:"label"`
+}
+
+func (c *ContactMethod) GetType() string {
+	return contactTypesToPD[c.Type]
+}
+
+func (c *ContactMethod) GetCountryCode() int {
+	return c.CountryCode
+}
+
+func (c *ContactMethod) GetAddress() string {
+	return c.Address
+}
+
+func (c *ContactMethod) GetLabel() string {
+	return c.Label
+}
+
+// NotificationRule notifies the user with the contact method (email, phone, sms or push) after the delay
+type NotificationRule struct {
+	Urgency       string `json:"urgency"`
+	ContactMethod string `json:"contact_method"`
+	DelayMinutes  int    `json:"delay_minutes"`
+}
+
+func (n *NotificationRule) String() string {
+	return n.Urgency + " urgency after " + strconv.Itoa(n.DelayMinutes) + " min via " + n.ContactMethod
+}
+
+// notificationRule is a rule from a notification profile with the member's contact method
+type notificationRule struct {
+	*NotificationRule
+	contactMethod *users.ContactMethod
+}
+
+func (n *notificationRule) GetUrgency() string {
+	return n.Urgency
+}
+
+func (n *notificationRule) GetDelayInMinutes() int {
+	return n.DelayMinutes
+}
+
+func (n *notificationRule) GetContactMethodID() string {
+	return n.contactMethod.ID
+}
+
+func (n *notificationRule) GetContactMethodType() string {
+	return n.contactMethod.Type
+}
@@ -0,0 +1,172 @@
+package pdmanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/users"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_syncNotifications(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	// mocks
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method, "dry-run must not make changes")
+
+		if strings.HasSuffix(req.URL.Path, "/contact_methods") {
+			_, _ = resp.Write([]byte(`{"contact_methods": [
+				{"id": "EMAIL", "type": "email_contact_method", "address": "paul@beatles.com"},
+				{"id": "OLD", "type": "sms_contact_method", "country_code": 44, "address": "7700900001"},
+				{"id": "PUSH", "type": "push_notification_contact_method", "address": "iPhone"}
+			]}`))
+			return
+		}
+
+		_, _ = resp.Write([]byte(`{"notification_rules": [
+			{"id": "R1", "urgency": "high", "start_delay_in_minutes": 0, "contact_method": {"id": "PUSH", "type": "push_notification_contact_method"}},
+			{"id": "R2", "urgency": "high", "start_delay_in_minutes": 0, "contact_method": {"id": "EMAIL", "type": "email_contact_method"}}
+		]}`))
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		baseURL: testServer.URL,
+		dryRun:  true,
+	}
+
+	member := &Member{
+		ID:    "PAUL",
+		Email: "paul@beatles.com",
+		ContactMethods: []*ContactMethod{
+			{Type: contactPush},
+			{Type: contactSMS, CountryCode: 44, Address: "7700900002", Label: "Mobile"},
+		},
+		NotificationProfile: "on-call",
+	}
+
+	manager := New(cfg, logger)
+	manager.userManager = users.New(cfg, logger)
+	manager.companyConfig.NotificationProfiles = map[string][]*NotificationRule{
+		"on-call": {
+			{Urgency: "high", ContactMethod: contactPush, DelayMinutes: 0},
+			{Urgency: "high", ContactMethod: contactSMS, DelayMinutes: 2},
+		},
+	}
+
+	// call object under test
+	resultErr := manager.syncNotifications(ctx, member)
+	require.NoError(t, resultErr)
+
+	// validation
+	expected := []string{
+		`create contact method "paul@beatles.com/7700900002"`,
+		`create notification rule "paul@beatles.com/high urgency after 2 min via sms"`,
+		`delete notification rule "paul@beatles.com/high urgency after 0 min via email_contact_method"`,
+		`delete contact method "paul@beatles.com/7700900001"`,
+	}
+
+	var result []string
+	for _, change := range manager.Plan().Changes {
+		result = append(result, change.String()[2:])
+	}
+
+	assert.Equal(t, expected, result)
+}
+
+func TestManager_syncNotificationRules(t *testing.T) {
+	methods := []*users.ContactMethod{
+		{ID: "PUSH", Type: users.ContactMethodPush},
+		{ID: "EMAIL", Type: users.ContactMethodEmail},
+	}
+
+	liveRules := []*users.NotificationRule{
+		{ID: "R1", Urgency: "high", StartDelayInMinutes: 0, ContactMethod: &users.ContactMethod{ID: "EMAIL", Type: users.ContactMethodEmail}},
+		{ID: "R2", Urgency: "low", StartDelayInMinutes: 0, ContactMethod: &users.ContactMethod{ID: "EMAIL", Type: users.ContactMethodEmail}},
+	}
+
+	scenarios := []struct {
+		desc     string
+		profile  []*NotificationRule
+		expected []string
+	}{
+		{
+			desc: "only the urgencies of the profile are replaced",
+			profile: []*NotificationRule{
+				{Urgency: "high", ContactMethod: contactPush, DelayMinutes: 0},
+			},
+			expected: []string{
+				`create notification rule "paul@beatles.com/high urgency after 0 min via push"`,
+				`delete notification rule "paul@beatles.com/high urgency after 0 min via email_contact_method"`,
+			},
+		},
+		{
+			desc: "rules are not removed when a rule of the profile is skipped",
+			profile: []*NotificationRule{
+				{Urgency: "high", ContactMethod: contactPush, DelayMinutes: 0},
+				{Urgency: "high", ContactMethod: contactSMS, DelayMinutes: 2},
+				{Urgency: "low", ContactMethod: contactPush, DelayMinutes: 0},
+			},
+			expected: []string{
+				`create notification rule "paul@beatles.com/high urgency after 0 min via push"`,
+				`create notification rule "paul@beatles.com/low urgency after 0 min via push"`,
+			},
+		},
+		{
+			desc: "rules are not removed when every rule of the profile is skipped",
+			profile: []*NotificationRule{
+				{Urgency: "high", ContactMethod: contactSMS, DelayMinutes: 0},
+			},
+			expected: nil,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			cfg := &testConfig{
+				dryRun: true,
+			}
+
+			member := &Member{
+				ID:                  "PAUL",
+				Email:               "paul@beatles.com",
+				NotificationProfile: "on-call",
+			}
+
+			manager := New(cfg, logger)
+			manager.companyConfig.NotificationProfiles = map[string][]*NotificationRule{
+				"on-call": scenario.profile,
+			}
+
+			// call object under test
+			resultErr := manager.syncNotificationRules(ctx, member, methods, liveRules)
+			require.NoError(t, resultErr)
+
+			// validation
+			var result []string
+			for _, change := range manager.Plan().Changes {
+				result = append(result, change.String()[2:])
+			}
+
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
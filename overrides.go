@@ -0,0 +1,314 @@
+package pdmanager
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"strings"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/diff"
+	"github.com/corsc/pagerduty-manager/internal/pd"
+	"github.com/corsc/pagerduty-manager/internal/schedules"
+	"github.com/corsc/pagerduty-manager/internal/users"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v3"
+)
+
+const resourceOverride = "override"
+
+// ParseOverrides attempts to parse the overrides file.
+// Parse must be called first as teams are resolved to their schedules using the config.
+func (m *Manager) ParseOverrides(_ context.Context, filename string) error {
+	m.logger.Debug("loading overrides from file", zap.String("file", filename))
+
+	fileContents, err := ioutil.ReadFile(filename)
+	if err != nil {
+		return fmt.Errorf("failed to read overrides file with err: %w", err)
+	}
+
+	overrides := &overridesConfig{}
+
+	root, problems, err := decodeConfig(filename, fileContents, overrides, 0)
+	if err != nil {
+		return err
+	}
+
+	v := &validator{
+		problems: problems,
+	}
+
+	m.validateOverrides(v, overrides, filename, root)
+
+	err = v.err()
+	if err != nil {
+		return err
+	}
+
+	m.overridesConfig = overrides
+
+	return nil
+}
+
+// validateOverrides records every problem with the overrides and leave, with the file and line of each of them
+func (m *Manager) validateOverrides(v *validator, overrides *overridesConfig, filename string, root *yaml.Node) {
+	lines := itemLines(root, "overrides")
+
+	for index, override := range overrides.Overrides {
+		v.source = itemSource(filename, lines, index)
+		path := fmt.Sprintf("overrides[%d]", index)
+
+		if override.User == "" {
+			v.errorf(path+".user", "missing user")
+		}
+
+		if !override.End.After(override.Start) {
+			v.errorf(path+".end", "override for '%s' must end after it starts", override.User)
+		}
+
+		switch {
+		case override.Schedule != "" && override.Team != "":
+			v.errorf(path+".schedule", "override for '%s' must have a team or a schedule (not both)", override.User)
+
+		case override.Schedule != "":
+			override.scheduleName = override.Schedule
+
+		case override.Team != "":
+			team := m.findTeam(override.Team)
+			if team == nil {
+				v.errorf(path+".team", "unknown team '%s' in override for '%s'", override.Team, override.User)
+				continue
+			}
+
+			override.scheduleName = team.ScheduleName
+
+		default:
+			v.errorf(path+".team", "override for '%s' must have a team or a schedule", override.User)
+		}
+	}
+
+	lines = itemLines(root, "leave")
+
+	for index, leave := range overrides.Leave {
+		v.source = itemSource(filename, lines, index)
+		path := fmt.Sprintf("leave[%d]", index)
+
+		if leave.User == "" {
+			v.errorf(path+".user", "missing user")
+		}
+
+		if !leave.End.After(leave.Start) {
+			v.errorf(path+".end", "leave for '%s' must end after it starts", leave.User)
+		}
+	}
+
+	v.source = ""
+}
+
+// itemSource returns the file and line of the item of a list (or only the file when the line is not known)
+func itemSource(filename string, lines []int, index int) string {
+	if index < len(lines) {
+		return fmt.Sprintf("%s:%d", filename, lines[index])
+	}
+
+	return filename
+}
+
+func (m *Manager) findTeam(name string) *Team {
+	for _, team := range m.companyConfig.Teams {
+		if pd.NamesMatch(name, team.Name, m.cfg.CaseInsensitiveNames()) {
+			return team
+		}
+	}
+
+	return nil
+}
+
+// SyncOverrides creates the overrides in the overrides file that do not yet exist.
+// Overrides that have already ended are ignored. When pruning, the upcoming overrides of the schedules in the file that
+// are not in the file are removed.
+// A warning is logged for every override that puts someone on-call during their leave.
+func (m *Manager) SyncOverrides(ctx context.Context) error {
+	m.userManager = users.New(m.cfg, m.logger)
+	m.scheduleManager = schedules.New(m.cfg, m.logger)
+
+	m.warnOverridesDuringLeave()
+
+	now := time.Now()
+
+	var scheduleNames []string
+	bySchedule := map[string][]*Override{}
+
+	for _, override := range m.overridesConfig.Overrides {
+		if !override.End.After(now) {
+			m.logger.Debug("skipping override that has ended", zap.String("user", override.User))
+			continue
+		}
+
+		if bySchedule[override.scheduleName] == nil {
+			scheduleNames = append(scheduleNames, override.scheduleName)
+		}
+
+		bySchedule[override.scheduleName] = append(bySchedule[override.scheduleName], override)
+	}
+
+	for _, scheduleName := range scheduleNames {
+		err := m.syncScheduleOverrides(ctx, scheduleName, bySchedule[scheduleName], now)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (m *Manager) syncScheduleOverrides(ctx context.Context, scheduleName string, overrides []*Override, now time.Time) error {
+	fetchedSchedule, err := m.scheduleManager.GetByName(ctx, scheduleName)
+	if err != nil {
+		m.logger.Error("failed to sync overrides - fetch schedule failed", zap.String("schedule", scheduleName), zap.Error(err))
+		return err
+	}
+
+	until := now
+	for _, override := range overrides {
+		if override.End.After(until) {
+			until = override.End
+		}
+	}
+
+	liveOverrides, err := m.scheduleManager.ListOverrides(ctx, fetchedSchedule.ID, now, until)
+	if err != nil {
+		m.logger.Error("failed to sync overrides - fetch overrides failed", zap.Error(err))
+		return err
+	}
+
+	matched := map[*schedules.Override]bool{}
+
+	for _, override := range overrides {
+		fetchedUser, err := m.userManager.GetByEmail(ctx, override.User)
+		if err != nil {
+			m.logger.Error("failed to sync overrides - fetch user failed", zap.String("email", override.User), zap.Error(err))
+			return err
+		}
+
+		override.userID = fetchedUser.ID
+
+		liveOverride := schedules.FindOverride(liveOverrides, override)
+		if liveOverride != nil {
+			matched[liveOverride] = true
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionCreate,
+			Resource: resourceOverride,
+			Name:     scheduleName + "/" + override.User,
+			Details: []string{
+				"start: " + override.Start.Format(time.RFC3339),
+				"end: " + override.End.Format(time.RFC3339),
+			},
+		}
+
+		thisOverride := override
+
+		err = m.apply(change, func() error {
+			_, addErr := m.scheduleManager.AddOverride(ctx, fetchedSchedule.ID, thisOverride)
+			return addErr
+		})
+		if err != nil {
+			m.logger.Error("failed to sync overrides - add override failed", zap.Error(err))
+			return err
+		}
+	}
+
+	if !m.cfg.Prune() {
+		return nil
+	}
+
+	for _, liveOverride := range liveOverrides {
+		if matched[liveOverride] {
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionDelete,
+			Resource: resourceOverride,
+			Name:     scheduleName + "/" + diff.IDs(liveOverride.GetUserID())[0],
+			Details: []string{
+				"start: " + liveOverride.Start.Format(time.RFC3339),
+				"end: " + liveOverride.End.Format(time.RFC3339),
+			},
+		}
+
+		overrideID := liveOverride.ID
+
+		err = m.apply(change, func() error {
+			return m.scheduleManager.DeleteOverride(ctx, fetchedSchedule.ID, overrideID)
+		})
+		if err != nil {
+			m.logger.Error("failed to sync overrides - delete override failed", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// warnOverridesDuringLeave logs a warning for each override that overlaps with leave of the same user
+func (m *Manager) warnOverridesDuringLeave() {
+	for _, override := range m.overridesConfig.Overrides {
+		for _, leave := range m.overridesConfig.Leave {
+			if !strings.EqualFold(override.User, leave.User) {
+				continue
+			}
+
+			if override.Start.Before(leave.End) && leave.Start.Before(override.End) {
+				m.logger.Warn("override puts user on-call during their leave",
+					zap.String("email", override.User),
+					zap.String("schedule", override.scheduleName),
+					zap.Time("start", override.Start),
+					zap.Time("end", override.End))
+			}
+		}
+	}
+}
+
+type overridesConfig struct {
+	Overrides []*Override `json:"overrides"`
+	Leave     []*Leave    `json:"leave"`
+}
+
+// Override puts the user on-call for the schedule of the team (or the named schedule) between start and end
+type Override struct {
+	// User is the email of the user
+	User string `json:"user"`
+	// Team is the name of a team in the config
+	Team string `json:"team"`
+	// Schedule is the name of a PagerDuty schedule (for schedules not managed by the config)
+	Schedule string    `json:"schedule"`
+	Start    time.Time `json:"start"`
+	End      time.Time `json:"end"`
+
+	scheduleName string
+	userID       string
+}
+
+func (o *Override) GetUserID() string {
+	return o.userID
+}
+
+func (o *Override) GetStart() time.Time {
+	return o.Start
+}
+
+func (o *Override) GetEnd() time.Time {
+	return o.End
+}
+
+// Leave is a period the user is not available to be on-call
+type Leave struct {
+	// User is the email of the user
+	User  string    `json:"user"`
+	Start time.Time `json:"start"`
+	End   time.Time `json:"end"`
+}
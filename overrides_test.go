@@ -0,0 +1,144 @@
+package pdmanager
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_ParseOverrides(t *testing.T) {
+	scenarios := []struct {
+		desc           string
+		in             string
+		expectErr      bool
+		expectedErrors []string
+	}{
+		{
+			desc:      "happy path",
+			in:        "./test_data/overrides.json",
+			expectErr: false,
+		},
+		{
+			desc:      "sad path - every problem is reported",
+			in:        "./test_data/invalid_overrides.json",
+			expectErr: true,
+			expectedErrors: []string{
+				"./test_data/invalid_overrides.json:11 overrides[1].usr",
+				"./test_data/invalid_overrides.json:3 overrides[0].team",
+				"./test_data/invalid_overrides.json:9 overrides[1].user",
+				"./test_data/invalid_overrides.json:9 overrides[1].end",
+				"./test_data/invalid_overrides.json:17 leave[0].user",
+			},
+		},
+		{
+			desc:      "sad path - invalid file",
+			in:        "./test_data/invalid.json",
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			cfg := &testConfig{
+				filename: "./test_data/simple.json",
+			}
+
+			logger, _ := zap.NewDevelopment()
+
+			manager := New(cfg, logger)
+			require.NoError(t, manager.Parse(ctx))
+
+			// call object under test
+			resultErr := manager.ParseOverrides(ctx, scenario.in)
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+
+			if len(scenario.expectedErrors) == 0 {
+				return
+			}
+
+			validationErr := &ValidationError{}
+			require.True(t, errors.As(resultErr, &validationErr))
+
+			var result []string
+			for _, problem := range validationErr.Problems {
+				result = append(result, problem.Source+" "+problem.Path)
+			}
+
+			assert.Equal(t, scenario.expectedErrors, result)
+		})
+	}
+}
+
+func TestManager_SyncOverrides_DryRun(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	// mocks
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method, "dry-run must not make changes")
+
+		switch {
+		case strings.HasSuffix(req.URL.Path, "/overrides"):
+			_, _ = resp.Write([]byte(`{"overrides": [
+				{"id": "O1", "start": "2099-12-24T20:00:00+11:00", "end": "2099-12-27T20:00:00+11:00", "user": {"id": "RINGO"}},
+				{"id": "O2", "start": "2099-12-25T09:00:00Z", "end": "2099-12-26T09:00:00Z", "user": {"id": "PAUL"}}
+			]}`))
+
+		case req.URL.Path == "/schedules":
+			_, _ = resp.Write([]byte(`{"schedules": [{"id": "S1", "name": "Test Team A Schedule"}]}`))
+
+		case req.URL.Query().Get("query") == "ringo@beatles.com":
+			_, _ = resp.Write([]byte(`{"users": [{"id": "RINGO", "email": "ringo@beatles.com"}]}`))
+
+		default:
+			_, _ = resp.Write([]byte(`{"users": [{"id": "GEORGE", "email": "george@beatles.com"}]}`))
+		}
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		filename: "./test_data/simple.json",
+		baseURL:  testServer.URL,
+		dryRun:   true,
+		prune:    true,
+	}
+
+	manager := New(cfg, logger)
+	require.NoError(t, manager.Parse(ctx))
+	require.NoError(t, manager.ParseOverrides(ctx, "./test_data/overrides.json"))
+
+	// call object under test
+	resultErr := manager.SyncOverrides(ctx)
+	require.NoError(t, resultErr)
+
+	// validation
+	expected := []string{
+		`create override "Test Team A Schedule/george@beatles.com"`,
+		`delete override "Test Team A Schedule/PAUL"`,
+	}
+
+	var result []string
+	for _, change := range manager.Plan().Changes {
+		result = append(result, change.String()[2:])
+	}
+
+	assert.Equal(t, expected, result)
+}
@@ -0,0 +1,101 @@
+package pdmanager
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/corsc/pagerduty-manager/internal/diff"
+)
+
+// Action is the type of change a sync would make to a PagerDuty resource
+type Action string
+
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionDelete Action = "delete"
+)
+
+var actionSymbols = map[Action]string{
+	ActionCreate: "+",
+	ActionUpdate: "~",
+	ActionDelete: "-",
+}
+
+// FieldDiff is the difference between the live and desired value of a single field of a PagerDuty resource
+type FieldDiff = diff.Field
+
+// Change is a single change to a PagerDuty resource.
+// Creates describe the new resource with Details, updates list each of the differing Fields.
+type Change struct {
+	Action   Action
+	Resource string
+	Name     string
+	Details  []string
+	Fields   []*FieldDiff
+}
+
+func (c *Change) String() string {
+	return fmt.Sprintf("%s %s %s %q", actionSymbols[c.Action], c.Action, c.Resource, c.Name)
+}
+
+// Plan is the list of changes a sync has made (or would make during a dry-run) in the order they are applied
+type Plan struct {
+	Changes []*Change
+}
+
+func (p *Plan) add(change *Change) {
+	p.Changes = append(p.Changes, change)
+}
+
+// IsEmpty returns true when the plan contains no changes
+func (p *Plan) IsEmpty() bool {
+	return len(p.Changes) == 0
+}
+
+// Count returns the number of changes with the supplied action
+func (p *Plan) Count(action Action) int {
+	total := 0
+
+	for _, change := range p.Changes {
+		if change.Action == action {
+			total++
+		}
+	}
+
+	return total
+}
+
+// Print writes a human readable version of the plan to the supplied writer
+func (p *Plan) Print(writer io.Writer) error {
+	if p.IsEmpty() {
+		_, err := fmt.Fprintln(writer, "No changes. PagerDuty matches the config.")
+		return err
+	}
+
+	for _, change := range p.Changes {
+		_, err := fmt.Fprintln(writer, change.String())
+		if err != nil {
+			return err
+		}
+
+		for _, detail := range change.Details {
+			_, err = fmt.Fprintf(writer, "    %s\n", detail)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, field := range change.Fields {
+			_, err = fmt.Fprintf(writer, "    %s\n", field)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := fmt.Fprintf(writer, "\nPlan: %d to create, %d to update, %d to delete.\n",
+		p.Count(ActionCreate), p.Count(ActionUpdate), p.Count(ActionDelete))
+
+	return err
+}
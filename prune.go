@@ -0,0 +1,345 @@
+package pdmanager
+
+import (
+	"context"
+	"sort"
+
+	"github.com/corsc/pagerduty-manager/internal/escalations"
+	"github.com/corsc/pagerduty-manager/internal/naming"
+	"github.com/corsc/pagerduty-manager/internal/schedules"
+	"github.com/corsc/pagerduty-manager/internal/services"
+	"go.uber.org/zap"
+)
+
+const serviceStatusDisabled = "disabled"
+
+// Prune removes objects that belong to the teams in the config but are no longer in the config.
+// It does nothing unless prune is enabled and should be called after all of the Sync Methods.
+// Only objects that are managed by this tool (created by it or adopted) are removed.
+//
+// Services are disabled (to keep their incident history) and escalation policies and schedules are deleted.
+// Users that have been removed from all of their teams are only deleted when user pruning is also enabled, as deleting
+// a user cannot be undone; otherwise a warning is logged.
+// Escalation policies still used by a service and schedules still used by an escalation policy are kept.
+// Objects with a legacy name of a team resource are kept, as they are renamed by the sync.
+func (m *Manager) Prune(ctx context.Context) error {
+	if !m.cfg.Prune() {
+		return nil
+	}
+
+	managedTeamIDs := map[string]bool{}
+	for _, team := range m.companyConfig.Teams {
+		if team.ID != "" && team.Managed {
+			managedTeamIDs[team.ID] = true
+		}
+	}
+
+	liveServices, err := m.pruneServices(ctx, managedTeamIDs)
+	if err != nil {
+		return err
+	}
+
+	livePolicies, err := m.prunePolicies(ctx, managedTeamIDs, liveServices)
+	if err != nil {
+		return err
+	}
+
+	err = m.pruneSchedules(ctx, managedTeamIDs, livePolicies)
+	if err != nil {
+		return err
+	}
+
+	return m.pruneUsers(ctx)
+}
+
+// pruneServices disables any services of the managed teams that are not in the config.
+// Returns all of the services.
+func (m *Manager) pruneServices(ctx context.Context, managedTeamIDs map[string]bool) ([]*services.Service, error) {
+	desiredNames := map[string]bool{}
+	for _, team := range m.companyConfig.Teams {
+		desiredNames[team.ServiceName] = true
+
+		for _, legacyName := range m.naming.LegacyNames(naming.ResourceTeamService, team.Name) {
+			desiredNames[legacyName] = true
+		}
+
+		for _, service := range team.Services {
+			desiredNames[service.Name] = true
+		}
+	}
+
+	liveServices, err := m.serviceManager.List(ctx)
+	if err != nil {
+		m.logger.Error("failed to prune services - list services failed", zap.Error(err))
+		return nil, err
+	}
+
+	for _, liveService := range liveServices {
+		if desiredNames[liveService.Name] || liveService.Status == serviceStatusDisabled {
+			continue
+		}
+
+		if !inTeams(managedTeamIDs, serviceTeamIDs(liveService)) {
+			continue
+		}
+
+		if !m.isManaged(AdoptService, resourceService, liveService.Name, liveService.Description) {
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionUpdate,
+			Resource: resourceService,
+			Name:     liveService.Name,
+			Fields: []*FieldDiff{
+				{
+					Path:    "status",
+					Live:    liveService.Status,
+					Desired: serviceStatusDisabled,
+				},
+			},
+		}
+
+		serviceID := liveService.ID
+
+		err = m.apply(change, func() error {
+			return m.serviceManager.Disable(ctx, serviceID)
+		})
+		if err != nil {
+			m.logger.Error("failed to prune services - disable service failed", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	return liveServices, nil
+}
+
+// prunePolicies deletes any escalation policies of the managed teams that are not in the config.
+// Returns the policies that remain.
+func (m *Manager) prunePolicies(ctx context.Context, managedTeamIDs map[string]bool, liveServices []*services.Service) ([]*escalations.EscalationPolicy, error) {
+	desiredNames := map[string]bool{}
+	for _, team := range m.companyConfig.Teams {
+		desiredNames[team.PolicyName] = true
+
+		for _, legacyName := range m.naming.LegacyNames(naming.ResourceEscalation, team.Name) {
+			desiredNames[legacyName] = true
+		}
+	}
+
+	usedPolicyIDs := map[string]bool{}
+	for _, liveService := range liveServices {
+		if liveService.EscalationPolicy != nil {
+			usedPolicyIDs[liveService.EscalationPolicy.ID] = true
+		}
+	}
+
+	livePolicies, err := m.escalationManager.List(ctx)
+	if err != nil {
+		m.logger.Error("failed to prune escalations - list escalations failed", zap.Error(err))
+		return nil, err
+	}
+
+	var remaining []*escalations.EscalationPolicy
+
+	for _, livePolicy := range livePolicies {
+		if desiredNames[livePolicy.Name] || !inTeams(managedTeamIDs, policyTeamIDs(livePolicy)) ||
+			!m.isManaged(AdoptEscalation, resourceEscalation, livePolicy.Name, livePolicy.Description) {
+			remaining = append(remaining, livePolicy)
+			continue
+		}
+
+		if usedPolicyIDs[livePolicy.ID] {
+			m.logger.Warn("not pruning escalation policy as it is used by a service", zap.String("name", livePolicy.Name))
+
+			remaining = append(remaining, livePolicy)
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionDelete,
+			Resource: resourceEscalation,
+			Name:     livePolicy.Name,
+		}
+
+		policyID := livePolicy.ID
+
+		err = m.apply(change, func() error {
+			return m.escalationManager.Delete(ctx, policyID)
+		})
+		if err != nil {
+			m.logger.Error("failed to prune escalations - delete escalation failed", zap.Error(err))
+			return nil, err
+		}
+	}
+
+	return remaining, nil
+}
+
+// pruneSchedules deletes any schedules of the managed teams that are not in the config
+func (m *Manager) pruneSchedules(ctx context.Context, managedTeamIDs map[string]bool, livePolicies []*escalations.EscalationPolicy) error {
+	desiredNames := map[string]bool{}
+	for _, team := range m.companyConfig.Teams {
+		desiredNames[team.ScheduleName] = true
+
+		for _, legacyName := range m.naming.LegacyNames(naming.ResourceSchedule, team.Name) {
+			desiredNames[legacyName] = true
+		}
+	}
+
+	usedScheduleIDs := map[string]bool{}
+	for _, livePolicy := range livePolicies {
+		for _, scheduleID := range livePolicy.GetScheduleIDs() {
+			usedScheduleIDs[scheduleID] = true
+		}
+	}
+
+	liveSchedules, err := m.scheduleManager.List(ctx)
+	if err != nil {
+		m.logger.Error("failed to prune schedules - list schedules failed", zap.Error(err))
+		return err
+	}
+
+	for _, liveSchedule := range liveSchedules {
+		if desiredNames[liveSchedule.Name] || !inTeams(managedTeamIDs, scheduleTeamIDs(liveSchedule)) ||
+			!m.isManaged(AdoptSchedule, resourceSchedule, liveSchedule.Name, liveSchedule.Description) {
+			continue
+		}
+
+		if usedScheduleIDs[liveSchedule.ID] {
+			m.logger.Warn("not pruning schedule as it is used by an escalation policy", zap.String("name", liveSchedule.Name))
+			continue
+		}
+
+		change := &Change{
+			Action:   ActionDelete,
+			Resource: resourceSchedule,
+			Name:     liveSchedule.Name,
+		}
+
+		scheduleID := liveSchedule.ID
+
+		err = m.apply(change, func() error {
+			return m.scheduleManager.Delete(ctx, scheduleID)
+		})
+		if err != nil {
+			m.logger.Error("failed to prune schedules - delete schedule failed", zap.Error(err))
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pruneUsers deletes the users that were removed from teams (during SyncTeams) and are no longer in any team
+func (m *Manager) pruneUsers(ctx context.Context) error {
+	desiredUserIDs := map[string]bool{}
+	for _, team := range m.companyConfig.Teams {
+		for _, member := range team.Members {
+			desiredUserIDs[member.ID] = true
+		}
+	}
+
+	var userIDs []string
+
+	for userID := range m.removedFrom {
+		if desiredUserIDs[userID] {
+			// still in other teams in the config
+			continue
+		}
+
+		userIDs = append(userIDs, userID)
+	}
+
+	sort.Strings(userIDs)
+
+	for _, userID := range userIDs {
+		err := m.pruneUser(ctx, userID, m.removedFrom[userID])
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// pruneUser deletes the user when they are no longer in any team (and user pruning is enabled)
+func (m *Manager) pruneUser(ctx context.Context, userID string, removedFromTeamIDs map[string]bool) error {
+	user, err := m.userManager.Get(ctx, userID)
+	if err != nil {
+		m.logger.Error("failed to prune users - fetch user failed", zap.Error(err))
+		return err
+	}
+
+	for _, team := range user.Teams {
+		if !removedFromTeamIDs[team.ID] {
+			// still in other teams
+			return nil
+		}
+	}
+
+	if !m.isManaged(AdoptUser, resourceUser, user.Email, user.Description) {
+		return nil
+	}
+
+	if !m.cfg.PruneUsers() {
+		m.logger.Warn("not deleting user that is no longer in any team as user pruning is disabled", zap.String("email", user.Email))
+		return nil
+	}
+
+	change := &Change{
+		Action:   ActionDelete,
+		Resource: resourceUser,
+		Name:     user.Email,
+	}
+
+	err = m.apply(change, func() error {
+		return m.userManager.Delete(ctx, userID)
+	})
+	if err != nil {
+		m.logger.Error("failed to prune users - delete user failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+// inTeams returns true when any of the team IDs is one of the managed teams
+func inTeams(managedTeamIDs map[string]bool, teamIDs []string) bool {
+	for _, teamID := range teamIDs {
+		if managedTeamIDs[teamID] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func serviceTeamIDs(service *services.Service) []string {
+	var out []string
+
+	for _, team := range service.Teams {
+		out = append(out, team.ID)
+	}
+
+	return out
+}
+
+func policyTeamIDs(policy *escalations.EscalationPolicy) []string {
+	var out []string
+
+	for _, team := range policy.Teams {
+		out = append(out, team.ID)
+	}
+
+	return out
+}
+
+func scheduleTeamIDs(schedule *schedules.Schedule) []string {
+	var out []string
+
+	for _, team := range schedule.Teams {
+		out = append(out, team.ID)
+	}
+
+	return out
+}
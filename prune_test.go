@@ -0,0 +1,197 @@
+package pdmanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/corsc/pagerduty-manager/internal/escalations"
+	"github.com/corsc/pagerduty-manager/internal/ownership"
+	"github.com/corsc/pagerduty-manager/internal/schedules"
+	"github.com/corsc/pagerduty-manager/internal/services"
+	"github.com/corsc/pagerduty-manager/internal/users"
+)
+
+func TestManager_Prune(t *testing.T) {
+	scenarios := []struct {
+		desc              string
+		dryRun            bool
+		pruneUsers        bool
+		expectedPlan      []string
+		expectedMutations []string
+	}{
+		{
+			desc:   "dry-run",
+			dryRun: true,
+			expectedPlan: []string{
+				`update service "Old API"`,
+				`delete escalation policy "Unused Escalation"`,
+				`delete schedule "Old Schedule"`,
+			},
+		},
+		{
+			desc:       "dry-run with user pruning",
+			dryRun:     true,
+			pruneUsers: true,
+			expectedPlan: []string{
+				`update service "Old API"`,
+				`delete escalation policy "Unused Escalation"`,
+				`delete schedule "Old Schedule"`,
+				`delete user "ringo@beatles.com"`,
+			},
+		},
+		{
+			desc: "prune without user pruning",
+			expectedPlan: []string{
+				`update service "Old API"`,
+				`delete escalation policy "Unused Escalation"`,
+				`delete schedule "Old Schedule"`,
+			},
+			expectedMutations: []string{
+				"PUT /services/OLDAPI",
+				"DELETE /escalation_policies/UNUSED",
+				"DELETE /schedules/OLD",
+			},
+		},
+		{
+			desc:       "prune with user pruning",
+			pruneUsers: true,
+			expectedPlan: []string{
+				`update service "Old API"`,
+				`delete escalation policy "Unused Escalation"`,
+				`delete schedule "Old Schedule"`,
+				`delete user "ringo@beatles.com"`,
+			},
+			expectedMutations: []string{
+				"PUT /services/OLDAPI",
+				"DELETE /escalation_policies/UNUSED",
+				"DELETE /schedules/OLD",
+				"DELETE /users/RINGO",
+			},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+			defer cancel()
+
+			logger, _ := zap.NewDevelopment()
+
+			// mocks
+			var mutex sync.Mutex
+			var mutations []string
+
+			testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+				if req.Method != http.MethodGet {
+					mutex.Lock()
+					mutations = append(mutations, req.Method+" "+req.URL.Path)
+					mutex.Unlock()
+
+					resp.WriteHeader(http.StatusNoContent)
+					return
+				}
+
+				response, ok := pruneResponses[req.URL.Path]
+				require.True(t, ok, "unexpected request %s", req.URL.Path)
+
+				_, _ = resp.Write([]byte(response))
+			}))
+			defer testServer.Close()
+
+			cfg := &testConfig{
+				baseURL:    testServer.URL,
+				dryRun:     scenario.dryRun,
+				prune:      true,
+				pruneUsers: scenario.pruneUsers,
+			}
+
+			manager := New(cfg, logger)
+			manager.companyConfig = &companyConfig{
+				Teams: []*Team{
+					{
+						ID:       "BOOKING",
+						Name:     "Booking",
+						Managed:  true,
+						Services: []*Service{{Name: "Booking API"}},
+						Members:  []*Member{{ID: "PAUL", Email: "paul@beatles.com"}},
+					},
+				},
+				Naming: Naming{
+					Legacy: map[string][]string{
+						"schedule":     {"{team} Rota"},
+						"team_service": {"{team} Service"},
+					},
+				},
+			}
+			require.NoError(t, manager.applyNaming())
+
+			manager.userManager = users.New(cfg, logger)
+			manager.scheduleManager = schedules.New(cfg, logger)
+			manager.escalationManager = escalations.New(cfg, logger)
+			manager.serviceManager = services.New(cfg, logger)
+
+			// removed from the team during SyncTeams
+			manager.removedFrom = map[string]map[string]bool{
+				"PAUL":  {"BOOKING": true},
+				"PETE":  {"BOOKING": true},
+				"RINGO": {"BOOKING": true},
+			}
+
+			// call object under test
+			resultErr := manager.Prune(ctx)
+			require.NoError(t, resultErr)
+
+			// validation
+			var result []string
+			for _, change := range manager.Plan().Changes {
+				result = append(result, change.String()[2:])
+			}
+
+			assert.Equal(t, scenario.expectedPlan, result)
+			assert.Equal(t, scenario.expectedMutations, mutations)
+		})
+	}
+}
+
+var pruneResponses = map[string]string{
+	"/services": `{"services": [
+		{"id": "BOOKING", "name": "Booking", "status": "active", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}]},
+		{"id": "LEGACY", "name": "Booking Service", "status": "active", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}]},
+		{"id": "API", "name": "Booking API", "status": "active", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}],
+			"escalation_policy": {"id": "BOOKING"}},
+		{"id": "OLDAPI", "name": "Old API", "status": "active", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}],
+			"escalation_policy": {"id": "OLD"}},
+		{"id": "HANDMADE", "name": "Hand made", "status": "active", "teams": [{"id": "BOOKING"}]},
+		{"id": "OTHER", "name": "Other team", "status": "active", "description": "` + ownership.Marker + `", "teams": [{"id": "OTHER"}]},
+		{"id": "RETIRED", "name": "Retired", "status": "disabled", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}]}
+	]}`,
+	"/escalation_policies": `{"escalation_policies": [
+		{"id": "BOOKING", "name": "Booking Escalation", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}],
+			"escalation_rules": [{"targets": [{"id": "SHARED", "type": "schedule_reference"}]}]},
+		{"id": "OLD", "name": "Old Escalation", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}]},
+		{"id": "UNUSED", "name": "Unused Escalation", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}],
+			"escalation_rules": [{"targets": [{"id": "OLD", "type": "schedule_reference"}]}]},
+		{"id": "HANDMADE", "name": "Hand made Escalation", "teams": [{"id": "BOOKING"}]}
+	]}`,
+	"/schedules": `{"schedules": [
+		{"id": "BOOKING", "name": "Booking Schedule", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}]},
+		{"id": "LEGACY", "name": "Booking Rota", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}]},
+		{"id": "SHARED", "name": "Shared Schedule", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}]},
+		{"id": "OLD", "name": "Old Schedule", "description": "` + ownership.Marker + `", "teams": [{"id": "BOOKING"}]},
+		{"id": "HANDMADE", "name": "Hand made Schedule", "teams": [{"id": "BOOKING"}]}
+	]}`,
+	"/users/PETE": `{"user": {"id": "PETE", "email": "pete@beatles.com", "description": "` + ownership.Marker + `",
+		"teams": [{"id": "BOOKING"}, {"id": "OTHER"}]}}`,
+	"/users/RINGO": `{"user": {"id": "RINGO", "email": "ringo@beatles.com", "description": "` + ownership.Marker + `",
+		"teams": [{"id": "BOOKING"}]}}`,
+}
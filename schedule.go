@@ -0,0 +1,277 @@
+package pdmanager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/schedules"
+)
+
+const (
+	restrictionDaily  = "daily"
+	restrictionWeekly = "weekly"
+)
+
+// TeamSchedule contains the (optional) rotation settings and layers of the team's schedule.
+// Unset fields use the defaults; a weekly rotation handing off on Monday at 11:00 in the default time zone.
+type TeamSchedule struct {
+	RotationSettings
+
+	// TimeZone of the schedule
+	TimeZone string `json:"time_zone"`
+	// Layers of the schedule; when empty the schedule has a single layer of the responders and leads
+	Layers []*ScheduleLayer `json:"layers"`
+}
+
+// RotationSettings are the settings of a rotation
+type RotationSettings struct {
+	// RotationLength is "daily", "weekly" or a duration (e.g. "12h" or "336h" for fortnightly)
+	RotationLength string `json:"rotation_length"`
+	// HandoffDay is the day of the week of the handoff (only used for weekly rotations)
+	HandoffDay string `json:"handoff_day"`
+	// HandoffTime is the time of day of the handoff as HH:MM
+	HandoffTime string `json:"handoff_time"`
+	// StartDate is the date of the first turn as YYYY-MM-DD
+	StartDate string `json:"start_date"`
+}
+
+// ScheduleLayer is a layer of the team's schedule.
+// Unset rotation settings use the settings of the team's schedule.
+type ScheduleLayer struct {
+	RotationSettings
+
+	Name string `json:"name"`
+	// Members are the emails of the team members in the layer (in order); when empty the responders and leads are used
+	Members      []string            `json:"members"`
+	Restrictions []*LayerRestriction `json:"restrictions"`
+}
+
+// LayerRestriction limits when a layer is on-call
+type LayerRestriction struct {
+	// Type is "daily" or "weekly"
+	Type string `json:"type"`
+	// StartDay is the day of the week the restriction starts (only used for weekly restrictions)
+	StartDay string `json:"start_day"`
+	// StartTime is the time of day the restriction starts as HH:MM
+	StartTime string `json:"start_time"`
+	// Duration of the restriction (e.g. "8h" or "120h")
+	Duration string `json:"duration"`
+}
+
+// teamLayer is a parsed layer with the emails of its members (the IDs are only known after the users are synced)
+type teamLayer struct {
+	layer  *schedules.Layer
+	emails []string
+}
+
+// rotation converts the settings into a rotation (applying the defaults); returns nil when the settings are invalid.
+// path is the path of the schedule.
+func (s *TeamSchedule) rotation(v *validator, path string) *schedules.Rotation {
+	out := schedules.DefaultRotation()
+
+	if s == nil {
+		return out
+	}
+
+	errs := v.errorCount()
+
+	out = s.RotationSettings.rotation(v, path, out)
+
+	if s.TimeZone != "" {
+		_, err := time.LoadLocation(s.TimeZone)
+		if err != nil {
+			v.errorf(path+".time_zone", "invalid time zone '%s'", s.TimeZone)
+		}
+	}
+
+	if v.errorCount() > errs {
+		return nil
+	}
+
+	if s.TimeZone != "" {
+		out.TimeZone = s.TimeZone
+	}
+
+	return out
+}
+
+// layers parses the layers of the schedule; rotation settings of the layers override the rotation of the schedule.
+// path is the path of the schedule.
+func (s *TeamSchedule) layers(v *validator, path string, team *Team, rotation *schedules.Rotation) []*teamLayer {
+	if s == nil {
+		return nil
+	}
+
+	var out []*teamLayer
+
+	names := map[string]bool{}
+
+	for layerIndex, thisLayer := range s.Layers {
+		layerPath := fmt.Sprintf("%s.layers[%d]", path, layerIndex)
+
+		if thisLayer.Name == "" {
+			v.errorf(layerPath+".name", "missing name")
+		} else if names[thisLayer.Name] {
+			v.errorf(layerPath+".name", "duplicate schedule layer '%s'", thisLayer.Name)
+		}
+
+		names[thisLayer.Name] = true
+
+		for memberIndex, email := range thisLayer.Members {
+			if team.findMember(email) == nil {
+				v.errorf(fmt.Sprintf("%s.members[%d]", layerPath, memberIndex), "'%s' is not a member of the team", email)
+			}
+		}
+
+		layerRotation := thisLayer.RotationSettings.rotation(v, layerPath, rotation)
+
+		var restrictions []*schedules.Restriction
+
+		for restrictionIndex, thisRestriction := range thisLayer.Restrictions {
+			restriction := thisRestriction.restriction(v, fmt.Sprintf("%s.restrictions[%d]", layerPath, restrictionIndex))
+
+			restrictions = append(restrictions, restriction)
+		}
+
+		out = append(out, &teamLayer{
+			layer: &schedules.Layer{
+				Name:         thisLayer.Name,
+				Rotation:     layerRotation,
+				Restrictions: restrictions,
+			},
+			emails: thisLayer.Members,
+		})
+	}
+
+	return out
+}
+
+// rotation applies the settings to a copy of the base rotation; returns nil when the settings are invalid.
+// path is the path of the object with the settings.
+func (s *RotationSettings) rotation(v *validator, path string, base *schedules.Rotation) *schedules.Rotation {
+	out := *base
+
+	errs := v.errorCount()
+
+	switch strings.ToLower(s.RotationLength) {
+	case "":
+		// base
+
+	case "daily":
+		out.Length = 24 * time.Hour
+
+	case "weekly":
+		out.Length = 7 * 24 * time.Hour
+
+	default:
+		length, err := time.ParseDuration(s.RotationLength)
+		if err != nil || length < time.Hour {
+			v.errorf(path+".rotation_length", "invalid rotation length '%s' (must be daily, weekly or a duration of at least 1h)",
+				s.RotationLength)
+		}
+
+		out.Length = length
+	}
+
+	if s.HandoffDay != "" {
+		day, ok := parseWeekday(s.HandoffDay)
+		if !ok {
+			v.errorf(path+".handoff_day", "invalid handoff day '%s'", s.HandoffDay)
+		}
+
+		out.HandoffDay = day
+	}
+
+	if s.HandoffTime != "" {
+		handoffTime, ok := parseTimeOfDay(s.HandoffTime)
+		if !ok {
+			v.errorf(path+".handoff_time", "invalid handoff time '%s' (must be HH:MM)", s.HandoffTime)
+		}
+
+		out.HandoffTime = handoffTime
+	}
+
+	if s.StartDate != "" {
+		startDate, err := time.Parse("2006-01-02", s.StartDate)
+		if err != nil {
+			v.errorf(path+".start_date", "invalid start date '%s' (must be YYYY-MM-DD)", s.StartDate)
+		}
+
+		out.StartDate = startDate
+	}
+
+	if v.errorCount() > errs {
+		return nil
+	}
+
+	return &out
+}
+
+// restriction converts the config into a restriction; returns nil when the config is invalid.
+// path is the path of the restriction.
+func (r *LayerRestriction) restriction(v *validator, path string) *schedules.Restriction {
+	out := &schedules.Restriction{}
+
+	errs := v.errorCount()
+
+	maxDuration := 24 * time.Hour
+
+	switch strings.ToLower(r.Type) {
+	case restrictionDaily:
+		// no start day
+
+	case restrictionWeekly:
+		day, ok := parseWeekday(r.StartDay)
+		if !ok {
+			v.errorf(path+".start_day", "invalid restriction start day '%s'", r.StartDay)
+		}
+
+		out.Weekly = true
+		out.StartDay = day
+		maxDuration = 7 * 24 * time.Hour
+
+	default:
+		v.errorf(path+".type", "invalid restriction type '%s' (must be daily or weekly)", r.Type)
+	}
+
+	startTime, ok := parseTimeOfDay(r.StartTime)
+	if !ok {
+		v.errorf(path+".start_time", "invalid restriction start time '%s' (must be HH:MM)", r.StartTime)
+	}
+
+	out.StartTime = startTime
+
+	duration, err := time.ParseDuration(r.Duration)
+	if err != nil || duration <= 0 || duration > maxDuration {
+		v.errorf(path+".duration", "invalid restriction duration '%s' (must be a duration of at most %s)", r.Duration, maxDuration)
+	}
+
+	out.Duration = duration
+
+	if v.errorCount() > errs {
+		return nil
+	}
+
+	return out
+}
+
+func parseWeekday(value string) (time.Weekday, bool) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if strings.EqualFold(value, day.String()) {
+			return day, true
+		}
+	}
+
+	return time.Sunday, false
+}
+
+// parseTimeOfDay parses HH:MM into the duration since midnight
+func parseTimeOfDay(value string) (time.Duration, bool) {
+	parsed, err := time.Parse("15:04", value)
+	if err != nil {
+		return 0, false
+	}
+
+	return time.Duration(parsed.Hour())*time.Hour + time.Duration(parsed.Minute())*time.Minute, true
+}
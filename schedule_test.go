@@ -0,0 +1,255 @@
+package pdmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/schedules"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTeamSchedule_rotation(t *testing.T) {
+	scenarios := []struct {
+		desc           string
+		in             *TeamSchedule
+		expected       *schedules.Rotation
+		expectedErrors []string
+	}{
+		{
+			desc:     "no schedule uses the default",
+			in:       nil,
+			expected: schedules.DefaultRotation(),
+		},
+		{
+			desc: "daily follow-the-sun handoff",
+			in: &TeamSchedule{
+				RotationSettings: RotationSettings{
+					RotationLength: "daily",
+					HandoffTime:    "08:30",
+					StartDate:      "2021-08-02",
+				},
+				TimeZone: "Europe/London",
+			},
+			expected: &schedules.Rotation{
+				Length:      24 * time.Hour,
+				HandoffDay:  time.Monday,
+				HandoffTime: 8*time.Hour + 30*time.Minute,
+				StartDate:   time.Date(2021, time.August, 2, 0, 0, 0, 0, time.UTC),
+				TimeZone:    "Europe/London",
+			},
+		},
+		{
+			desc: "fortnightly on Thursdays",
+			in: &TeamSchedule{
+				RotationSettings: RotationSettings{
+					RotationLength: "336h",
+					HandoffDay:     "thursday",
+				},
+			},
+			expected: &schedules.Rotation{
+				Length:      14 * 24 * time.Hour,
+				HandoffDay:  time.Thursday,
+				HandoffTime: 11 * time.Hour,
+				StartDate:   time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC),
+			},
+		},
+		{
+			desc:           "sad path - invalid rotation length",
+			in:             &TeamSchedule{RotationSettings: RotationSettings{RotationLength: "monthly"}},
+			expectedErrors: []string{"schedule.rotation_length"},
+		},
+		{
+			desc:           "sad path - invalid handoff day",
+			in:             &TeamSchedule{RotationSettings: RotationSettings{HandoffDay: "funday"}},
+			expectedErrors: []string{"schedule.handoff_day"},
+		},
+		{
+			desc:           "sad path - invalid handoff time",
+			in:             &TeamSchedule{RotationSettings: RotationSettings{HandoffTime: "9am"}},
+			expectedErrors: []string{"schedule.handoff_time"},
+		},
+		{
+			desc: "sad path - every problem is reported",
+			in: &TeamSchedule{
+				RotationSettings: RotationSettings{RotationLength: "monthly", HandoffTime: "9am", StartDate: "02/08/2021"},
+				TimeZone:         "Mars/Olympus_Mons",
+			},
+			expectedErrors: []string{
+				"schedule.rotation_length",
+				"schedule.handoff_time",
+				"schedule.start_date",
+				"schedule.time_zone",
+			},
+		},
+		{
+			desc:           "sad path - invalid time zone",
+			in:             &TeamSchedule{TimeZone: "Mars/Olympus_Mons"},
+			expectedErrors: []string{"schedule.time_zone"},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			v := &validator{}
+
+			// call object under test
+			result := scenario.in.rotation(v, "schedule")
+
+			// validation
+			assert.Equal(t, scenario.expectedErrors, problemPaths(v.problems))
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestTeam_GetLayers(t *testing.T) {
+	scenarios := []struct {
+		desc           string
+		in             *TeamSchedule
+		expected       []*schedules.Layer
+		expectedErrors []string
+	}{
+		{
+			desc:     "no layers",
+			in:       &TeamSchedule{},
+			expected: nil,
+		},
+		{
+			desc: "business hours and after hours",
+			in: &TeamSchedule{
+				Layers: []*ScheduleLayer{
+					{
+						Name:    "Business hours",
+						Members: []string{"b@example.com", "a@example.com"},
+						Restrictions: []*LayerRestriction{
+							{Type: "weekly", StartDay: "monday", StartTime: "09:00", Duration: "8h"},
+						},
+					},
+					{
+						Name:             "After hours",
+						RotationSettings: RotationSettings{RotationLength: "daily"},
+						Restrictions: []*LayerRestriction{
+							{Type: "daily", StartTime: "17:00", Duration: "16h"},
+						},
+					},
+				},
+			},
+			expected: []*schedules.Layer{
+				{
+					Name:     "Business hours",
+					UserIDs:  []string{"B", "A"},
+					Rotation: schedules.DefaultRotation(),
+					Restrictions: []*schedules.Restriction{
+						{Weekly: true, StartDay: time.Monday, StartTime: 9 * time.Hour, Duration: 8 * time.Hour},
+					},
+				},
+				{
+					Name:    "After hours",
+					UserIDs: []string{"A", "C", "B"},
+					Rotation: &schedules.Rotation{
+						Length:      24 * time.Hour,
+						HandoffDay:  time.Monday,
+						HandoffTime: 11 * time.Hour,
+						StartDate:   time.Date(2021, time.July, 5, 0, 0, 0, 0, time.UTC),
+					},
+					Restrictions: []*schedules.Restriction{
+						{Weekly: false, StartTime: 17 * time.Hour, Duration: 16 * time.Hour},
+					},
+				},
+			},
+		},
+		{
+			desc: "sad path - duplicate layer",
+			in: &TeamSchedule{
+				Layers: []*ScheduleLayer{{Name: "Weekend"}, {Name: "Weekend"}},
+			},
+			expectedErrors: []string{"schedule.layers[1].name"},
+		},
+		{
+			desc: "sad path - not a member of the team",
+			in: &TeamSchedule{
+				Layers: []*ScheduleLayer{{Name: "Weekend", Members: []string{"z@example.com"}}},
+			},
+			expectedErrors: []string{"schedule.layers[0].members[0]"},
+		},
+		{
+			desc: "sad path - daily restriction longer than a day",
+			in: &TeamSchedule{
+				Layers: []*ScheduleLayer{
+					{
+						Name:         "Weekend",
+						Restrictions: []*LayerRestriction{{Type: "daily", StartTime: "00:00", Duration: "48h"}},
+					},
+				},
+			},
+			expectedErrors: []string{"schedule.layers[0].restrictions[0].duration"},
+		},
+		{
+			desc: "sad path - every problem is reported",
+			in: &TeamSchedule{
+				Layers: []*ScheduleLayer{
+					{
+						Members:          []string{"a@example.com", "z@example.com"},
+						RotationSettings: RotationSettings{HandoffDay: "funday"},
+						Restrictions: []*LayerRestriction{
+							{Type: "daily", StartTime: "00:00", Duration: "16h"},
+							{Type: "monthly", StartTime: "midnight", Duration: "16h"},
+						},
+					},
+				},
+			},
+			expectedErrors: []string{
+				"schedule.layers[0].name",
+				"schedule.layers[0].members[1]",
+				"schedule.layers[0].handoff_day",
+				"schedule.layers[0].restrictions[1].type",
+				"schedule.layers[0].restrictions[1].start_time",
+			},
+		},
+		{
+			desc: "sad path - weekly restriction without start day",
+			in: &TeamSchedule{
+				Layers: []*ScheduleLayer{
+					{
+						Name:         "Weekend",
+						Restrictions: []*LayerRestriction{{Type: "weekly", StartTime: "00:00", Duration: "48h"}},
+					},
+				},
+			},
+			expectedErrors: []string{"schedule.layers[0].restrictions[0].start_day"},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			team := &Team{
+				Name:     "Team",
+				Schedule: scenario.in,
+				Members: []*Member{
+					{ID: "A", Email: "a@example.com", Role: roleMember},
+					{ID: "B", Email: "b@example.com", Role: roleLead},
+					{ID: "C", Email: "c@example.com", Role: roleMember},
+				},
+			}
+
+			v := &validator{}
+
+			rotation := scenario.in.rotation(v, "schedule")
+			require.Empty(t, v.problems)
+
+			// call object under test
+			team.layers = scenario.in.layers(v, "schedule", team, rotation)
+
+			// validation
+			assert.Equal(t, scenario.expectedErrors, problemPaths(v.problems))
+			if len(scenario.expectedErrors) == 0 {
+				assert.Equal(t, scenario.expected, team.GetLayers())
+			}
+		})
+	}
+}
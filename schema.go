@@ -0,0 +1,11 @@
+package pdmanager
+
+import (
+	// embed the JSON Schema of the config
+	_ "embed"
+)
+
+// Schema is the JSON Schema of the config files (for editors and CI; the config is also validated when it is parsed)
+//
+//go:embed config.schema.json
+var Schema []byte
@@ -0,0 +1,109 @@
+package pdmanager
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSchema checks that the schema has every field of the config
+func TestSchema(t *testing.T) {
+	scenarios := []struct {
+		desc string
+		// path of the object in the schema
+		path []string
+		in   interface{}
+	}{
+		{desc: "config", path: nil, in: configFile{}},
+		{desc: "naming", path: []string{"properties", "naming"}, in: Naming{}},
+		{desc: "slack", path: []string{"properties", "slack"}, in: SlackConfig{}},
+		{desc: "team", path: []string{"definitions", "team"}, in: Team{}},
+		{desc: "member", path: []string{"definitions", "member"}, in: Member{}},
+		{desc: "contact method", path: []string{"definitions", "contact_method"}, in: ContactMethod{}},
+		{desc: "notification rule", path: []string{"definitions", "notification_rule"}, in: NotificationRule{}},
+		{desc: "schedule", path: []string{"definitions", "schedule"}, in: TeamSchedule{}},
+		{desc: "schedule layer", path: []string{"definitions", "schedule_layer"}, in: ScheduleLayer{}},
+		{desc: "layer restriction", path: []string{"definitions", "layer_restriction"}, in: LayerRestriction{}},
+		{desc: "escalation", path: []string{"definitions", "escalation"}, in: TeamEscalation{}},
+		{desc: "escalation tier", path: []string{"definitions", "escalation_tier"}, in: EscalationTier{}},
+		{
+			desc: "team target",
+			path: []string{"definitions", "escalation_tier", "properties", "teams", "items"},
+			in:   TeamTarget{},
+		},
+		{desc: "service", path: []string{"definitions", "service"}, in: Service{}},
+		{
+			desc: "service link",
+			path: []string{"definitions", "service", "properties", "links", "items"},
+			in:   ServiceLink{},
+		},
+		{
+			desc: "service integration",
+			path: []string{"definitions", "service", "properties", "integrations", "items"},
+			in:   ServiceIntegration{},
+		},
+		{
+			desc: "support hours",
+			path: []string{"definitions", "service", "properties", "support_hours"},
+			in:   ServiceSupportHours{},
+		},
+	}
+
+	schema := map[string]interface{}{}
+	require.NoError(t, json.Unmarshal(Schema, &schema))
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			object := schema
+			for _, key := range scenario.path {
+				next, ok := object[key].(map[string]interface{})
+				require.True(t, ok, "missing '%s' of %v", key, scenario.path)
+
+				object = next
+			}
+
+			properties, ok := object["properties"].(map[string]interface{})
+			require.True(t, ok, "missing properties")
+
+			// call object under test
+			fields := jsonFields(reflect.TypeOf(scenario.in))
+
+			// validation
+			var propertyNames []string
+			for name := range properties {
+				propertyNames = append(propertyNames, name)
+			}
+
+			assert.ElementsMatch(t, fields, propertyNames)
+		})
+	}
+}
+
+// jsonFields returns the names of the JSON fields of the struct (including the fields of embedded structs)
+func jsonFields(structType reflect.Type) []string {
+	var out []string
+
+	for index := 0; index < structType.NumField(); index++ {
+		field := structType.Field(index)
+
+		if field.Anonymous {
+			out = append(out, jsonFields(field.Type)...)
+			continue
+		}
+
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		out = append(out, name)
+	}
+
+	return out
+}
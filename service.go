@@ -0,0 +1,221 @@
+package pdmanager
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/services"
+)
+
+const (
+	// max alert grouping timeout supported by PagerDuty (1 day)
+	maxGroupingTimeout = 24 * time.Hour
+	// timeouts must be at least 1 minute
+	minTimeout = time.Minute
+)
+
+// name of the link made from the deprecated dashboard field
+const dashboardLink = "Dashboard"
+
+// ServiceLink is a named link of a service (e.g. a dashboard, runbook or repo)
+type ServiceLink struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// allLinks returns the links of the service including the deprecated dashboard
+func (s *Service) allLinks() []*ServiceLink {
+	if s.Dashboard == "" {
+		return s.Links
+	}
+
+	return append([]*ServiceLink{{Name: dashboardLink, URL: s.Dashboard}}, s.Links...)
+}
+
+// validateLinks records the problems with the links of the service; path is the path of the service
+func (s *Service) validateLinks(v *validator, path string) {
+	names := map[string]bool{}
+	fieldNames := map[string]bool{}
+
+	// the deprecated dashboard is first so that duplicates of it are reported on the link
+	if s.Dashboard != "" {
+		link := &ServiceLink{Name: dashboardLink, URL: s.Dashboard}
+		validateLink(v, path+".dashboard", path+".dashboard", link, names, fieldNames)
+	}
+
+	for index, link := range s.Links {
+		linkPath := fmt.Sprintf("%s.links[%d]", path, index)
+		validateLink(v, linkPath+".name", linkPath+".url", link, names, fieldNames)
+	}
+}
+
+// validateLink records the problems with the link; names and fieldNames are those of the links validated before it
+func validateLink(v *validator, namePath, urlPath string, link *ServiceLink, names, fieldNames map[string]bool) {
+	fieldName := linkFieldName(link.Name)
+
+	switch {
+	case link.Name == "":
+		v.errorf(namePath, "missing name")
+
+	case names[link.Name]:
+		v.errorf(namePath, "duplicate link '%s'", link.Name)
+
+	case fieldName == "":
+		v.errorf(namePath, "name of link '%s' must contain a letter or digit", link.Name)
+
+	case fieldNames[fieldName]:
+		v.errorf(namePath, "link '%s' has the same field name '%s' as another link", link.Name, fieldName)
+	}
+
+	names[link.Name] = true
+	fieldNames[fieldName] = true
+
+	parsed, err := url.Parse(link.URL)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		v.errorf(urlPath, "invalid url '%s'", link.URL)
+	}
+}
+
+// ServiceSupportHours are the hours the service is supported; incidents outside of them have the outside urgency
+type ServiceSupportHours struct {
+	TimeZone       string   `json:"time_zone"`
+	Days           []string `json:"days"`
+	Start          string   `json:"start"`
+	End            string   `json:"end"`
+	OutsideUrgency string   `json:"outside_urgency"`
+}
+
+// parseSettings validates and parses the incident settings of the service; returns nil when they are invalid.
+// path is the path of the service.
+func (s *Service) parseSettings(v *validator, path, defaultTimeZone string) *services.Settings {
+	out := services.DefaultSettings()
+
+	errs := v.errorCount()
+
+	if s.Urgency != "" {
+		if !validUrgency(s.Urgency) {
+			v.errorf(path+".urgency", "invalid urgency '%s' (must be high or low)", s.Urgency)
+		}
+
+		out.Urgency = s.Urgency
+	}
+
+	if s.SupportHours != nil {
+		out.SupportHours, out.OutsideUrgency = s.SupportHours.parse(v, path+".support_hours", defaultTimeZone)
+	}
+
+	switch s.AlertGrouping {
+	case "", services.GroupingIntelligent:
+		if s.AlertGroupingTimeout != "" {
+			v.errorf(path+".alert_grouping_timeout", "alert_grouping_timeout requires time alert grouping")
+		}
+
+	case services.GroupingTime:
+		out.AlertGrouping = services.GroupingTime
+
+		if s.AlertGroupingTimeout != "" {
+			timeout, err := time.ParseDuration(s.AlertGroupingTimeout)
+			if err != nil || timeout < minTimeout || timeout > maxGroupingTimeout || timeout%time.Minute != 0 {
+				v.errorf(path+".alert_grouping_timeout", "invalid alert_grouping_timeout '%s' (must be whole minutes between 1m and 24h)",
+					s.AlertGroupingTimeout)
+			}
+
+			out.AlertGroupingTimeout = timeout
+		}
+
+	default:
+		v.errorf(path+".alert_grouping", "invalid alert_grouping '%s' (must be intelligent or time)", s.AlertGrouping)
+	}
+
+	out.AcknowledgementTimeout = parseTimeout(v, path, "acknowledgement_timeout", s.AcknowledgementTimeout)
+	out.AutoResolveTimeout = parseTimeout(v, path, "auto_resolve_timeout", s.AutoResolveTimeout)
+
+	if v.errorCount() > errs {
+		return nil
+	}
+
+	return out
+}
+
+// parse validates and parses the support hours and returns them with the urgency outside of them; path is the path of the
+// support hours
+func (s *ServiceSupportHours) parse(v *validator, path, defaultTimeZone string) (*services.SupportHours, string) {
+	out := &services.SupportHours{
+		TimeZone: defaultTimeZone,
+	}
+
+	if s.TimeZone != "" {
+		_, err := time.LoadLocation(s.TimeZone)
+		if err != nil {
+			v.errorf(path+".time_zone", "invalid support hours time zone '%s'", s.TimeZone)
+		}
+
+		out.TimeZone = s.TimeZone
+	}
+
+	if len(s.Days) == 0 {
+		v.errorf(path+".days", "support hours must have days")
+	}
+
+	seen := map[time.Weekday]bool{}
+
+	for index, value := range s.Days {
+		dayPath := fmt.Sprintf("%s.days[%d]", path, index)
+
+		day, ok := parseWeekday(value)
+		if !ok {
+			v.errorf(dayPath, "invalid support hours day '%s'", value)
+			continue
+		}
+
+		if seen[day] {
+			v.errorf(dayPath, "duplicate support hours day '%s'", value)
+			continue
+		}
+
+		seen[day] = true
+		out.Days = append(out.Days, day)
+	}
+
+	var startOK, endOK bool
+
+	out.Start, startOK = parseTimeOfDay(s.Start)
+	if !startOK {
+		v.errorf(path+".start", "invalid support hours start '%s' (must be HH:MM)", s.Start)
+	}
+
+	out.End, endOK = parseTimeOfDay(s.End)
+	if !endOK || (startOK && out.End <= out.Start) {
+		v.errorf(path+".end", "invalid support hours end '%s' (must be HH:MM after the start)", s.End)
+	}
+
+	outsideUrgency := services.UrgencyLow
+	if s.OutsideUrgency != "" {
+		if !validUrgency(s.OutsideUrgency) {
+			v.errorf(path+".outside_urgency", "invalid outside_urgency '%s' (must be high or low)", s.OutsideUrgency)
+		}
+
+		outsideUrgency = s.OutsideUrgency
+	}
+
+	return out, outsideUrgency
+}
+
+func validUrgency(urgency string) bool {
+	return urgency == services.UrgencyHigh || urgency == services.UrgencyLow
+}
+
+// parseTimeout parses an optional timeout field of the service; zero means it is not set
+func parseTimeout(v *validator, path, field, value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < minTimeout || timeout%time.Second != 0 {
+		v.errorf(path+"."+field, "invalid %s '%s' (must be a duration of at least 1m)", field, value)
+	}
+
+	return timeout
+}
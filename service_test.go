@@ -0,0 +1,283 @@
+package pdmanager
+
+import (
+	"testing"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/ownership"
+	"github.com/corsc/pagerduty-manager/internal/services"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestService_parseSettings(t *testing.T) {
+	scenarios := []struct {
+		desc           string
+		in             *Service
+		expected       *services.Settings
+		expectedErrors []string
+	}{
+		{
+			desc:     "no settings uses the defaults",
+			in:       &Service{Name: "A"},
+			expected: services.DefaultSettings(),
+		},
+		{
+			desc: "business hours service",
+			in: &Service{
+				Name: "A",
+				SupportHours: &ServiceSupportHours{
+					Days:  []string{"monday", "Tuesday", "wednesday", "thursday", "friday"},
+					Start: "09:00",
+					End:   "17:30",
+				},
+				AlertGrouping:          "time",
+				AlertGroupingTimeout:   "15m",
+				AcknowledgementTimeout: "30m",
+				AutoResolveTimeout:     "4h",
+			},
+			expected: &services.Settings{
+				Urgency: services.UrgencyHigh,
+				SupportHours: &services.SupportHours{
+					TimeZone: "Asia/Singapore",
+					Days:     []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday},
+					Start:    9 * time.Hour,
+					End:      17*time.Hour + 30*time.Minute,
+				},
+				OutsideUrgency:         services.UrgencyLow,
+				AlertGrouping:          services.GroupingTime,
+				AlertGroupingTimeout:   15 * time.Minute,
+				AcknowledgementTimeout: 30 * time.Minute,
+				AutoResolveTimeout:     4 * time.Hour,
+			},
+		},
+		{
+			desc: "low urgency service",
+			in:   &Service{Name: "A", Urgency: "low"},
+			expected: &services.Settings{
+				Urgency:       services.UrgencyLow,
+				AlertGrouping: services.GroupingIntelligent,
+			},
+		},
+		{
+			desc:           "sad path - invalid urgency",
+			in:             &Service{Name: "A", Urgency: "urgent"},
+			expectedErrors: []string{"services[0].urgency"},
+		},
+		{
+			desc:           "sad path - invalid alert grouping",
+			in:             &Service{Name: "A", AlertGrouping: "content"},
+			expectedErrors: []string{"services[0].alert_grouping"},
+		},
+		{
+			desc:           "sad path - grouping timeout without time grouping",
+			in:             &Service{Name: "A", AlertGroupingTimeout: "15m"},
+			expectedErrors: []string{"services[0].alert_grouping_timeout"},
+		},
+		{
+			desc:           "sad path - grouping timeout too long",
+			in:             &Service{Name: "A", AlertGrouping: "time", AlertGroupingTimeout: "48h"},
+			expectedErrors: []string{"services[0].alert_grouping_timeout"},
+		},
+		{
+			desc:           "sad path - invalid acknowledgement timeout",
+			in:             &Service{Name: "A", AcknowledgementTimeout: "10s"},
+			expectedErrors: []string{"services[0].acknowledgement_timeout"},
+		},
+		{
+			desc: "sad path - support hours without days",
+			in: &Service{
+				Name:         "A",
+				SupportHours: &ServiceSupportHours{Start: "09:00", End: "17:00"},
+			},
+			expectedErrors: []string{"services[0].support_hours.days"},
+		},
+		{
+			desc: "sad path - support hours end before start",
+			in: &Service{
+				Name:         "A",
+				SupportHours: &ServiceSupportHours{Days: []string{"monday"}, Start: "17:00", End: "09:00"},
+			},
+			expectedErrors: []string{"services[0].support_hours.end"},
+		},
+		{
+			desc: "sad path - every problem is reported",
+			in: &Service{
+				Name:    "A",
+				Urgency: "urgent",
+				SupportHours: &ServiceSupportHours{
+					TimeZone: "Mars/Olympus_Mons",
+					Days:     []string{"monday", "funday", "Monday"},
+					Start:    "9am",
+					End:      "17:00",
+				},
+				AlertGrouping:      "content",
+				AutoResolveTimeout: "forever",
+			},
+			expectedErrors: []string{
+				"services[0].urgency",
+				"services[0].support_hours.time_zone",
+				"services[0].support_hours.days[1]",
+				"services[0].support_hours.days[2]",
+				"services[0].support_hours.start",
+				"services[0].alert_grouping",
+				"services[0].auto_resolve_timeout",
+			},
+		},
+		{
+			desc: "sad path - invalid outside urgency",
+			in: &Service{
+				Name: "A",
+				SupportHours: &ServiceSupportHours{
+					Days:           []string{"monday"},
+					Start:          "09:00",
+					End:            "17:00",
+					OutsideUrgency: "none",
+				},
+			},
+			expectedErrors: []string{"services[0].support_hours.outside_urgency"},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			v := &validator{}
+
+			// call object under test
+			result := scenario.in.parseSettings(v, "services[0]", "Asia/Singapore")
+
+			// validation
+			assert.Equal(t, scenario.expectedErrors, problemPaths(v.problems))
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestService_GetDescription(t *testing.T) {
+	scenarios := []struct {
+		desc     string
+		in       *Service
+		expected string
+	}{
+		{
+			desc:     "no description or links",
+			in:       &Service{Name: "A"},
+			expected: ownership.Marker,
+		},
+		{
+			desc: "description and links",
+			in: &Service{
+				Name:        "A",
+				Description: "Bookings API",
+				Links: []*ServiceLink{
+					{Name: "Runbook", URL: "https://wiki.example.com/bookings"},
+					{Name: "Repo", URL: "https://git.example.com/bookings"},
+				},
+			},
+			expected: "Bookings API\nRunbook: https://wiki.example.com/bookings\nRepo: https://git.example.com/bookings\n" + ownership.Marker,
+		},
+		{
+			desc: "deprecated dashboard",
+			in: &Service{
+				Name:      "A",
+				Dashboard: "https://grafana.example.com/bookings",
+			},
+			expected: "Dashboard: https://grafana.example.com/bookings\n" + ownership.Marker,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// call object under test
+			result := scenario.in.GetDescription()
+
+			// validation
+			assert.Equal(t, scenario.expected, result)
+		})
+	}
+}
+
+func TestService_validateLinks(t *testing.T) {
+	scenarios := []struct {
+		desc           string
+		in             *Service
+		expectedErrors []string
+	}{
+		{
+			desc: "happy path",
+			in: &Service{
+				Name:      "A",
+				Dashboard: "https://grafana.example.com/bookings",
+				Links:     []*ServiceLink{{Name: "Runbook", URL: "http://wiki.example.com/bookings"}},
+			},
+		},
+		{
+			desc:           "sad path - missing name",
+			in:             &Service{Name: "A", Links: []*ServiceLink{{URL: "https://wiki.example.com"}}},
+			expectedErrors: []string{"services[0].links[0].name"},
+		},
+		{
+			desc: "sad path - duplicate dashboard",
+			in: &Service{
+				Name:      "A",
+				Dashboard: "https://grafana.example.com/bookings",
+				Links:     []*ServiceLink{{Name: "Dashboard", URL: "https://grafana.example.com/other"}},
+			},
+			expectedErrors: []string{"services[0].links[0].name"},
+		},
+		{
+			desc: "sad path - same field name",
+			in: &Service{
+				Name: "A",
+				Links: []*ServiceLink{
+					{Name: "Runbook Wiki", URL: "https://wiki.example.com/bookings"},
+					{Name: "runbook-wiki", URL: "https://wiki.example.com/other"},
+				},
+			},
+			expectedErrors: []string{"services[0].links[1].name"},
+		},
+		{
+			desc:           "sad path - no letters or digits",
+			in:             &Service{Name: "A", Links: []*ServiceLink{{Name: "???", URL: "https://wiki.example.com"}}},
+			expectedErrors: []string{"services[0].links[0].name"},
+		},
+		{
+			desc: "sad path - every problem is reported",
+			in: &Service{
+				Name:      "A",
+				Dashboard: "grafana/bookings",
+				Links: []*ServiceLink{
+					{URL: "wiki/bookings"},
+					{Name: "Dashboard", URL: "https://grafana.example.com/other"},
+				},
+			},
+			expectedErrors: []string{
+				"services[0].dashboard",
+				"services[0].links[0].name",
+				"services[0].links[0].url",
+				"services[0].links[1].name",
+			},
+		},
+		{
+			desc:           "sad path - not a url",
+			in:             &Service{Name: "A", Links: []*ServiceLink{{Name: "Runbook", URL: "wiki/bookings"}}},
+			expectedErrors: []string{"services[0].links[0].url"},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			v := &validator{}
+
+			// call object under test
+			scenario.in.validateLinks(v, "services[0]")
+
+			// validation
+			assert.Equal(t, scenario.expectedErrors, problemPaths(v.problems))
+		})
+	}
+}
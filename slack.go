@@ -0,0 +1,139 @@
+package pdmanager
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	"github.com/corsc/pagerduty-manager/internal/ownership"
+	"github.com/corsc/pagerduty-manager/internal/webhooks"
+	"go.uber.org/zap"
+)
+
+const resourceWebhook = "webhook subscription"
+
+// channelPlaceholder is replaced with the team's Slack channel in the Slack webhook URL
+const channelPlaceholder = "{channel}"
+
+// Slack channel names are lowercase letters, numbers, hyphens and underscores (max 80 characters)
+var slackChannelPattern = regexp.MustCompile(`^#?[a-z0-9_-]{1,80}$`)
+
+// SlackConfig is where incidents are sent to be posted to the team's Slack channel
+type SlackConfig struct {
+	// WebhookURL receives the incident events of the services of each team (e.g. a Slack bot or relay).
+	// "{channel}" is replaced with the team's channel (without the #).
+	WebhookURL string `json:"webhook_url"`
+}
+
+func (m *Manager) validateSlack(team *Team) error {
+	if team.Slack == "" {
+		if m.companyConfig.Slack != nil {
+			return fmt.Errorf("missing slack channel of team '%s'", team.Name)
+		}
+
+		return nil
+	}
+
+	if !slackChannelPattern.MatchString(team.Slack) {
+		return fmt.Errorf("invalid slack channel '%s' of team '%s' (must be lowercase letters, numbers, hyphens and underscores)",
+			team.Slack, team.Name)
+	}
+
+	return nil
+}
+
+func (s *SlackConfig) validate() error {
+	if s == nil {
+		return nil
+	}
+
+	parsed, err := url.Parse(strings.ReplaceAll(s.WebhookURL, channelPlaceholder, "channel"))
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("invalid slack webhook_url '%s'", s.WebhookURL)
+	}
+
+	if !strings.Contains(s.WebhookURL, channelPlaceholder) {
+		return fmt.Errorf("slack webhook_url '%s' must contain %s", s.WebhookURL, channelPlaceholder)
+	}
+
+	return nil
+}
+
+// syncSlack creates or updates the webhook subscription that sends the incidents of the service to the team's Slack channel.
+// Subscriptions are matched by description so subscriptions created by hand are left alone.
+func (m *Manager) syncSlack(ctx context.Context, service *Service, team *Team, serviceID string) error {
+	if m.companyConfig.Slack == nil {
+		return nil
+	}
+
+	subscription := &slackSubscription{
+		url: strings.ReplaceAll(m.companyConfig.Slack.WebhookURL, channelPlaceholder,
+			url.PathEscape(strings.TrimPrefix(team.Slack, "#"))),
+	}
+
+	change := &Change{
+		Action:   ActionCreate,
+		Resource: resourceWebhook,
+		Name:     service.Name + "/Slack",
+		Details: []string{
+			"channel: " + team.Slack,
+		},
+	}
+
+	var liveSubscription *webhooks.Subscription
+
+	if serviceID != "" {
+		liveSubscriptions, err := m.webhookManager.ListForService(ctx, serviceID)
+		if err != nil {
+			m.logger.Error("failed to sync service - fetch webhook subscriptions failed", zap.Error(err))
+			return err
+		}
+
+		liveSubscription = webhooks.FindSubscription(liveSubscriptions, subscription.GetDescription())
+	}
+
+	if liveSubscription != nil {
+		change.Fields = m.webhookManager.Diff(liveSubscription, subscription)
+		if len(change.Fields) == 0 {
+			return nil
+		}
+
+		change.Action = ActionUpdate
+		change.Details = nil
+
+		err := m.apply(change, func() error {
+			return m.webhookManager.Update(ctx, liveSubscription.ID, subscription)
+		})
+		if err != nil {
+			m.logger.Error("failed to sync service - update webhook subscription failed", zap.Error(err))
+			return err
+		}
+
+		return nil
+	}
+
+	err := m.apply(change, func() error {
+		_, addErr := m.webhookManager.Add(ctx, serviceID, subscription)
+		return addErr
+	})
+	if err != nil {
+		m.logger.Error("failed to sync service - add webhook subscription failed", zap.Error(err))
+		return err
+	}
+
+	return nil
+}
+
+type slackSubscription struct {
+	url string
+}
+
+func (s *slackSubscription) GetDescription() string {
+	return ownership.Mark("Slack notifications")
+}
+
+func (s *slackSubscription) GetURL() string {
+	return s.url
+}
@@ -0,0 +1,121 @@
+package pdmanager
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/corsc/pagerduty-manager/internal/webhooks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_syncSlack(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	// mocks
+	testServer := httptest.NewServer(http.HandlerFunc(func(resp http.ResponseWriter, req *http.Request) {
+		assert.Equal(t, http.MethodGet, req.Method, "dry-run must not make changes")
+
+		_, _ = resp.Write([]byte(`{"webhook_subscriptions": [
+			{"id": "WEB1", "description": "Hand made", "active": true, "delivery_method": {"url": "https://example.com"}},
+			{"id": "WEB2", "description": "Slack notifications [managed by pagerduty-manager]", "active": true,
+				"events": ["incident.triggered"], "delivery_method": {"url": "https://relay.example.com/slack/old-channel"}}
+		]}`))
+	}))
+	defer testServer.Close()
+
+	cfg := &testConfig{
+		baseURL: testServer.URL,
+		dryRun:  true,
+	}
+
+	team := &Team{Name: "Booking Team", Slack: "#booking"}
+	service := &Service{Name: "Booking"}
+
+	manager := New(cfg, logger)
+	manager.companyConfig.Slack = &SlackConfig{WebhookURL: "https://relay.example.com/slack/{channel}"}
+	manager.webhookManager = webhooks.New(cfg, logger)
+
+	// call object under test
+	resultErr := manager.syncSlack(ctx, service, team, "BOOK")
+	require.NoError(t, resultErr)
+
+	// validation
+	require.Len(t, manager.Plan().Changes, 1)
+	assert.Equal(t, `update webhook subscription "Booking/Slack"`, manager.Plan().Changes[0].String()[2:])
+	assert.Equal(t, "https://relay.example.com/slack/booking", manager.Plan().Changes[0].Fields[0].Desired)
+}
+
+func TestManager_validateSlack(t *testing.T) {
+	scenarios := []struct {
+		desc      string
+		slack     *SlackConfig
+		channel   string
+		expectErr bool
+	}{
+		{
+			desc:      "happy path",
+			slack:     &SlackConfig{WebhookURL: "https://relay.example.com/slack?channel={channel}"},
+			channel:   "#test-team_a",
+			expectErr: false,
+		},
+		{
+			desc:      "no channel without slack config",
+			slack:     nil,
+			channel:   "",
+			expectErr: false,
+		},
+		{
+			desc:      "sad path - no channel",
+			slack:     &SlackConfig{WebhookURL: "https://relay.example.com/slack?channel={channel}"},
+			channel:   "",
+			expectErr: true,
+		},
+		{
+			desc:      "sad path - malformed channel",
+			slack:     nil,
+			channel:   "#Test Team",
+			expectErr: true,
+		},
+		{
+			desc:      "sad path - webhook url without channel",
+			slack:     &SlackConfig{WebhookURL: "https://relay.example.com/slack"},
+			channel:   "#test",
+			expectErr: true,
+		},
+		{
+			desc:      "sad path - invalid webhook url",
+			slack:     &SlackConfig{WebhookURL: "relay/{channel}"},
+			channel:   "#test",
+			expectErr: true,
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			manager := New(&testConfig{}, logger)
+			manager.companyConfig.Slack = scenario.slack
+
+			// call object under test
+			resultErr := manager.validateSlack(&Team{Name: "Test", Slack: scenario.channel})
+			if resultErr == nil {
+				resultErr = scenario.slack.validate()
+			}
+
+			// validation
+			require.Equal(t, scenario.expectErr, resultErr != nil, "expected error. err: %s", resultErr)
+		})
+	}
+}
@@ -0,0 +1,270 @@
+package pdmanager
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Severities of validation problems; only errors stop a sync
+const (
+	SeverityError   = "error"
+	SeverityWarning = "warning"
+)
+
+// Problem is a problem with a field of the config
+type Problem struct {
+	Severity string `json:"severity"`
+	// Source is the file and line of the team the problem is in (when known)
+	Source string `json:"source,omitempty"`
+	// Path of the field, e.g. teams[0].members[2].email
+	Path    string `json:"path"`
+	Message string `json:"message"`
+}
+
+func (p *Problem) String() string {
+	out := p.Severity + ": "
+
+	if p.Source != "" {
+		out += p.Source + ": "
+	}
+
+	return out + p.Path + ": " + p.Message
+}
+
+// ValidationError contains every error found in the config
+type ValidationError struct {
+	Problems []*Problem
+}
+
+func (e *ValidationError) Error() string {
+	lines := []string{fmt.Sprintf("invalid config (%d problems)", len(e.Problems))}
+
+	for _, problem := range e.Problems {
+		lines = append(lines, problem.String())
+	}
+
+	return strings.Join(lines, "\n  ")
+}
+
+// Problems returns the errors and warnings found when the config was parsed
+func (m *Manager) Problems() []*Problem {
+	return m.problems
+}
+
+// validator collects the problems with the config so they can be reported at once
+type validator struct {
+	problems []*Problem
+	// source of the team being validated
+	source string
+}
+
+func (v *validator) errorf(path, format string, args ...interface{}) {
+	v.add(SeverityError, path, fmt.Sprintf(format, args...))
+}
+
+func (v *validator) warnf(path, format string, args ...interface{}) {
+	v.add(SeverityWarning, path, fmt.Sprintf(format, args...))
+}
+
+// check records the error (if any) and returns true when there is no error
+func (v *validator) check(path string, err error) bool {
+	if err == nil {
+		return true
+	}
+
+	v.add(SeverityError, path, err.Error())
+
+	return false
+}
+
+func (v *validator) add(severity, path, message string) {
+	v.problems = append(v.problems, &Problem{
+		Severity: severity,
+		Source:   v.source,
+		Path:     path,
+		Message:  message,
+	})
+}
+
+// errorCount returns the number of errors found so far
+func (v *validator) errorCount() int {
+	count := 0
+
+	for _, problem := range v.problems {
+		if problem.Severity == SeverityError {
+			count++
+		}
+	}
+
+	return count
+}
+
+// err returns the errors found (if any)
+func (v *validator) err() error {
+	var errs []*Problem
+
+	for _, problem := range v.problems {
+		if problem.Severity == SeverityError {
+			errs = append(errs, problem)
+		}
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return &ValidationError{Problems: errs}
+}
+
+func (m *Manager) validateDefaultTimezone(v *validator) {
+	if m.companyConfig.DefaultTimezone == "" {
+		v.errorf("default_timezone", "missing default time zone")
+		return
+	}
+
+	_, err := time.LoadLocation(m.companyConfig.DefaultTimezone)
+	if err != nil {
+		v.errorf("default_timezone", "invalid time zone '%s'", m.companyConfig.DefaultTimezone)
+	}
+}
+
+func (m *Manager) validateMember(v *validator, path string, member *Member) {
+	if member.Name == "" {
+		v.errorf(path+".name", "missing name")
+	}
+
+	if member.Email == "" {
+		v.errorf(path+".email", "missing email")
+	} else if !strings.Contains(strings.Trim(member.Email, "@"), "@") {
+		v.errorf(path+".email", "invalid email '%s'", member.Email)
+	}
+
+	_, ok := rolesToPDUserRoles[member.Role]
+	if !ok {
+		v.errorf(path+".role", "invalid role '%s' (must be member, lead, observer or dept-head)", member.Role)
+	}
+
+	if member.Timezone != "" {
+		_, err := time.LoadLocation(member.Timezone)
+		if err != nil {
+			v.errorf(path+".timezone", "invalid time zone '%s'", member.Timezone)
+		}
+	}
+
+	m.validateNotifications(v, path, member)
+}
+
+// validateOnCall checks that the schedule has someone to be on-call
+func validateOnCall(v *validator, path string, team *Team) {
+	if len(team.getEmails(roleMember, roleLead)) > 0 {
+		return
+	}
+
+	// layers without members use the members and leads of the team
+	for _, thisLayer := range team.layers {
+		if len(thisLayer.emails) == 0 {
+			v.errorf(path+".members", "no members or leads to be on-call for schedule layer '%s'", thisLayer.layer.Name)
+			return
+		}
+	}
+
+	if len(team.layers) == 0 {
+		v.errorf(path+".members", "no members or leads to be on-call")
+	}
+}
+
+// configMember is a member and where it is in the config
+type configMember struct {
+	member *Member
+	team   *Team
+}
+
+// userField is an optional field of the user of a member; a member in more than one team can set it in any of the teams
+// (see resolveUserFields) but it must not conflict
+type userField struct {
+	name  string
+	value func(member *Member) string
+}
+
+var userFields = []*userField{
+	{name: "job_title", value: func(member *Member) string { return member.JobTitle }},
+	{name: "description", value: func(member *Member) string { return member.Description }},
+	{name: "contact_methods", value: func(member *Member) string { return contactMethodsKey(member.ContactMethods) }},
+	{name: "notification_profile", value: func(member *Member) string { return member.NotificationProfile }},
+}
+
+// validateMembersAcrossTeams checks that members in more than one team are the same user.
+// Users are shared by every team so their name and time zone must be the same in each team and their optional user fields
+// (e.g. contact methods) must not conflict.
+func (m *Manager) validateMembersAcrossTeams(v *validator) {
+	members := map[string]*configMember{}
+	// where each optional user field was first set, keyed by email and field name
+	setFields := map[string]map[string]*configMember{}
+
+	for teamIndex, team := range m.companyConfig.Teams {
+		v.source = team.source
+
+		for memberIndex, member := range team.Members {
+			path := fmt.Sprintf("teams[%d].members[%d]", teamIndex, memberIndex)
+			this := &configMember{member: member, team: team}
+
+			first, ok := members[member.Email]
+			if !ok {
+				members[member.Email] = this
+				setFields[member.Email] = map[string]*configMember{}
+			}
+
+			if ok && first.team == team {
+				v.errorf(path+".email", "duplicate member '%s'", member.Email)
+				continue
+			}
+
+			if ok {
+				validateSameUser(v, path, member, first)
+			}
+
+			for _, field := range userFields {
+				value := field.value(member)
+				if value == "" {
+					continue
+				}
+
+				set, ok := setFields[member.Email][field.name]
+				if !ok {
+					setFields[member.Email][field.name] = this
+					continue
+				}
+
+				if field.value(set.member) != value {
+					v.errorf(path+"."+field.name, "%s of '%s' conflicts with the %s in team '%s' (set it in one team or the same in each)",
+						field.name, member.Email, field.name, set.team.Name)
+				}
+			}
+		}
+	}
+
+	v.source = ""
+}
+
+// validateSameUser records the problems with a member that is also in an earlier team
+func validateSameUser(v *validator, path string, member *Member, first *configMember) {
+	if member.Name != first.member.Name {
+		v.errorf(path+".name", "'%s' conflicts with the name '%s' of '%s' in team '%s'",
+			member.Name, first.member.Name, member.Email, first.team.Name)
+	}
+
+	if member.Timezone != first.member.Timezone {
+		v.errorf(path+".timezone", "'%s' conflicts with the time zone '%s' of '%s' in team '%s'",
+			member.Timezone, first.member.Timezone, member.Email, first.team.Name)
+	}
+
+	role, ok := rolesToPDUserRoles[member.Role]
+	firstRole, firstOK := rolesToPDUserRoles[first.member.Role]
+
+	// invalid roles have already been reported
+	if ok && firstOK && role != firstRole {
+		v.warnf(path+".role", "'%s' is a %s in team '%s'; the most privileged user role is used",
+			member.Email, first.member.Role, first.team.Name)
+	}
+}
@@ -0,0 +1,298 @@
+package pdmanager
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestManager_validate(t *testing.T) {
+	scenarios := []struct {
+		desc             string
+		in               *companyConfig
+		expectedErrors   []string
+		expectedWarnings []string
+	}{
+		{
+			desc: "happy path",
+			in: &companyConfig{
+				DefaultTimezone: "Asia/Jakarta",
+				Teams: []*Team{
+					{
+						Name:     "A",
+						Members:  []*Member{{Name: "John", Email: "john@beatles.com", Role: roleLead}},
+						Services: []*Service{{Name: "Service A"}},
+					},
+				},
+			},
+		},
+		{
+			desc: "every problem is reported",
+			in: &companyConfig{
+				DefaultTimezone: "Mars/Olympus_Mons",
+				Teams: []*Team{
+					{
+						Name: "A",
+						Members: []*Member{
+							{Name: "John", Role: roleObserver},
+							{Name: "Paul", Email: "paul@beatles.com", Role: "drummer"},
+						},
+						Services: []*Service{{Name: "Service A"}, {Name: "Service A"}},
+					},
+					{
+						Name: "A",
+						Members: []*Member{
+							{Name: "Paul", Email: "paul@beatles.com", Role: roleLead, Timezone: "Europe/London"},
+						},
+					},
+				},
+			},
+			expectedErrors: []string{
+				"default_timezone",
+				"teams[0].members[0].email",
+				"teams[0].members[1].role",
+				"teams[0].members",
+				"teams[0].services[1].name",
+				"teams[1].name",
+				"teams[1].members[0].timezone",
+			},
+		},
+		{
+			desc: "members with different roles in different teams",
+			in: &companyConfig{
+				DefaultTimezone: "Asia/Jakarta",
+				Teams: []*Team{
+					{
+						Name:    "A",
+						Members: []*Member{{Name: "John", Email: "john@beatles.com", Role: roleLead}},
+					},
+					{
+						Name: "B",
+						Members: []*Member{
+							{Name: "Paul", Email: "paul@beatles.com", Role: roleMember},
+							{Name: "John", Email: "john@beatles.com", Role: roleDeptHead},
+						},
+					},
+				},
+			},
+			expectedWarnings: []string{"teams[1].members[1].role"},
+		},
+		{
+			desc: "user fields of a member in more than one team",
+			in: &companyConfig{
+				DefaultTimezone: "Asia/Jakarta",
+				Teams: []*Team{
+					{
+						Name: "A",
+						Members: []*Member{
+							{Name: "John", Email: "john@beatles.com", Role: roleLead, JobTitle: "Boss"},
+						},
+					},
+					{
+						Name: "B",
+						Members: []*Member{
+							{
+								Name:           "John",
+								Email:          "john@beatles.com",
+								Role:           roleLead,
+								Description:    "Sings",
+								ContactMethods: []*ContactMethod{{Type: contactSMS, CountryCode: 44, Address: "7700900123"}},
+							},
+						},
+					},
+					{
+						Name: "C",
+						Members: []*Member{
+							{
+								Name:           "John",
+								Email:          "john@beatles.com",
+								Role:           roleLead,
+								JobTitle:       "Singer",
+								Description:    "Sings",
+								ContactMethods: []*ContactMethod{{Type: contactSMS, CountryCode: 44, Address: "7700900999"}},
+							},
+						},
+					},
+				},
+			},
+			expectedErrors: []string{
+				"teams[2].members[0].job_title",
+				"teams[2].members[0].contact_methods",
+			},
+		},
+		{
+			desc: "phone number with a country code in the address",
+			in: &companyConfig{
+				DefaultTimezone: "Asia/Jakarta",
+				Teams: []*Team{
+					{
+						Name: "A",
+						Members: []*Member{
+							{
+								Name:           "John",
+								Email:          "john@beatles.com",
+								Role:           roleLead,
+								ContactMethods: []*ContactMethod{{Type: contactPhone, Address: "+44 7700 900123"}},
+							},
+						},
+					},
+				},
+			},
+			expectedErrors: []string{
+				"teams[0].members[0].contact_methods[0].country_code",
+				"teams[0].members[0].contact_methods[0].address",
+			},
+		},
+		{
+			desc: "duplicate member in a team",
+			in: &companyConfig{
+				DefaultTimezone: "Asia/Jakarta",
+				Teams: []*Team{
+					{
+						Name: "A",
+						Members: []*Member{
+							{Name: "John", Email: "john@beatles.com", Role: roleLead},
+							{Name: "John", Email: "john@beatles.com", Role: roleMember},
+						},
+					},
+				},
+			},
+			expectedErrors: []string{"teams[0].members[1].email"},
+		},
+		{
+			desc:           "no teams",
+			in:             &companyConfig{DefaultTimezone: "Asia/Jakarta"},
+			expectedErrors: []string{"teams"},
+		},
+	}
+
+	for _, s := range scenarios {
+		scenario := s
+		t.Run(scenario.desc, func(t *testing.T) {
+			// inputs
+			logger, _ := zap.NewDevelopment()
+
+			manager := New(&testConfig{}, logger)
+			manager.companyConfig = scenario.in
+
+			// call object under test
+			resultErr := manager.validate()
+
+			// validation
+			var errorPaths, warningPaths []string
+			for _, problem := range manager.Problems() {
+				if problem.Severity == SeverityWarning {
+					warningPaths = append(warningPaths, problem.Path)
+					continue
+				}
+
+				errorPaths = append(errorPaths, problem.Path)
+			}
+
+			assert.Equal(t, scenario.expectedErrors, errorPaths)
+			assert.Equal(t, scenario.expectedWarnings, warningPaths)
+
+			if len(scenario.expectedErrors) == 0 {
+				require.NoError(t, resultErr)
+				return
+			}
+
+			validationErr := &ValidationError{}
+			require.True(t, errors.As(resultErr, &validationErr))
+			assert.Len(t, validationErr.Problems, len(scenario.expectedErrors))
+		})
+	}
+}
+
+// problemPaths returns the paths of the problems (in order)
+func problemPaths(problems []*Problem) []string {
+	var out []string
+
+	for _, problem := range problems {
+		out = append(out, problem.Path)
+	}
+
+	return out
+}
+
+func TestManager_Parse_problemsInOneBlock(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	cfg := &testConfig{
+		filename: "./test_data/invalid_blocks.yaml",
+	}
+
+	// call object under test
+	manager := New(cfg, logger)
+	resultErr := manager.Parse(ctx)
+
+	// validation
+	require.Error(t, resultErr)
+
+	assert.Equal(t, []string{
+		"teams[0].schedule.rotation_length",
+		"teams[0].schedule.handoff_time",
+		"teams[0].schedule.layers[0].members[0]",
+		"teams[0].schedule.layers[0].restrictions[0].start_day",
+		"teams[0].escalation.num_loops",
+		"teams[0].escalation.tiers[1].delay_minutes",
+		"teams[0].escalation.tiers[1].roles[1]",
+		"teams[0].members[0].contact_methods[0].type",
+		"teams[0].members[0].contact_methods[1].country_code",
+		"teams[0].members[0].contact_methods[1].address",
+		"teams[0].services[0].integrations[0].type",
+		"teams[0].services[0].integrations[1].email",
+		"teams[0].services[0].links[0].url",
+		"teams[0].services[0].links[1].name",
+		"teams[0].services[0].urgency",
+		"teams[0].services[0].alert_grouping_timeout",
+		"teams[0].escalation.tiers[1].teams[0].name",
+		"notification_profiles.on-call[0].urgency",
+		"notification_profiles.on-call[0].contact_method",
+		"notification_profiles.on-call[0].delay_minutes",
+	}, problemPaths(manager.Problems()))
+
+	for _, problem := range manager.Problems()[:16] {
+		assert.Equal(t, "./test_data/invalid_blocks.yaml:8", problem.Source, problem.Path)
+	}
+}
+
+func TestManager_Parse_unknownFields(t *testing.T) {
+	// inputs
+	ctx, cancel := context.WithTimeout(context.Background(), 1*time.Second)
+	defer cancel()
+
+	logger, _ := zap.NewDevelopment()
+
+	cfg := &testConfig{
+		filename: "./test_data/unknown_fields.yaml",
+	}
+
+	// call object under test
+	manager := New(cfg, logger)
+	resultErr := manager.Parse(ctx)
+
+	// validation
+	require.Error(t, resultErr)
+
+	var result []string
+	for _, problem := range manager.Problems() {
+		result = append(result, problem.Source+" "+problem.Path)
+	}
+
+	assert.Equal(t, []string{
+		"./test_data/unknown_fields.yaml:5 notification_profiles.on-call[0].contact_methd",
+		"./test_data/unknown_fields.yaml:12 teams[0].members[0].contact_method",
+		"./test_data/unknown_fields.yaml:14 teams[0].escalaton",
+		" notification_profiles.on-call[0].contact_method",
+	}, result)
+}